@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// runCreateAdmin は運営者を作成し、トークンを一度だけ表示します。
+// トークンは /api/v2/admin 以下のAPIで Authorization: Bearer として使います。
+func runCreateAdmin(ctx context.Context, args []string) error {
+	fs := newFlagSet("create-admin")
+	email := fs.String("email", "", "email address of the admin")
+	name := fs.String("name", "", "display name of the admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" || *name == "" {
+		return fmt.Errorf("--email and --name are required")
+	}
+	if _, err := mail.ParseAddress(*email); err != nil {
+		return fmt.Errorf("invalid email %q: %w", *email, err)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	token, err := repository.New(db).CreateAdmin(ctx, repository.CreateAdminParams{
+		Email: *email,
+		Name:  *name,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created admin %s\n", *email)
+	fmt.Printf("token: %s\n", token)
+	fmt.Println("このトークンは再表示できません。安全な場所に保管してください。")
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/storage"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// runEvents は events list / approve / reject / geocode を実行します。
+func runEvents(ctx context.Context, args []string) error {
+	name, args, err := subcommand(args, "list", "approve", "reject", "geocode")
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return runEventsList(ctx, args)
+	case "geocode":
+		return runEventsGeocode(ctx, args)
+	default:
+		return runEventsReview(ctx, name, args)
+	}
+}
+
+// runEventsList はイベントの一覧を表形式で出力します。
+func runEventsList(ctx context.Context, args []string) error {
+	fs := newFlagSet("events list")
+	status := fs.String("status", string(repository.StatusPending), "approved / pending / all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	eventStatus, err := repository.ParseEventStatus(*status)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	events, err := repository.New(db).GetEvents(ctx, repository.GetEventsParams{Status: eventStatus})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tSTART\tTITLE\tORGANIZER\tEMAIL")
+	for _, event := range events {
+		fmt.Fprintf(w, "%d\t%s\t%s %s\t%s\t%s\t%s\n",
+			event.ID, eventStatusOf(event), event.StartDate, event.StartTime,
+			event.Title, event.Organizer, event.Email,
+		)
+	}
+	return w.Flush()
+}
+
+// runEventsReview は events approve / reject <id>... で未承認のイベントを承認・却下します。
+// 却下したイベントは削除し、画像もストレージから削除します。
+func runEventsReview(ctx context.Context, name string, args []string) error {
+	fs := newFlagSet("events " + name)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("event ID is required")
+	}
+	ids := make([]int, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid event ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc := newService(repository.New(db))
+	var st storage.Storage
+	if name == "reject" {
+		if st, err = newStorage(config.Storage()); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range ids {
+		if name == "approve" {
+			if err := svc.ApproveEventByAdmin(ctx, id); err != nil {
+				return fmt.Errorf("approve event %d: %w", id, err)
+			}
+			fmt.Printf("approved event %d\n", id)
+			continue
+		}
+
+		keys, err := svc.RejectEventByAdmin(ctx, id)
+		if err != nil {
+			return fmt.Errorf("reject event %d: %w", id, err)
+		}
+		for _, key := range keys {
+			if err := st.Delete(ctx, key); err != nil {
+				fmt.Fprintf(os.Stderr, "delete image %s: %v\n", key, err)
+			}
+		}
+		fmt.Printf("rejected event %d\n", id)
+	}
+	return nil
+}
+
+// runEventsGeocode は座標のないイベントの位置を都道府県・住所から補い、近くのイベントの検索に含まれるようにします。
+// 位置情報に対応する前に登録されたイベントに、一度だけ実行します。何度実行しても、座標のあるイベントは変えません。
+func runEventsGeocode(ctx context.Context, args []string) error {
+	fs := newFlagSet("events geocode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	geocoder, err := geo.NewPrefectureGeocoder()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	n, err := newService(repository.New(db)).BackfillVenueLocations(ctx, geocoder)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("geocoded %d events\n", n)
+	return nil
+}
+
+func eventStatusOf(event *repository.Event) repository.EventStatus {
+	if event.IsAuthenticated {
+		return repository.StatusApproved
+	}
+	return repository.StatusPending
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// exportEvent は export で出力するイベントです。認証コードのハッシュなど内部の値は含めません。
+type exportEvent struct {
+	ID               int      `json:"id"`
+	Status           string   `json:"status"`
+	Title            string   `json:"title"`
+	Organizer        string   `json:"organizer"`
+	StartDate        string   `json:"startDate"`
+	StartTime        string   `json:"startTime"`
+	EndDate          string   `json:"endDate"`
+	EndTime          string   `json:"endTime"`
+	Email            string   `json:"email"`
+	Prefecture       *string  `json:"prefecture"`
+	EventType        *string  `json:"eventType"`
+	DeliveryMode     string   `json:"deliveryMode"`
+	OfficialURL      *string  `json:"officialUrl"`
+	OnlineLectureURL *string  `json:"onlineLectureUrl"`
+	Venue            *string  `json:"venue"`
+	VenueAddress     *string  `json:"venueAddress"`
+	Target           *string  `json:"target"`
+	Capacity         *string  `json:"capacity"`
+	Tags             []string `json:"tags"`
+	RecurrenceRule   *string  `json:"recurrenceRule"`
+}
+
+var exportCSVHeader = []string{
+	"id", "status", "title", "organizer", "startDate", "startTime", "endDate", "endTime", "email",
+	"prefecture", "eventType", "deliveryMode", "officialUrl", "onlineLectureUrl", "venue", "venueAddress",
+	"target", "capacity", "tags", "recurrenceRule",
+}
+
+func newExportEvent(event *repository.Event) exportEvent {
+	return exportEvent{
+		ID:               event.ID,
+		Status:           string(eventStatusOf(event)),
+		Title:            event.Title,
+		Organizer:        event.Organizer,
+		StartDate:        event.StartDate,
+		StartTime:        event.StartTime,
+		EndDate:          event.EndDate,
+		EndTime:          event.EndTime,
+		Email:            event.Email,
+		Prefecture:       event.Prefecture,
+		EventType:        event.EventType,
+		DeliveryMode:     event.DeliveryMode,
+		OfficialURL:      event.OfficialURL,
+		OnlineLectureURL: event.OnlineLectureURL,
+		Venue:            event.Venue,
+		VenueAddress:     event.VenueAddress,
+		Target:           event.Target,
+		Capacity:         event.Capacity,
+		Tags:             event.Tags,
+		RecurrenceRule:   event.RecurrenceRule,
+	}
+}
+
+func (e exportEvent) csvRecord() []string {
+	return []string{
+		strconv.Itoa(e.ID), e.Status, e.Title, e.Organizer, e.StartDate, e.StartTime, e.EndDate, e.EndTime, e.Email,
+		deref(e.Prefecture), deref(e.EventType), e.DeliveryMode, deref(e.OfficialURL), deref(e.OnlineLectureURL),
+		deref(e.Venue), deref(e.VenueAddress), deref(e.Target), deref(e.Capacity),
+		strings.Join(e.Tags, ","), deref(e.RecurrenceRule),
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// runExport はイベントをCSVまたはJSONで出力します。
+func runExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", "csv", "csv / json")
+	status := fs.String("status", string(repository.StatusApproved), "approved / pending / all")
+	out := fs.String("o", "", "output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q (csv / json)", *format)
+	}
+	eventStatus, err := repository.ParseEventStatus(*status)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	events, err := repository.New(db).GetEvents(ctx, repository.GetEventsParams{Status: eventStatus})
+	if err != nil {
+		return err
+	}
+	records := make([]exportEvent, 0, len(events))
+	for _, event := range events {
+		records = append(records, newExportEvent(event))
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(r.csvRecord()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
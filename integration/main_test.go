@@ -4,12 +4,22 @@ import (
 	"github.com/ras0q/go-backend-template/internal/handler"
 	"github.com/ras0q/go-backend-template/internal/migration"
 	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/mail"
+	"github.com/ras0q/go-backend-template/internal/pkg/ogp"
+	"github.com/ras0q/go-backend-template/internal/pkg/slack"
+	"github.com/ras0q/go-backend-template/internal/pkg/storage"
 	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
 	"log"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -19,6 +29,8 @@ import (
 
 var (
 	db        *sqlx.DB
+	dbConfig  *mysql.Config
+	sqliteDir string
 	e         *echo.Echo
 	r         *repository.Repository
 	h         *handler.Handler
@@ -26,6 +38,23 @@ var (
 )
 
 func TestMain(m *testing.M) {
+	// DB_DRIVER=sqlite ならDockerを使わず、一時ファイルのSQLiteで実行する
+	if config.Database().Driver == database.SQLite {
+		dir, err := os.MkdirTemp("", "integration")
+		if err != nil {
+			log.Fatal("create sqlite dir: ", err)
+		}
+		defer os.RemoveAll(dir)
+		sqliteDir = dir
+
+		db, err = database.OpenSQLite(filepath.Join(dir, "test.db"))
+		if err != nil {
+			log.Fatal("open sqlite: ", err)
+		}
+		setup(m)
+		return
+	}
+
 	pool, err := dockertest.NewPool("")
 	if err != nil {
 		log.Fatal("connect to docker: ", err)
@@ -36,6 +65,7 @@ func TestMain(m *testing.M) {
 	}
 
 	mysqlConfig := config.MySQL()
+	dbConfig = mysqlConfig
 
 	resource, err := pool.Run("mysql", "latest", []string{
 		"MYSQL_ROOT_PASSWORD=" + mysqlConfig.Passwd,
@@ -59,23 +89,53 @@ func TestMain(m *testing.M) {
 		log.Fatal("connect to database container: ", err)
 	}
 
+	setup(m)
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatal("purge docker: ", err)
+	}
+}
+
+// setup は接続済みの db にマイグレーションを適用し、テスト対象のサーバーを組み立ててテストを実行します。
+func setup(m *testing.M) {
 	// migrate tables
-	if err := migration.MigrateTables(db.DB); err != nil {
+	if err := migration.MigrateTables(db); err != nil {
 		log.Fatal("migrate tables: ", err)
 	}
 
 	// setup dependencies
 	r = repository.New(db)
-	h = handler.New(r)
+	geocoder, err := geo.NewPrefectureGeocoder()
+	if err != nil {
+		log.Fatal("setup geocoder: ", err)
+	}
+	storageDir, err := os.MkdirTemp("", "uploads")
+	if err != nil {
+		log.Fatal("create storage dir: ", err)
+	}
+	defer os.RemoveAll(storageDir)
+	st, err := storage.NewLocal(storageDir, "/api/v2/images")
+	if err != nil {
+		log.Fatal("setup storage: ", err)
+	}
+	ogRenderer, err := ogp.NewRenderer(nil)
+	if err != nil {
+		log.Fatal("setup og renderer: ", err)
+	}
+	svc := service.New(r,
+		slack.NewWebhook(config.SlackWebhookURL()),
+		mail.NewSMTP(config.SMTP()),
+	)
+	h = handler.New(r, svc, geocoder, st, ogRenderer, nil)
 	e = echo.New()
-	h.SetupRoutes(e.Group("/api/v1"))
+	validator := handler.OpenAPIValidator(handler.OpenAPIValidatorConfig{
+		ValidateResponses: true,
+	})
+	h.SetupRoutes(e.Group("/api/v1", handler.DeprecatedAPI(handler.V1Deprecation(config.APIV1Sunset())), validator))
+	h.SetupV2Routes(e.Group("/api/v2", validator))
 
 	log.Println("start integration test")
 	m.Run()
-
-	if err := pool.Purge(resource); err != nil {
-		log.Fatal("purge docker: ", err)
-	}
 }
 
 func doRequest(t *testing.T, method, path string, bodystr string) *httptest.ResponseRecorder {
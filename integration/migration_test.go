@@ -0,0 +1,336 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ras0q/go-backend-template/internal/migration"
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+	"github.com/ras0q/go-backend-template/internal/pkg/eventtype"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/prefecture"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+// newDisposableDB はテスト用のコンテナに新しいデータベースを作成して接続します。テストの終了時に削除します。
+// SQLiteの場合は一時ディレクトリに新しいファイルを作ります。
+func newDisposableDB(t *testing.T, name string) *sqlx.DB {
+	t.Helper()
+
+	if db.DriverName() == database.SQLite {
+		disposable, err := database.OpenSQLite(filepath.Join(sqliteDir, name+".db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { disposable.Close() })
+		return disposable
+	}
+
+	_, err := db.Exec(fmt.Sprintf("CREATE DATABASE `%s`", name))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", name))
+	})
+
+	c := dbConfig.Clone()
+	c.DBName = name
+	disposable, err := sqlx.Open(database.MySQL, c.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { disposable.Close() })
+
+	return disposable
+}
+
+func dbVersion(t *testing.T, db *sqlx.DB) int64 {
+	t.Helper()
+
+	current, _, err := migration.Version(db)
+	require.NoError(t, err)
+	return current
+}
+
+// TestMigration_UpDownUp はすべてのマイグレーションを1つずつ適用・巻き戻し・再適用できることを確かめます。
+func TestMigration_UpDownUp(t *testing.T) {
+	mdb := newDisposableDB(t, "migration_up_down_up")
+
+	versions, err := migration.Versions(mdb.DriverName())
+	require.NoError(t, err)
+
+	var prev int64
+	for _, v := range versions {
+		require.NoError(t, migration.UpTo(mdb, v), "up %d", v)
+		require.Equal(t, v, dbVersion(t, mdb))
+
+		require.NoError(t, migration.Down(mdb), "down %d", v)
+		require.Equal(t, prev, dbVersion(t, mdb))
+
+		require.NoError(t, migration.UpTo(mdb, v), "up %d again", v)
+		require.Equal(t, v, dbVersion(t, mdb))
+
+		prev = v
+	}
+	require.NoError(t, migration.Check(mdb))
+
+	// すべて戻すと、gooseの管理テーブル以外は残らない
+	require.NoError(t, migration.DownTo(mdb, 0))
+	query := "SHOW TABLES"
+	if mdb.DriverName() == database.SQLite {
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	}
+	var tables []string
+	require.NoError(t, mdb.Select(&tables, query))
+	require.Equal(t, []string{"goose_db_version"}, tables)
+}
+
+func TestMigration_Check(t *testing.T) {
+	mdb := newDisposableDB(t, "migration_check")
+
+	require.ErrorIs(t, migration.Check(mdb), migration.ErrSchemaBehind)
+
+	require.NoError(t, migration.Up(mdb))
+	require.NoError(t, migration.Check(mdb))
+
+	// 新しいアプリが適用したマイグレーションがあるDBで、古いアプリは起動しない
+	_, latest, err := migration.Version(mdb)
+	require.NoError(t, err)
+	_, err = mdb.Exec(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, TRUE)`, latest+1)
+	require.NoError(t, err)
+	require.ErrorIs(t, migration.Check(mdb), migration.ErrSchemaAhead)
+}
+
+// TestMigration_ConcurrentUp は複数のインスタンスが同時にマイグレーションしても失敗しないことを確かめます。
+// 別々の接続から実行し、後から実行した方は先に適用されたマイグレーションを飛ばします。
+func TestMigration_ConcurrentUp(t *testing.T) {
+	if db.DriverName() == database.SQLite {
+		// SQLiteは1つのプロセスから使う前提なので、複数のインスタンスからの同時実行は考えない
+		t.Skip("concurrent migration is MySQL only")
+	}
+
+	name := "migration_concurrent"
+	mdb := newDisposableDB(t, name)
+
+	c := dbConfig.Clone()
+	c.DBName = name
+	other, err := sqlx.Open(database.MySQL, c.FormatDSN())
+	require.NoError(t, err)
+	defer other.Close()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, conn := range []*sqlx.DB{mdb, other} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = migration.Up(conn)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NoError(t, migration.Check(mdb))
+}
+
+// legacyEvent は正規化する前の形式でイベントを入れるための列の値です。
+type legacyEvent struct {
+	Title            string
+	Prefecture       *string
+	EventType        *string
+	Venue            *string
+	IsOnline         bool
+	IsOffline        bool
+	OnlineLectureURL *string
+}
+
+func insertLegacyEvent(t *testing.T, db *sqlx.DB, e legacyEvent) {
+	t.Helper()
+
+	_, err := db.Exec(`
+		INSERT INTO events (title, organizer, start_date, start_time, end_date, end_time, email,
+		                    prefecture, event_type, venue, is_online, is_offline, online_lecture_url)
+		VALUES (?, 'Org', '2025-01-01', '09:00:00', '2025-01-01', '10:00:00', 'org@example.com', ?, ?, ?, ?, ?, ?)
+	`, e.Title, e.Prefecture, e.EventType, e.Venue, e.IsOnline, e.IsOffline, e.OnlineLectureURL)
+	require.NoError(t, err)
+}
+
+func ptr(s string) *string { return &s }
+
+// TestMigration_NormalizePrefectureEventType は、8 がアプリの Lookup と同じ表記を受け付け、
+// 解釈できない値を legacy_* に残し、Down で元の表記に戻すことを確かめます。
+func TestMigration_NormalizePrefectureEventType(t *testing.T) {
+	mdb := newDisposableDB(t, "migration_normalize")
+	require.NoError(t, migration.UpTo(mdb, 7))
+
+	type expectation struct {
+		prefecture *string
+		eventType  *string
+	}
+	want := make(map[string]expectation)
+	add := func(e legacyEvent, prefectureCode, eventTypeID *string) {
+		e.Title = fmt.Sprintf("event %d", len(want))
+		insertLegacyEvent(t, mdb, e)
+		want[e.Title] = expectation{prefecture: prefectureCode, eventType: eventTypeID}
+	}
+
+	for _, p := range prefecture.All() {
+		short := p.Name
+		if n := len([]rune(short)); short != "北海道" {
+			short = string([]rune(short)[:n-1])
+		}
+		aliases := []string{p.Code, p.Name, p.NameEn, strings.ToLower(p.NameEn), " " + p.Name + " ",
+			short, strings.TrimLeft(p.Code, "0")}
+		for _, alias := range aliases {
+			found, ok := prefecture.Lookup(alias)
+			require.True(t, ok, alias)
+			require.Equal(t, p.Code, found.Code, alias)
+			add(legacyEvent{Prefecture: ptr(alias)}, ptr(p.Code), nil)
+		}
+		add(legacyEvent{Prefecture: ptr(p.Name + "（オンラインでも参加可）")}, ptr(p.Code), nil)
+	}
+	for _, et := range eventtype.All() {
+		for _, alias := range []string{et.ID, et.Name, et.NameEn, strings.ToUpper(et.NameEn)} {
+			found, ok := eventtype.Lookup(alias)
+			require.True(t, ok, alias)
+			require.Equal(t, et.ID, found.ID, alias)
+			add(legacyEvent{EventType: ptr(alias)}, nil, ptr(et.ID))
+		}
+	}
+	add(legacyEvent{Venue: ptr("東京都千代田区 数学会館")}, ptr("13"), nil)
+	add(legacyEvent{Prefecture: ptr("月面"), EventType: ptr("オンライン")}, nil, nil)
+	add(legacyEvent{Prefecture: ptr("月面"), Venue: ptr("大阪府大阪市")}, ptr("27"), nil)
+
+	type row struct {
+		Title            string         `db:"title"`
+		Prefecture       sql.NullString `db:"prefecture"`
+		EventType        sql.NullString `db:"event_type"`
+		LegacyPrefecture sql.NullString `db:"legacy_prefecture"`
+		LegacyEventType  sql.NullString `db:"legacy_event_type"`
+	}
+	var before []row
+	require.NoError(t, mdb.Select(&before, `SELECT title, prefecture, event_type, NULL AS legacy_prefecture, NULL AS legacy_event_type FROM events`))
+
+	require.NoError(t, migration.UpTo(mdb, 8))
+	var after []row
+	require.NoError(t, mdb.Select(&after, `SELECT title, prefecture, event_type, legacy_prefecture, legacy_event_type FROM events`))
+	require.Len(t, after, len(want))
+	for _, r := range after {
+		w := want[r.Title]
+		if w.prefecture == nil {
+			require.False(t, r.Prefecture.Valid, "%s: %v", r.Title, r)
+		} else {
+			require.Equal(t, *w.prefecture, r.Prefecture.String, "%s: %v", r.Title, r)
+		}
+		if w.eventType == nil {
+			require.False(t, r.EventType.Valid, "%s: %v", r.Title, r)
+		} else {
+			require.Equal(t, *w.eventType, r.EventType.String, "%s: %v", r.Title, r)
+		}
+	}
+
+	// 解釈できなかった値は元の表記が残る
+	var legacy row
+	require.NoError(t, mdb.Get(&legacy, `SELECT title, prefecture, event_type, legacy_prefecture, legacy_event_type FROM events WHERE legacy_event_type = 'オンライン'`))
+	require.Equal(t, "月面", legacy.LegacyPrefecture.String)
+
+	require.NoError(t, migration.DownTo(mdb, 7))
+	var restored []row
+	require.NoError(t, mdb.Select(&restored, `SELECT title, prefecture, event_type, NULL AS legacy_prefecture, NULL AS legacy_event_type FROM events`))
+	require.ElementsMatch(t, before, restored)
+}
+
+// TestMigration_DeliveryMode は、9 が is_online / is_offline と、8 で event_type から外した表記の食い違いを解消することを確かめます。
+func TestMigration_DeliveryMode(t *testing.T) {
+	mdb := newDisposableDB(t, "migration_delivery_mode")
+	require.NoError(t, migration.UpTo(mdb, 7))
+
+	cases := map[string]struct {
+		event legacyEvent
+		want  deliverymode.Mode
+	}{
+		"online flag, offline type":   {legacyEvent{IsOnline: true, EventType: ptr("オフライン")}, deliverymode.Hybrid},
+		"offline flag, online type":   {legacyEvent{IsOffline: true, EventType: ptr("オンライン")}, deliverymode.Hybrid},
+		"offline flag, hybrid type":   {legacyEvent{IsOffline: true, EventType: ptr("ハイブリッド")}, deliverymode.Hybrid},
+		"both flags, online type":     {legacyEvent{IsOnline: true, IsOffline: true, EventType: ptr("オンライン")}, deliverymode.Hybrid},
+		"offline flag, lecture type":  {legacyEvent{IsOffline: true, EventType: ptr("講演会")}, deliverymode.Offline},
+		"no flags, online type":       {legacyEvent{EventType: ptr("オンライン")}, deliverymode.Online},
+		"no flags, url":               {legacyEvent{EventType: ptr("seminar"), OnlineLectureURL: ptr("https://zoom.example.com/j/1")}, deliverymode.Online},
+		"no flags, empty url":         {legacyEvent{OnlineLectureURL: ptr("")}, deliverymode.Offline},
+		"no flags, nothing":           {legacyEvent{}, deliverymode.Offline},
+		"no flags, url, offline type": {legacyEvent{EventType: ptr("オフライン"), OnlineLectureURL: ptr("https://zoom.example.com/j/1")}, deliverymode.Offline},
+	}
+	// アプリの開催形態の表記(ID・日本語名・英語名)はどれも読む
+	for _, opt := range deliverymode.All() {
+		for _, alias := range []string{string(opt.ID), opt.Name, opt.NameEn} {
+			cases["type "+alias] = struct {
+				event legacyEvent
+				want  deliverymode.Mode
+			}{legacyEvent{EventType: ptr(alias)}, opt.ID}
+		}
+	}
+	for name, c := range cases {
+		c.event.Title = name
+		insertLegacyEvent(t, mdb, c.event)
+	}
+
+	require.NoError(t, migration.UpTo(mdb, 9))
+
+	type row struct {
+		Title        string `db:"title"`
+		DeliveryMode string `db:"delivery_mode"`
+		IsOnline     bool   `db:"is_online"`
+		IsOffline    bool   `db:"is_offline"`
+	}
+	var rows []row
+	require.NoError(t, mdb.Select(&rows, `SELECT title, delivery_mode, is_online, is_offline FROM events`))
+	require.Len(t, rows, len(cases))
+	for _, r := range rows {
+		want := cases[r.Title].want
+		require.Equal(t, string(want), r.DeliveryMode, r.Title)
+		online, offline := want.Flags()
+		require.Equal(t, online, r.IsOnline, r.Title)
+		require.Equal(t, offline, r.IsOffline, r.Title)
+	}
+}
+
+// TestMigration_BackfillVenueLocations は、位置情報に対応する前に承認されたイベントが
+// マイグレーションのあと events geocode で近くのイベントの検索に含まれるようになることを確かめます。
+func TestMigration_BackfillVenueLocations(t *testing.T) {
+	mdb := newDisposableDB(t, "migration_backfill_locations")
+	require.NoError(t, migration.UpTo(mdb, 6))
+	insertLegacyEvent(t, mdb, legacyEvent{Title: "Legacy Tokyo", Prefecture: ptr("東京都"), IsOffline: true})
+	insertLegacyEvent(t, mdb, legacyEvent{Title: "Legacy Osaka", Venue: ptr("大阪府大阪市 数学会館"), IsOffline: true})
+	_, err := mdb.Exec(`UPDATE events SET is_authenticated = TRUE`)
+	require.NoError(t, err)
+	require.NoError(t, migration.Up(mdb))
+
+	repo := repository.New(mdb)
+	ctx := context.Background()
+	tokyo := geo.BoundsAround(geo.Point{Lat: 35.68, Lng: 139.76}, 50)
+	listed, err := repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+	require.NoError(t, err)
+	require.Empty(t, listed)
+
+	geocoder, err := geo.NewPrefectureGeocoder()
+	require.NoError(t, err)
+	n, err := service.New(repo, nil, nil).BackfillVenueLocations(ctx, geocoder)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	listed, err = repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, "Legacy Tokyo", listed[0].Title)
+
+	osaka := geo.BoundsAround(geo.Point{Lat: 34.69, Lng: 135.50}, 50)
+	listed, err = repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &osaka})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, "Legacy Osaka", listed[0].Title)
+}
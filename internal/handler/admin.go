@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+// requireAdmin は Authorization: Bearer で渡された運営者のトークンを確かめるミドルウェアです。
+// トークンは create-admin コマンドで発行します。
+func (h *Handler) requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scheme, credentials, _ := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+		token, err := uuid.Parse(strings.TrimSpace(credentials))
+		if !strings.EqualFold(scheme, "Bearer") || err != nil {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+			return echo.NewHTTPError(http.StatusUnauthorized, "admin token required")
+		}
+
+		admin, err := h.repo.GetAdminByToken(c.Request().Context(), token)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to authenticate admin").SetInternal(err)
+		}
+		if admin == nil {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid admin token")
+		}
+		return next(c)
+	}
+}
+
+// adminActionError は運営者の操作で返ったエラーをHTTPのエラーに変換します。
+func adminActionError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	case errors.Is(err, service.ErrAlreadyApproved):
+		return echo.NewHTTPError(http.StatusConflict, AlreadyApproved)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, message).SetInternal(err)
+	}
+}
+
+// POST /api/v2/admin/events/:id/approval
+// 運営者としてイベントを承認し、承認後のイベントを返します。認証コードは不要です。
+func (h *Handler) CreateAdminEventApproval(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	if err := h.svc.ApproveEventByAdmin(c.Request().Context(), id); err != nil {
+		return adminActionError(err, "failed to approve event")
+	}
+	h.invalidateEvents(c)
+
+	return h.organizerEventResponse(c, http.StatusCreated, id, uuid.Nil)
+}
+
+// DELETE /api/v2/admin/events/:id
+// 運営者として未承認のイベントを却下し、削除します。承認済みの場合は409を返します。
+func (h *Handler) DeleteAdminEvent(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	keys, err := h.svc.RejectEventByAdmin(c.Request().Context(), id)
+	if err != nil {
+		return adminActionError(err, "failed to reject event")
+	}
+	h.deleteImages(c, keys...)
+	h.ogCache.Delete(id)
+	h.invalidateEvents(c)
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+func doAdminRequest(e *echo.Echo, method, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestAdminEvents は create-admin で発行したトークンで、認証コードなしに承認・却下できることを確認します。
+func TestAdminEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemory()
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error { return nil })
+	h := handler.New(repo, service.New(repo, notifier, nil), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+	h.SetupV2Routes(e.Group("/api/v2"))
+
+	token, err := repo.CreateAdmin(ctx, repository.CreateAdminParams{Email: "admin@example.com", Name: "運営"})
+	require.NoError(t, err)
+	bearer := "Bearer " + token
+
+	submit := func(title string) string {
+		t.Helper()
+		rec := doRequest(e, http.MethodPost, "/api/v2/events", `{
+        "title":"`+title+`",
+        "organizer":"Test Org",
+        "startDate":"2025-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2025-01-01",
+        "endTime":"10:00:00",
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
+    }`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var created handler.CreateEventResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		return created.ID
+	}
+
+	t.Run("トークンがなければ401を返す", func(t *testing.T) {
+		id := submit("Unauthorized")
+		for _, authorization := range []string{"", "Bearer not-a-uuid", "Basic " + token, "Bearer " + "00000000-0000-4000-8000-000000000000"} {
+			rec := doAdminRequest(e, http.MethodPost, "/api/v2/admin/events/"+id+"/approval", authorization)
+			require.Equal(t, http.StatusUnauthorized, rec.Code, authorization)
+			require.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "Bearer")
+		}
+
+		rec := doRequest(e, http.MethodGet, "/api/v2/events/"+id, "")
+		require.Equal(t, http.StatusNotFound, rec.Code, "the event must stay pending")
+	})
+
+	t.Run("承認", func(t *testing.T) {
+		id := submit("Approved By Admin")
+
+		rec := doAdminRequest(e, http.MethodPost, "/api/v2/admin/events/"+id+"/approval", bearer)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		require.Contains(t, rec.Body.String(), "Approved By Admin")
+
+		rec = doRequest(e, http.MethodGet, "/api/v2/events/"+id, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doAdminRequest(e, http.MethodPost, "/api/v2/admin/events/"+id+"/approval", bearer)
+		require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+		// 承認済みのイベントは却下できない
+		rec = doAdminRequest(e, http.MethodDelete, "/api/v2/admin/events/"+id, bearer)
+		require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	})
+
+	t.Run("却下", func(t *testing.T) {
+		id := submit("Rejected By Admin")
+
+		rec := doAdminRequest(e, http.MethodDelete, "/api/v2/admin/events/"+id, bearer)
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		rec = doAdminRequest(e, http.MethodPost, "/api/v2/admin/events/"+id+"/approval", bearer)
+		require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// authCodeKey は認証コードを渡すときの名前です。クエリ・JSON・フォームのいずれもこの名前を使います。
+	authCodeKey = "authcode"
+	// legacyAuthCodeKey は GET /event/update/:id で使われていた旧名です。
+	legacyAuthCodeKey = "auth_code"
+)
+
+// parseAuthCode は認証コードを解釈します。空の場合は uuid.Nil を返します。
+func parseAuthCode(s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(s)
+}
+
+// queryAuthCode はクエリパラメータの authcode を返します。指定されていない場合は uuid.Nil を返します。
+func queryAuthCode(c echo.Context) (uuid.UUID, error) {
+	return parseAuthCode(c.QueryParam(authCodeKey))
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/ics"
+)
+
+const (
+	responseCacheSize = 512
+	// eventsVersionKey はイベントの公開内容の世代です。イベントが変わるたびに増やし、
+	// 古い世代のキーで保存したレスポンスを使わないようにします。
+	eventsVersionKey = "events:version"
+)
+
+// cachedResponse はキャッシュに保存するレスポンスです。
+type cachedResponse struct {
+	ContentType  string    `json:"contentType"`
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+	// ChangesAt は時刻が進むと内容が変わるレスポンスの、次に変わる時刻です。キャッシュはこれより前に期限切れにします。
+	ChangesAt time.Time `json:"-"`
+}
+
+// newCachedResponse は本文のハッシュをETagとし、modified をLast-Modifiedとするレスポンスを作成します。
+func newCachedResponse(contentType string, body []byte, modified time.Time) *cachedResponse {
+	sum := sha256.Sum256(body)
+	return &cachedResponse{
+		ContentType:  contentType,
+		Body:         body,
+		ETag:         strconv.Quote(hex.EncodeToString(sum[:16])),
+		LastModified: modified.UTC().Truncate(time.Second),
+	}
+}
+
+func jsonResponse(v any, modified time.Time) (*cachedResponse, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	return newCachedResponse(echo.MIMEApplicationJSON, b, modified), nil
+}
+
+func calendarResponse(cal ics.Calendar) (*cachedResponse, error) {
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	return newCachedResponse(mimeTextCalendar, buf.Bytes(), cal.Stamp), nil
+}
+
+// serveEvents はイベントの一覧・詳細・ICSのレスポンスを ETag と Last-Modified 付きで返します。
+// public なリクエスト(認証コードなどを含まない)はキャッシュから返し、なければ render の結果を保存します。
+// キャッシュの読み書きに失敗した場合もログに残すだけで、render の結果を返します。
+func (h *Handler) serveEvents(c echo.Context, public bool, render func(now time.Time) (*cachedResponse, error)) error {
+	if !public {
+		res, err := render(time.Now())
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set("Cache-Control", "private, no-cache")
+		return writeConditional(c, res)
+	}
+
+	ctx := c.Request().Context()
+	// 世代は render の前に読む。作っている間に更新された場合は古い世代のキーに保存され、使われない
+	key, err := h.eventsCacheKey(c)
+	if err != nil {
+		c.Logger().Warnf("get events cache version: %v", err)
+	} else if b, ok, err := h.respCache.Get(ctx, key); err != nil {
+		c.Logger().Warnf("get cached response %s: %v", key, err)
+	} else if ok {
+		var res cachedResponse
+		if err := json.Unmarshal(b, &res); err == nil {
+			return writeConditional(c, &res)
+		}
+	}
+
+	res, err := render(time.Now())
+	if err != nil {
+		return err
+	}
+	if ttl, ok := cacheTTL(res, time.Now()); key != "" && ok {
+		b, err := json.Marshal(res)
+		if err == nil {
+			err = h.respCache.Set(ctx, key, b, ttl)
+		}
+		if err != nil {
+			c.Logger().Warnf("cache response %s: %v", key, err)
+		}
+	}
+	return writeConditional(c, res)
+}
+
+// cacheTTL は res をキャッシュしておく期間を返します。config.ResponseCacheTTL を、内容が次に変わるまでに縮めます。
+// 秒単位で期限を指定するキャッシュでも期限なしにならないよう秒未満を切り捨て、1秒に満たない場合は保存しません。
+func cacheTTL(res *cachedResponse, now time.Time) (time.Duration, bool) {
+	ttl := config.ResponseCacheTTL()
+	if res.ChangesAt.IsZero() {
+		return ttl, true
+	}
+
+	until := res.ChangesAt.Sub(now).Truncate(time.Second)
+	if until <= 0 {
+		return 0, false
+	}
+	if ttl <= 0 || until < ttl {
+		ttl = until
+	}
+	return ttl, true
+}
+
+// eventsCacheKey は現在の世代とリクエストのパス・クエリからキャッシュのキーを作ります。
+func (h *Handler) eventsCacheKey(c echo.Context) (string, error) {
+	version := "0"
+	b, ok, err := h.respCache.Get(c.Request().Context(), eventsVersionKey)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		version = string(b)
+	}
+	return "events:" + version + ":" + c.Request().URL.RequestURI(), nil
+}
+
+// invalidateEvents はイベントの登録・承認・編集の後に呼び、キャッシュしたレスポンスを使わないようにします。
+func (h *Handler) invalidateEvents(c echo.Context) {
+	if _, err := h.respCache.Incr(c.Request().Context(), eventsVersionKey); err != nil {
+		c.Logger().Errorf("invalidate events cache: %v", err)
+	}
+}
+
+// writeConditional は ETag と Last-Modified を付けてレスポンスを書き出します。
+// If-None-Match または If-Modified-Since の条件に合う場合は、本文なしの304を返します。
+func writeConditional(c echo.Context, res *cachedResponse) error {
+	header := c.Response().Header()
+	header.Set("ETag", res.ETag)
+	header.Set("Last-Modified", res.LastModified.Format(http.TimeFormat))
+	if header.Get("Cache-Control") == "" {
+		// 内容はいつ変わるか分からないため、毎回304で確認させる
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	if notModified(c.Request(), res) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, res.ContentType, res.Body)
+}
+
+// notModified はリクエストの条件から、クライアントの持っているレスポンスが最新かを判定します。
+// If-None-Match がある場合は If-Modified-Since を無視します(RFC 9110 13.2.2)。
+func notModified(req *http.Request, res *cachedResponse) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == res.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	return err == nil && !res.LastModified.After(since)
+}
@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+func doConditionalRequest(e *echo.Echo, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// submitAndApprove はイベントを申請して承認し、IDと認証コードを返します。
+func submitAndApprove(t *testing.T, e *echo.Echo, messages *[]string, title string) (string, string) {
+	t.Helper()
+
+	body := fmt.Sprintf(`{
+        "title":%q,
+        "organizer":"Test Org",
+        "startDate":"2025-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2025-01-01",
+        "endTime":"10:00:00",
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
+    }`, title)
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var created handler.CreateEventResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	msg := (*messages)[len(*messages)-1]
+	link := fmt.Sprintf("/events/update/%s?authcode=", created.ID)
+	require.Contains(t, msg, link)
+	authCode := strings.Fields(msg[strings.Index(msg, link)+len(link):])[0]
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+created.ID+"/approve", fmt.Sprintf(`{"authcode":%q}`, authCode))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return created.ID, authCode
+}
+
+func TestConditionalRequest(t *testing.T) {
+	var messages []string
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, notifier, nil), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+	h.SetupV2Routes(e.Group("/api/v2"))
+	id, authCode := submitAndApprove(t, e, &messages, "First Event")
+
+	for _, path := range []string{
+		"/api/v1/event/all",
+		"/api/v1/event/all.ics",
+		"/api/v1/event/" + id,
+		"/api/v1/event/" + id + "/ics",
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := doConditionalRequest(e, path, nil)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			etag := rec.Header().Get("ETag")
+			lastModified := rec.Header().Get("Last-Modified")
+			require.NotEmpty(t, etag)
+			require.NotEmpty(t, lastModified)
+
+			// キャッシュから返すため、内容も ETag も変わらない
+			again := doConditionalRequest(e, path, nil)
+			require.Equal(t, rec.Body.String(), again.Body.String())
+			require.Equal(t, etag, again.Header().Get("ETag"))
+
+			rec = doConditionalRequest(e, path, map[string]string{"If-None-Match": etag})
+			require.Equal(t, http.StatusNotModified, rec.Code)
+			require.Empty(t, rec.Body.String())
+			require.Equal(t, etag, rec.Header().Get("ETag"))
+
+			rec = doConditionalRequest(e, path, map[string]string{"If-None-Match": `W/"other", ` + etag})
+			require.Equal(t, http.StatusNotModified, rec.Code)
+
+			rec = doConditionalRequest(e, path, map[string]string{"If-Modified-Since": lastModified})
+			require.Equal(t, http.StatusNotModified, rec.Code)
+
+			// If-None-Match が一致しなければ If-Modified-Since は見ない
+			rec = doConditionalRequest(e, path, map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified})
+			require.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+
+	t.Run("イベントが承認されると新しい内容を返す", func(t *testing.T) {
+		rec := doConditionalRequest(e, "/api/v1/event/all", nil)
+		etag := rec.Header().Get("ETag")
+		require.NotContains(t, rec.Body.String(), "Second Event")
+
+		submitAndApprove(t, e, &messages, "Second Event")
+
+		rec = doConditionalRequest(e, "/api/v1/event/all", map[string]string{
+			"If-None-Match":     etag,
+			"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotEqual(t, etag, rec.Header().Get("ETag"))
+		require.Contains(t, rec.Body.String(), "Second Event")
+	})
+
+	t.Run("イベントが編集されると新しい内容を返す", func(t *testing.T) {
+		path := "/api/v1/event/" + id
+		rec := doConditionalRequest(e, path, nil)
+		require.Contains(t, rec.Body.String(), "First Event")
+		etag := rec.Header().Get("ETag")
+
+		rec = doRequest(e, http.MethodPatch, "/api/v2/events/"+id+"?authcode="+authCode, `{"title":"Renamed Event"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doConditionalRequest(e, path, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Renamed Event")
+	})
+
+	t.Run("認証コード付きのレスポンスはキャッシュしない", func(t *testing.T) {
+		rec := doConditionalRequest(e, "/api/v1/event/"+id+"?authcode="+authCode, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+		require.NotEmpty(t, rec.Header().Get("ETag"))
+	})
+}
+
+// TestLectureURLCache は、キャッシュしたレスポンスがオンライン講義URLの公開開始を過ぎて使われないことを確かめます。
+func TestLectureURLCache(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, nil, nil), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+
+	jst := time.FixedZone("JST", 9*60*60)
+	tomorrow := time.Now().In(jst).AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 10, 0, 0, 0, jst)
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	id, _, err := repo.CreateEventTx(ctx, tx, repository.CreateEventParams{
+		Title:                 "Online Lecture",
+		StartDate:             start.Format("2006-01-02"),
+		StartTime:             "10:00:00",
+		EndDate:               start.Format("2006-01-02"),
+		EndTime:               "12:00:00",
+		DeliveryMode:          "online",
+		IsOnline:              true,
+		OnlineLectureURL:      ptr("https://zoom.example.com/j/1"),
+		ApprovalCodeExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, repo.SetEventApproved(ctx, id))
+
+	// URLの公開開始を、キャッシュの期限(1分)より前の少し先にする。公開は秒単位で切り替わる
+	visibleFrom := time.Now().Truncate(time.Second).Add(2 * time.Second)
+	t.Setenv("ONLINE_URL_REVEAL_WINDOW", start.Sub(visibleFrom).String())
+
+	paths := []string{fmt.Sprintf("/api/v1/event/%d", id), "/api/v1/event/all"}
+	for _, path := range paths {
+		rec := doConditionalRequest(e, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NotContains(t, rec.Body.String(), "zoom.example.com", path)
+	}
+
+	time.Sleep(time.Until(visibleFrom) + 100*time.Millisecond)
+	for _, path := range paths {
+		rec := doConditionalRequest(e, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Contains(t, rec.Body.String(), "zoom.example.com", path)
+	}
+}
@@ -1,10 +1,11 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/service"
 )
 
 // CreateContact
@@ -26,17 +27,12 @@ func (h *Handler) CreateContact(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body").SetInternal(err)
 	}
 
-	// 3) Slack通知
-	InitSlack()
-	slackMessage := fmt.Sprintf(
-		"お問い合わせがありました\n"+
-			"名前: %s\n"+
-			"メールアドレス: %s\n"+
-			"メッセージ: %s",
-		req.Name, req.Email, req.Message)
-
-	if err := postToSlack(slackMessage); err != nil {
-		// 失敗→ROLLBACKしてエラー応答
+	err := h.svc.SubmitContact(c.Request().Context(), service.Contact{
+		Name:    req.Name,
+		Email:   req.Email,
+		Message: req.Message,
+	})
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError,
 			"failed to send Slack notification").SetInternal(err)
 	}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+)
+
+// httpURL はhttpまたはhttpsの絶対URLであることを検証します。
+var httpURL = vd.By(func(value interface{}) error {
+	s, ok := value.(*string)
+	if !ok || s == nil || *s == "" {
+		return nil
+	}
+	u, err := url.Parse(*s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+})
+
+// resolveDeliveryMode はリクエストの deliveryMode と、旧形式の isOnline/isOffline から開催形態を決めます。
+// 両方が指定されて矛盾している場合や、どちらからも決められない場合はエラーを返します。
+func resolveDeliveryMode(mode *string, isOnline, isOffline bool) (deliverymode.Mode, error) {
+	fromFlags, hasFlags := deliverymode.FromFlags(isOnline, isOffline)
+	if mode == nil || *mode == "" {
+		if !hasFlags {
+			return "", vd.Errors{"deliveryMode": errors.New("cannot be blank")}
+		}
+		return fromFlags, nil
+	}
+
+	m, err := deliverymode.Parse(*mode)
+	if err != nil {
+		return "", vd.Errors{"deliveryMode": errors.New("must be one of online, offline, hybrid")}
+	}
+	if hasFlags && fromFlags != m {
+		return "", vd.Errors{"deliveryMode": errors.New("contradicts isOnline/isOffline")}
+	}
+	return m, nil
+}
+
+// mergeDeliveryPatch はPATCHで開催形態を deliveryMode と isOnline/isOffline の片方の形式だけで変えた場合に、
+// もう片方の形式の元の値を捨てて、送られた形式だけから開催形態を決めるようにします。
+func mergeDeliveryPatch(req *CreateEventRequest, patch map[string]json.RawMessage) {
+	_, hasMode := patch["deliveryMode"]
+	_, hasOnline := patch["isOnline"]
+	_, hasOffline := patch["isOffline"]
+	switch {
+	case hasMode && !hasOnline && !hasOffline:
+		req.IsOnline, req.IsOffline = false, false
+	case !hasMode && (hasOnline || hasOffline):
+		req.DeliveryMode = nil
+	}
+}
+
+// validateDeliveryDetails は開催形態に応じて必要な項目を検証します。
+// オンライン・ハイブリッドはオンライン講義URL、オフライン・ハイブリッドは会場が必須です。
+func validateDeliveryDetails(mode deliverymode.Mode, onlineLectureURL, venue *string) error {
+	errs := vd.Errors{}
+	if mode.RequiresOnlineURL() {
+		if err := vd.Validate(onlineLectureURL, vd.Required); err != nil {
+			errs["onlineLectureUrl"] = errors.New("is required for online and hybrid events")
+		}
+	}
+	if mode.RequiresVenue() {
+		if err := vd.Validate(venue, vd.Required); err != nil {
+			errs["venue"] = errors.New("is required for offline and hybrid events")
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIDeprecationConfig は DeprecatedAPI の設定です。
+type APIDeprecationConfig struct {
+	// Since は廃止予定になった日時です。
+	Since time.Time
+	// Sunset は提供を終了する予定の日時です。ゼロ値の場合は Sunset ヘッダを付けません。
+	Sunset time.Time
+	// Link は移行方法を説明するページのURLです。
+	Link string
+}
+
+// v1DeprecatedAt は /api/v2 の公開に合わせて /api/v1 を廃止予定にした日時です。
+var v1DeprecatedAt = time.Date(2026, 10, 18, 0, 0, 0, 0, jst)
+
+// V1Deprecation は /api/v1 に付ける廃止予定の情報です。
+func V1Deprecation(sunset time.Time) APIDeprecationConfig {
+	return APIDeprecationConfig{
+		Since:  v1DeprecatedAt,
+		Sunset: sunset,
+		Link:   "/api/v2/docs",
+	}
+}
+
+// DeprecatedAPI は全てのレスポンスに Deprecation (RFC 9745) と Sunset (RFC 8594) ヘッダを付けるミドルウェアです。
+// フロントエンドはこれを見て、移行が済んでいない呼び出しを見つけられます。
+func DeprecatedAPI(cfg APIDeprecationConfig) echo.MiddlewareFunc {
+	deprecation := fmt.Sprintf("@%d", cfg.Since.Unix())
+	sunset := cfg.Sunset.UTC().Format(http.TimeFormat)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set("Deprecation", deprecation)
+			if !cfg.Sunset.IsZero() {
+				header.Set("Sunset", sunset)
+			}
+			if cfg.Link != "" {
+				header.Add("Link", "<"+cfg.Link+">; rel=\"deprecation\"")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+)
+
+func TestDeprecatedAPI(t *testing.T) {
+	e := echo.New()
+	api := e.Group("/api/v1", handler.DeprecatedAPI(handler.APIDeprecationConfig{
+		Since:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC),
+		Link:   "/api/v2/docs",
+	}))
+	api.GET("/ok", func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
+	api.GET("/error", func(c echo.Context) error { return echo.NewHTTPError(http.StatusNotFound) })
+
+	for _, path := range []string{"/api/v1/ok", "/api/v1/error"} {
+		rec := serve(e, http.MethodGet, path, "")
+		require.Equal(t, "@1790812800", rec.Header().Get("Deprecation"), path)
+		require.Equal(t, "Wed, 31 Mar 2027 00:00:00 GMT", rec.Header().Get("Sunset"), path)
+		require.Equal(t, `</api/v2/docs>; rel="deprecation"`, rec.Header().Get("Link"), path)
+	}
+
+	// Sunset が未定なら付けない
+	e = echo.New()
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+		handler.DeprecatedAPI(handler.APIDeprecationConfig{Since: time.Unix(0, 0)}))
+	rec := serve(e, http.MethodGet, "/", "")
+	require.Equal(t, "@0", rec.Header().Get("Deprecation"))
+	require.Empty(t, rec.Header().Get("Sunset"))
+}
@@ -1,12 +1,11 @@
 package handler
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
+	"time"
 
 	vd "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -14,8 +13,11 @@ import (
 	"github.com/labstack/echo/v4"
 
 	// リポジトリとの連携
-	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/eventtype"
+	"github.com/ras0q/go-backend-template/internal/pkg/markdown"
+	"github.com/ras0q/go-backend-template/internal/pkg/prefecture"
 	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
 )
 
 // -------------------
@@ -49,28 +51,39 @@ func convertSpeakersToResponse(speakers []repository.Speaker) []Speaker {
 	return result
 }
 
-func convertSchedules(schedules []Schedule) []repository.Schedule {
-	result := make([]repository.Schedule, len(schedules))
-	for i, s := range schedules {
+func convertSchedules(schedules []Schedule, eventStartDate string) []repository.Schedule {
+	normalized := normalizeSchedules(schedules, eventStartDate)
+	result := make([]repository.Schedule, len(normalized))
+	for i, s := range normalized {
 		result[i] = repository.Schedule{
-			Time:    s.Time,
-			Title:   s.Title,
-			Speaker: s.Speaker,
+			Date:      s.Date,
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+			Title:     s.Title,
+			Room:      s.Room,
+			Track:     s.Track,
+			Speakers:  s.Speakers,
 		}
 	}
 	return result
 }
 
-func convertSchedulesToResponse(schedules []repository.Schedule) []Schedule {
+func convertSchedulesToResponse(schedules []repository.Schedule, eventStartDate string) []Schedule {
 	result := make([]Schedule, len(schedules))
 	for i, s := range schedules {
 		result[i] = Schedule{
-			Time:    s.Time,
-			Title:   s.Title,
-			Speaker: s.Speaker,
+			Date:      s.Date,
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+			Title:     s.Title,
+			Room:      s.Room,
+			Track:     s.Track,
+			Speakers:  s.Speakers,
+			Time:      s.Time,
+			Speaker:   s.Speaker,
 		}
 	}
-	return result
+	return normalizeSchedules(result, eventStartDate)
 }
 
 // ---------------------
@@ -87,6 +100,7 @@ type (
 		Email            string     `json:"email"`
 		Prefecture       *string    `json:"prefecture"`
 		EventType        *string    `json:"eventType"`
+		DeliveryMode     *string    `json:"deliveryMode"` // online / offline / hybrid
 		IsOnline         bool       `json:"isOnline"`
 		IsOffline        bool       `json:"isOffline"`
 		OfficialURL      *string    `json:"officialUrl"`
@@ -94,10 +108,19 @@ type (
 		Venue            *string    `json:"venue"`
 		Target           *string    `json:"target"`
 		Capacity         *string    `json:"capacity"`
-		Description      *string    `json:"description"`
+		Description      *string    `json:"description"` // Markdown。$...$ で数式を書けます
 		Tags             []string   `json:"tags"`
 		Speakers         []Speaker  `json:"speakers"`
 		Schedule         []Schedule `json:"schedule"`
+		// RecurrenceRule はRFC 5545のRRULE(例: "FREQ=MONTHLY;BYDAY=2SA")です。
+		RecurrenceRule    *string  `json:"recurrenceRule"`
+		RecurrenceDates   []string `json:"recurrenceDates"`
+		RecurrenceExDates []string `json:"recurrenceExdates"`
+		// 会場の住所と位置情報。緯度・経度を省略した場合は都道府県から近似します。
+		VenueAddress    *string  `json:"venueAddress"`
+		VenuePostalCode *string  `json:"venuePostalCode"`
+		Latitude        *float64 `json:"latitude"`
+		Longitude       *float64 `json:"longitude"`
 	}
 
 	CreateEventResponse struct {
@@ -108,6 +131,15 @@ type (
 		Message string `json:"message"`
 	}
 
+	// ApprovalConfirmationResponse は承認前に主催者に確認してもらうイベントの内容です。
+	ApprovalConfirmationResponse struct {
+		Message         string           `json:"message"`
+		Event           GetEventResponse `json:"event"`
+		IsAuthenticated bool             `json:"isAuthenticated"`
+		// ApproveURL に authcode をPOSTすると承認されます。
+		ApproveURL string `json:"approveUrl"`
+	}
+
 	GetEventResponse struct {
 		ID               int        `json:"id"`
 		Title            string     `json:"title"`
@@ -116,8 +148,11 @@ type (
 		StartTime        string     `json:"startTime"`
 		EndDate          string     `json:"endDate"`
 		EndTime          string     `json:"endTime"`
-		Prefecture       *string    `json:"prefecture"`
-		EventType        *string    `json:"eventType"`
+		Prefecture       *string    `json:"prefecture"`     // 都道府県名
+		PrefectureCode   *string    `json:"prefectureCode"` // JIS X 0401 コード
+		EventType        *string    `json:"eventType"`      // 種別ID
+		EventTypeName    *string    `json:"eventTypeName"`
+		DeliveryMode     string     `json:"deliveryMode"`
 		IsOnline         bool       `json:"isOnline"`
 		IsOffline        bool       `json:"isOffline"`
 		OfficialURL      *string    `json:"officialUrl"`
@@ -125,10 +160,37 @@ type (
 		Venue            *string    `json:"venue"`
 		Target           *string    `json:"target"`
 		Capacity         *string    `json:"capacity"`
-		Description      *string    `json:"description"`
+		Description      *string    `json:"description"` // Markdownのソース
+		DescriptionHTML  *string    `json:"descriptionHtml"`
 		Tags             []string   `json:"tags"`
 		Speakers         []Speaker  `json:"speakers"`
 		Schedule         []Schedule `json:"schedule"`
+		// ScheduleDays は schedule を日付ごとにまとめたものです。
+		ScheduleDays []ScheduleDay `json:"scheduleDays"`
+		// シリーズの場合の繰り返し情報
+		RecurrenceRule    *string              `json:"recurrenceRule,omitempty"`
+		RecurrenceDates   []string             `json:"recurrenceDates,omitempty"`
+		RecurrenceExDates []string             `json:"recurrenceExdates,omitempty"`
+		Overrides         []OccurrenceOverride `json:"overrides,omitempty"`
+		// オンライン講義URLは主催者・参加登録者か、開始直前から終了までの間だけ onlineLectureUrl に入ります。
+		// それ以外は hasOnlineLectureUrl でURLの有無と、onlineLectureUrlVisibleFrom で公開開始時刻を示します。
+		HasOnlineLectureURL         bool    `json:"hasOnlineLectureUrl"`
+		OnlineLectureURLVisibleFrom *string `json:"onlineLectureUrlVisibleFrom"`
+		// lectureURLChangesAt は onlineLectureUrl などの表示が次に切り替わる時刻です。キャッシュの期限に使います。
+		lectureURLChangesAt time.Time
+		// OccurrenceDate は期間指定で展開されたシリーズの各回の元の日付です。
+		OccurrenceDate *string `json:"occurrenceDate,omitempty"`
+		// 会場の住所と位置情報。locationPrecision は "exact" または "prefecture"(都道府県の代表点) です。
+		VenueAddress      *string  `json:"venueAddress"`
+		VenuePostalCode   *string  `json:"venuePostalCode"`
+		Latitude          *float64 `json:"latitude"`
+		Longitude         *float64 `json:"longitude"`
+		LocationPrecision *string  `json:"locationPrecision"`
+		// ポスター画像。未設定の場合はnullです。
+		PosterImageURL     *string `json:"posterImageUrl"`
+		PosterThumbnailURL *string `json:"posterThumbnailUrl"`
+		// OGImageURL はSNSでの共有時に使うOGP画像のURLです。
+		OGImageURL string `json:"ogImageUrl"`
 	}
 
 	ApproveEventRequest struct {
@@ -139,72 +201,68 @@ type (
 		AuthCode string `json:"authcode"`
 	}
 
+	RotateAuthCodeRequest struct {
+		Email string `json:"email"`
+	}
+
 	Speaker struct {
 		Name         string `json:"name"`
 		Title        string `json:"title"`
 		Organization string `json:"organization"`
+		// 顔写真はレスポンスでのみ返します。POST /event/:id/speakers/:index/photo で設定してください。
+		PhotoURL          string `json:"photoUrl,omitempty"`
+		PhotoThumbnailURL string `json:"photoThumbnailUrl,omitempty"`
 	}
 
+	// Schedule はスケジュールの1枠です。Speakers には speakers[].name を指定します。
+	// 1日だけのイベントでは Date を省略でき、開始日として扱います。
 	Schedule struct {
-		Time    string `json:"time"`
-		Title   string `json:"title"`
-		Speaker string `json:"speaker"`
-	}
-)
+		Date      string   `json:"date"`
+		StartTime string   `json:"startTime"`
+		EndTime   string   `json:"endTime,omitempty"`
+		Title     string   `json:"title"`
+		Room      string   `json:"room,omitempty"`
+		Track     string   `json:"track,omitempty"`
+		Speakers  []string `json:"speakers,omitempty"`
 
-// -------------------
-// Slack連携
-// -------------------
-var slackWebhookURL string
-
-// InitSlack は環境変数からWebhook URLを取得
-func InitSlack() {
-	slackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
-}
-
-// postToSlack はSlackへメッセージをPOST送信します
-func postToSlack(msg string) error {
-	if slackWebhookURL == "" {
-		return fmt.Errorf("slackWebhookURLが設定されていません")
-	}
-	payload := map[string]string{"text": msg}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("payloadのJSON変換に失敗: %w", err)
+		// Deprecated: StartTime と Speakers を使ってください。
+		Time string `json:"time,omitempty"`
+		// Deprecated: Speakers を使ってください。
+		Speaker string `json:"speaker,omitempty"`
 	}
 
-	req, err := http.NewRequest("POST", slackWebhookURL, bytes.NewBuffer(body))
-	if err != nil {
-		return fmt.Errorf("Slackへのリクエスト作成に失敗しました: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("Slackへのリクエスト送信に失敗しました: %w", err)
+	// ScheduleDay は1日分のスケジュールです。
+	ScheduleDay struct {
+		Date  string     `json:"date"`
+		Items []Schedule `json:"items"`
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Slackからの応答が異常です: %s", resp.Status)
-	}
-	fmt.Println("Slackへの通知が成功しました")
-	return nil
-}
+)
 
 // -------------------
 //  ハンドラ実装
 // -------------------
 
 // POST /api/v1/event/new
-// 通知まで含めて失敗ならROLLBACKする
 func (h *Handler) CreateEvent(c echo.Context) error {
 	req := new(CreateEventRequest)
 	if err := c.Bind(req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
 	}
 
+	eventID, err := h.submitEvent(c, req)
+	if err != nil {
+		return err
+	}
+
+	// 正常レスポンス
+	res := CreateEventResponse{
+		ID: fmt.Sprintf("%d", eventID),
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// eventParams はリクエストを検証し、登録する内容に変換します。v1とv2の作成・更新で共通です。
+func (h *Handler) eventParams(c echo.Context, req *CreateEventRequest) (repository.CreateEventParams, error) {
 	// バリデーション
 	if err := vd.ValidateStruct(
 		req,
@@ -215,24 +273,46 @@ func (h *Handler) CreateEvent(c echo.Context) error {
 		vd.Field(&req.EndDate, vd.Required),
 		vd.Field(&req.EndTime, vd.Required),
 		vd.Field(&req.Email, vd.Required, is.Email),
+		vd.Field(&req.Prefecture, vd.By(prefecture.Validate)),
+		vd.Field(&req.EventType, vd.By(eventtype.Validate)),
+		vd.Field(&req.OfficialURL, httpURL),
+		vd.Field(&req.OnlineLectureURL, httpURL),
+		vd.Field(&req.RecurrenceRule, validateRecurrenceRule),
+		vd.Field(&req.RecurrenceDates, vd.Each(vd.Date(dateLayout))),
+		vd.Field(&req.RecurrenceExDates, vd.Each(vd.Date(dateLayout))),
+		vd.Field(&req.VenuePostalCode, vd.Match(postalCodePattern)),
+		vd.Field(&req.Latitude, vd.Min(-90.0), vd.Max(90.0), notNilIf(req.Longitude != nil)),
+		vd.Field(&req.Longitude, vd.Min(-180.0), vd.Max(180.0), notNilIf(req.Latitude != nil)),
 	); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest,
+		return repository.CreateEventParams{}, echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+	if err := validateEventPeriod(req); err != nil {
+		return repository.CreateEventParams{}, echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+	mode, err := resolveDeliveryMode(req.DeliveryMode, req.IsOnline, req.IsOffline)
+	if err != nil {
+		return repository.CreateEventParams{}, echo.NewHTTPError(http.StatusBadRequest,
 			fmt.Errorf("invalid request body: %w", err))
 	}
+	if err := validateDeliveryDetails(mode, req.OnlineLectureURL, req.Venue); err != nil {
+		return repository.CreateEventParams{}, echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+	isOnline, isOffline := mode.Flags()
 
-	ctx := c.Request().Context()
+	req.Prefecture = normalizePrefecture(req.Prefecture)
+	req.EventType = normalizeEventType(req.EventType)
 
-	// 1) トランザクション開始
-	tx, err := h.repo.BeginTx(ctx)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError,
-			"failed to begin transaction").SetInternal(err)
+	// オンラインのみのイベントは近隣検索の対象にしない
+	var latitude, longitude *float64
+	var precision *string
+	if mode.RequiresVenue() {
+		latitude, longitude, precision = h.locateVenue(c, req)
 	}
-	// deferでROLLBACKを仕込む（後で成功時はCommitする）
-	defer tx.Rollback()
 
-	// 2) DB登録
-	params := repository.CreateEventParams{
+	return repository.CreateEventParams{
 		Title:            req.Title,
 		Organizer:        req.Organizer,
 		StartDate:        req.StartDate,
@@ -242,8 +322,9 @@ func (h *Handler) CreateEvent(c echo.Context) error {
 		Email:            req.Email,
 		Prefecture:       req.Prefecture,
 		EventType:        req.EventType,
-		IsOnline:         req.IsOnline,
-		IsOffline:        req.IsOffline,
+		DeliveryMode:     string(mode),
+		IsOnline:         isOnline,
+		IsOffline:        isOffline,
 		OfficialURL:      req.OfficialURL,
 		OnlineLectureURL: req.OnlineLectureURL,
 		Venue:            req.Venue,
@@ -252,154 +333,253 @@ func (h *Handler) CreateEvent(c echo.Context) error {
 		Description:      req.Description,
 		Tags:             req.Tags,
 		Speakers:         convertSpeakers(req.Speakers),
-		Schedule:         convertSchedules(req.Schedule),
+		Schedule:         convertSchedules(req.Schedule, req.StartDate),
+
+		RecurrenceRule:    req.RecurrenceRule,
+		RecurrenceDates:   req.RecurrenceDates,
+		RecurrenceExDates: req.RecurrenceExDates,
+		VenueAddress:      req.VenueAddress,
+		VenuePostalCode:   req.VenuePostalCode,
+		Latitude:          latitude,
+		Longitude:         longitude,
+		LocationPrecision: precision,
+	}, nil
+}
+
+// submitEvent はイベントを登録し、作成したイベントのIDを返します。v1とv2で共通です。
+func (h *Handler) submitEvent(c echo.Context, req *CreateEventRequest) (int, error) {
+	params, err := h.eventParams(c, req)
+	if err != nil {
+		return 0, err
 	}
 
-	eventID, authCode, err := h.repo.CreateEventTx(ctx, tx, params)
+	eventID, err := h.svc.SubmitEvent(c.Request().Context(), params)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError,
+		if errors.Is(err, service.ErrNotification) {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError,
+				"failed to send Slack notification").SetInternal(err)
+		}
+		return 0, echo.NewHTTPError(http.StatusInternalServerError,
 			"failed to create event").SetInternal(err)
 	}
+	h.invalidateEvents(c)
+	return eventID, nil
+}
 
-	// 3) Slack通知
-	InitSlack()
-	authLink := fmt.Sprintf(config.CORE_FRONTEND_URL+"/events/update/%d?authcode=%s",
-		eventID, authCode)
-	slackMessage := fmt.Sprintf(
-		"新しいイベントが作成されました。\nタイトル: %s\nオーガナイザー: %s\n認証リンク: %s",
-		req.Title, req.Organizer, authLink,
-	)
-	if err := postToSlack(slackMessage); err != nil {
-		// 失敗→ROLLBACKしてエラー応答
-		return echo.NewHTTPError(http.StatusInternalServerError,
-			"failed to send Slack notification").SetInternal(err)
+// newGetEventResponse はイベントをレスポンスの形に変換します。
+func (h *Handler) newGetEventResponse(event *repository.Event) GetEventResponse {
+	schedule := convertSchedulesToResponse(event.Schedule, event.StartDate)
+	res := GetEventResponse{
+		ID:                event.ID,
+		Title:             event.Title,
+		Organizer:         event.Organizer,
+		StartDate:         event.StartDate,
+		StartTime:         event.StartTime,
+		EndDate:           event.EndDate,
+		EndTime:           event.EndTime,
+		Prefecture:        prefectureName(event.Prefecture),
+		PrefectureCode:    event.Prefecture,
+		EventType:         event.EventType,
+		EventTypeName:     eventTypeName(event.EventType),
+		DeliveryMode:      event.DeliveryMode,
+		IsOnline:          event.IsOnline,
+		IsOffline:         event.IsOffline,
+		OfficialURL:       event.OfficialURL,
+		OnlineLectureURL:  event.OnlineLectureURL,
+		Venue:             event.Venue,
+		Target:            event.Target,
+		Capacity:          event.Capacity,
+		Description:       event.Description,
+		DescriptionHTML:   event.DescriptionHTML,
+		Tags:              event.Tags,
+		Speakers:          convertSpeakersToResponse(event.Speakers),
+		Schedule:          schedule,
+		ScheduleDays:      groupScheduleByDay(schedule),
+		RecurrenceRule:    event.RecurrenceRule,
+		RecurrenceDates:   event.RecurrenceDates,
+		RecurrenceExDates: event.RecurrenceExDates,
+		Overrides:         convertOverridesToResponse(event.Overrides),
+		VenueAddress:      event.VenueAddress,
+		VenuePostalCode:   event.VenuePostalCode,
+		Latitude:          event.Latitude,
+		Longitude:         event.Longitude,
+		LocationPrecision: event.LocationPrecision,
+		OGImageURL:        ogImageURL(event.ID),
+	}
+	// description_html を追加する前に登録し、まだ編集されていないイベントだけはここで変換する
+	if res.DescriptionHTML == nil && event.Description != nil {
+		if html, err := markdown.ToHTML(*event.Description); err == nil {
+			res.DescriptionHTML = &html
+		}
 	}
+	h.setImageURLs(&res, event)
+	setLectureURLVisibility(&res, event, time.Now())
+	return res
+}
 
-	// 4) COMMIT
-	if err := tx.Commit(); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError,
-			"failed to commit transaction").SetInternal(err)
+// GET /api/v1/event/all
+// from/to (YYYY-MM-DD) を指定すると、その期間のイベントを返し、シリーズは各回に展開します。
+// レスポンスはキャッシュし、ETag / Last-Modified による条件付きリクエストに対応します。
+func (h *Handler) GetEvents(c echo.Context) error {
+	from, to, err := parseDateRange(c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid date range").SetInternal(err)
 	}
 
-	// 正常レスポンス
-	res := CreateEventResponse{
-		ID: fmt.Sprintf("%d", eventID),
+	params := repository.GetEventsParams{}
+	if !from.IsZero() {
+		params.From = from.Format(dateLayout)
+		params.To = to.Format(dateLayout)
 	}
-	return c.JSON(http.StatusOK, res)
+
+	return h.serveEvents(c, true, func(now time.Time) (*cachedResponse, error) {
+		events, err := h.repo.GetEvents(c.Request().Context(), params)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+
+		eventsResponse := make([]GetEventResponse, 0, len(events))
+		for _, event := range events {
+			if from.IsZero() || !event.IsSeries() {
+				eventsResponse = append(eventsResponse, h.newGetEventResponse(event))
+				continue
+			}
+
+			occurrences, err := h.expandOccurrences(event, from, to.Add(24*time.Hour-time.Second))
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+			}
+			eventsResponse = append(eventsResponse, occurrences...)
+		}
+		if !from.IsZero() {
+			sortEventResponses(eventsResponse)
+		}
+
+		res, err := jsonResponse(eventsResponse, now)
+		if err != nil {
+			return nil, err
+		}
+		res.ChangesAt = nextLectureURLChange(eventsResponse...)
+		return res, nil
+	})
 }
 
-// GET /api/v1/event/all
-func (h *Handler) GetEvents(c echo.Context) error {
-	events, err := h.repo.GetEvents(c.Request().Context())
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
-	}
-	eventsResponse := make([]GetEventResponse, len(events))
-	for i, event := range events {
-		eventsResponse[i] = GetEventResponse{
-			ID:               event.ID,
-			Title:            event.Title,
-			Organizer:        event.Organizer,
-			StartDate:        event.StartDate,
-			StartTime:        event.StartTime,
-			EndDate:          event.EndDate,
-			EndTime:          event.EndTime,
-			Prefecture:       event.Prefecture,
-			EventType:        event.EventType,
-			IsOnline:         event.IsOnline,
-			IsOffline:        event.IsOffline,
-			OfficialURL:      event.OfficialURL,
-			OnlineLectureURL: event.OnlineLectureURL,
-			Venue:            event.Venue,
-			Target:           event.Target,
-			Capacity:         event.Capacity,
-			Description:      event.Description,
-			Tags:             event.Tags,
-			Speakers:         convertSpeakersToResponse(event.Speakers),
-			Schedule:         convertSchedulesToResponse(event.Schedule),
+// parseDateRange は from/to クエリを解釈します。両方空なら期間指定なしとしてゼロ値を返します。
+// 片方だけ指定された場合、from は今日、to は from の1年後を既定値とします。
+func parseDateRange(fromParam, toParam string) (time.Time, time.Time, error) {
+	if fromParam == "" && toParam == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+
+	var from, to time.Time
+	var err error
+	if fromParam != "" {
+		if from, err = time.Parse(dateLayout, fromParam); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	} else {
+		from, _ = time.Parse(dateLayout, time.Now().Format(dateLayout))
+	}
+	if toParam != "" {
+		if to, err = time.Parse(dateLayout, toParam); err != nil {
+			return time.Time{}, time.Time{}, err
 		}
+	} else {
+		to = from.AddDate(1, 0, 0)
 	}
-	return c.JSON(http.StatusOK, eventsResponse)
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must not be before from")
+	}
+
+	return from, to, nil
 }
 
 // GET /api/v1/event/update/:id
-func (h *Handler) UpdateEvent(c echo.Context) error {
-	idParam := c.Param("id")
-	authCode := c.QueryParam("auth_code")
-
-	id, err := strconv.Atoi(idParam)
+// Deprecated: 以前はGETでイベントを承認していましたが、Slackなどのリンクのプレビュー取得で
+// 承認されてしまうため、承認内容の確認だけを返すようにしました。承認は POST /api/v1/event/:id/approve で行います。
+func (h *Handler) GetApprovalConfirmation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
 	}
 
-	authCodeUUID, err := uuid.Parse(authCode)
-	if err != nil {
+	authCode, err := queryAuthCode(c)
+	if err == nil && authCode == uuid.Nil {
+		// 旧形式のリンク(?auth_code=)も受け付ける
+		authCode, err = parseAuthCode(c.QueryParam(legacyAuthCodeKey))
+	}
+	if err != nil || authCode == uuid.Nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
 	}
 
-	err = h.repo.AuthenticateEvent(c.Request().Context(), id, authCodeUUID)
+	event, err := h.repo.GetEvent(c.Request().Context(), id, authCode)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "authentication failed").SetInternal(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
+	}
+	if event == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
 	}
 
-	res := UpdateEventResponse{
-		Message: "Event authenticated successfully",
+	approveURL := fmt.Sprintf("/api/v1/event/%d/approve", id)
+	// Deprecation ヘッダは /api/v1 全体に DeprecatedAPI で付けている
+	c.Response().Header().Add("Link", "<"+approveURL+">; rel=\"successor-version\"")
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	res := ApprovalConfirmationResponse{
+		Message:         "Send POST " + approveURL + " with the authcode to approve this event",
+		Event:           h.newGetEventResponse(event),
+		IsAuthenticated: event.IsAuthenticated,
+		ApproveURL:      approveURL,
 	}
 	return c.JSON(http.StatusOK, res)
 }
 
 // GET /api/v1/event/:id
+// 主催者は authcode、参加登録者は attendee を指定するとオンライン講義URLを取得できます。
 func (h *Handler) GetEvent(c echo.Context) error {
 	idParam := c.Param("id")
-	authCode := c.QueryParam("authcode")
 
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
 	}
 
-	var codeUUID uuid.UUID
-	if authCode != "" {
-		codeUUID, err = uuid.Parse(authCode)
+	codeUUID, err := queryAuthCode(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+
+	// 主催者・参加登録者向けのレスポンスはキャッシュしない
+	public := codeUUID == uuid.Nil && c.QueryParam("attendee") == ""
+	return h.serveEvents(c, public, func(now time.Time) (*cachedResponse, error) {
+		event, err := h.repo.GetEvent(c.Request().Context(), id, codeUUID)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+		if event == nil {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "event not found")
 		}
-	}
 
-	event, err := h.repo.GetEvent(c.Request().Context(), id, codeUUID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
-	}
-	if event == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "event not found")
-	}
+		res := h.newGetEventResponse(event)
 
-	res := GetEventResponse{
-		ID:               event.ID,
-		Title:            event.Title,
-		Organizer:        event.Organizer,
-		StartDate:        event.StartDate,
-		StartTime:        event.StartTime,
-		EndDate:          event.EndDate,
-		EndTime:          event.EndTime,
-		Prefecture:       event.Prefecture,
-		EventType:        event.EventType,
-		IsOnline:         event.IsOnline,
-		IsOffline:        event.IsOffline,
-		OfficialURL:      event.OfficialURL,
-		OnlineLectureURL: event.OnlineLectureURL,
-		Venue:            event.Venue,
-		Target:           event.Target,
-		Capacity:         event.Capacity,
-		Description:      event.Description,
-		Tags:             event.Tags,
-		Speakers:         convertSpeakersToResponse(event.Speakers),
-		Schedule:         convertSchedulesToResponse(event.Schedule),
-	}
-	return c.JSON(http.StatusOK, res)
+		canView, err := h.canViewLectureURL(c, id, codeUUID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+		if canView {
+			res.OnlineLectureURL = event.OnlineLectureURL
+		}
+		cached, err := jsonResponse(res, now)
+		if err != nil {
+			return nil, err
+		}
+		cached.ChangesAt = nextLectureURLChange(res)
+		return cached, nil
+	})
 }
 
 // ApproveEvent はイベントを承認します。
+// POST /api/v1/event/:id/approve
 func (h *Handler) ApproveEvent(c echo.Context) error {
 	idParam := c.Param("id")
 	var req ApproveEventRequest
@@ -412,31 +592,41 @@ func (h *Handler) ApproveEvent(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
 	}
 
-	authCodeUUID, err := uuid.Parse(req.AuthCode)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	if err := h.approveEvent(c, id, req.AuthCode); err != nil {
+		if errors.Is(err, service.ErrAlreadyApproved) {
+			return echo.NewHTTPError(http.StatusBadRequest, AlreadyApproved)
+		}
+		return err
 	}
 
-	event, err := h.repo.GetEvent(c.Request().Context(), id, authCodeUUID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
-	}
-	if event == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "event not found")
-	}
-	if event.IsAuthenticated {
-		return echo.NewHTTPError(http.StatusBadRequest, AlreadyApproved)
+	res := UpdateEventResponse{
+		Message: "Event approved successfully",
 	}
+	return c.JSON(http.StatusOK, res)
+}
 
-	err = h.repo.AuthenticateEvent(c.Request().Context(), id, authCodeUUID)
+// approveEvent は認証コードを解釈してイベントを承認します。v1とv2で共通です。
+// 承認済みの場合、v1は400、v2は409で返すため service.ErrAlreadyApproved をそのまま返します。
+func (h *Handler) approveEvent(c echo.Context, id int, authCode string) error {
+	authCodeUUID, err := uuid.Parse(authCode)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "authentication failed").SetInternal(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
 	}
 
-	res := UpdateEventResponse{
-		Message: "Event approved successfully",
+	err = h.svc.ApproveEvent(c.Request().Context(), id, authCodeUUID)
+	switch {
+	case err == nil:
+		h.invalidateEvents(c)
+		return nil
+	case errors.Is(err, service.ErrAlreadyApproved):
+		return err
+	case errors.Is(err, service.ErrEventNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	case errors.Is(err, service.ErrInvalidAuthCode):
+		return echo.NewHTTPError(http.StatusBadRequest, "authentication failed").SetInternal(err)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to approve event").SetInternal(err)
 	}
-	return c.JSON(http.StatusOK, res)
 }
 
 // RejectEvent はイベントを拒否します（イベントが存在し、未承認であることを確認して200を返します）。
@@ -458,16 +648,14 @@ func (h *Handler) RejectEvent(c echo.Context) error {
 	}
 
 	// イベントが存在し、未承認であることを確認
-	event, err := h.repo.GetEvent(c.Request().Context(), id, authCodeUUID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
-	}
-	if event == nil {
+	err = h.svc.RejectEvent(c.Request().Context(), id, authCodeUUID)
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
 		return echo.NewHTTPError(http.StatusNotFound, "event not found")
-	}
-
-	if event.IsAuthenticated {
+	case errors.Is(err, service.ErrAlreadyApproved):
 		return echo.NewHTTPError(http.StatusBadRequest, AlreadyApproved)
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
 	}
 
 	// なにもしないが200を返す
@@ -476,3 +664,39 @@ func (h *Handler) RejectEvent(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, res)
 }
+
+// RotateAuthCode はイベントの連絡先メールアドレス宛てに新しい認証リンクを送信します。
+// 古い認証コードは無効になります。メールアドレスの一致・不一致はレスポンスから判別できないようにしています。
+// POST /api/v1/event/:id/authcode
+func (h *Handler) RotateAuthCode(c echo.Context) error {
+	idParam := c.Param("id")
+	var req RotateAuthCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	if err := vd.ValidateStruct(
+		&req,
+		vd.Field(&req.Email, vd.Required, is.Email),
+	); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := h.svc.RotateAuthCode(c.Request().Context(), id, req.Email); err != nil {
+		if errors.Is(err, service.ErrNotification) {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to send mail").SetInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to rotate auth code").SetInternal(err)
+	}
+
+	res := UpdateEventResponse{
+		Message: "If the email matches the event, a new link has been sent",
+	}
+	return c.JSON(http.StatusAccepted, res)
+}
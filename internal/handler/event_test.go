@@ -5,8 +5,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -15,33 +19,40 @@ import (
 	// テスト対象のパッケージ
 	"github.com/ras0q/go-backend-template/internal/handler"
 	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
 )
 
+var assertAnError = errors.New("assert.AnError general error for testing")
+
+// newTestServer はメモリ上のリポジトリを使うハンドラーを /api/v1 にルーティングします。
+func newTestServer(notifier service.Notifier) (*echo.Echo, *repository.Memory) {
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, notifier, nil), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+	return e, repo
+}
+
+func doRequest(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
 // TestCreateEvent_Success は正常系のハンドラテスト例
 func TestCreateEvent_Success(t *testing.T) {
-	// 1) Repositoryモックを用意
-	mockRepo := &repository.MockRepository{
-		BeginTxFunc: func(ctx context.Context) (*repository.MockTx, error) {
-			// ここでは MockTx struct を作って返すイメージ
-			// 実装は省略
-			return &repository.MockTx{}, nil
-		},
-		CreateEventTxFunc: func(ctx context.Context, tx *repository.MockTx, params repository.CreateEventParams) (int, string, error) {
-			return 123, "abc-auth-code", nil
-		},
-	}
-
-	// 2) Slack送信をモック
-	originalSlackPoster := handler.SlackPoster
-	defer func() { handler.SlackPoster = originalSlackPoster }()
-	handler.SlackPoster = func(msg string) error {
+	// 1) Slack送信をモック
+	var slackMessage string
+	slackPoster := service.NotifierFunc(func(ctx context.Context, msg string) error {
 		// 成功するモック
+		slackMessage = msg
 		return nil
-	}
+	})
 
-	// 3) Handlerを作り、POSTリクエスト
-	h := handler.New(mockRepo)
-	e := echo.New()
+	// 2) メモリ上のリポジトリでサーバーを作り、POSTリクエスト
+	e, repo := newTestServer(slackPoster)
 
 	// リクエストボディ
 	reqBody := `{
@@ -51,45 +62,82 @@ func TestCreateEvent_Success(t *testing.T) {
         "startTime":"09:00:00",
         "endDate":"2025-01-01",
         "endTime":"10:00:00",
-        "email":"test@example.com"
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
     }`
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/event/new", bytes.NewBufferString(reqBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	rec := httptest.NewRecorder()
-
-	c := e.NewContext(req, rec)
-
-	// テスト実行
-	err := h.CreateEvent(c)
-	require.NoError(t, err)
-	require.Equal(t, http.StatusOK, rec.Code)
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", reqBody)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
 
 	// レスポンスをチェック
 	var got handler.CreateEventResponse
-	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	err := json.Unmarshal(rec.Body.Bytes(), &got)
 	require.NoError(t, err)
-	require.Equal(t, "123", got.ID)
+
+	// 認証リンク付きで通知し、コミットされる
+	link := fmt.Sprintf("/events/update/%s?authcode=", got.ID)
+	require.Contains(t, slackMessage, link)
+	authCode := strings.Fields(slackMessage[strings.Index(slackMessage, link)+len(link):])[0]
+	pending, err := repo.GetEvents(context.Background(), repository.GetEventsParams{Status: repository.StatusPending})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	// 承認前のイベントは認証コードがなければ見えない
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/"+got.ID, "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/"+got.ID+"?authcode="+authCode, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var event handler.GetEventResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &event))
+	require.Equal(t, "Test Event", event.Title)
 }
 
-// TestCreateEvent_SlackFail はSlack通知が失敗するケース
-func TestCreateEvent_SlackFail(t *testing.T) {
-	mockRepo := &repository.MockRepository{
-		BeginTxFunc: func(ctx context.Context) (*repository.MockTx, error) {
-			return &repository.MockTx{}, nil
-		},
-		CreateEventTxFunc: func(ctx context.Context, tx *repository.MockTx, params repository.CreateEventParams) (int, string, error) {
-			return 123, "abc-auth-code", nil
-		},
+// TestCreateEvent_NormalizesPrefectureEventType は都道府県と種別を正規の値で保存し、空の値はNULLにすることを確認します。
+func TestCreateEvent_NormalizesPrefectureEventType(t *testing.T) {
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error { return nil })
+
+	tests := []struct {
+		name, prefecture, eventType string
+		wantPrefecture, wantType    *string
+	}{
+		{name: "表記を揃える", prefecture: "東京", eventType: "講演会", wantPrefecture: ptr("13"), wantType: ptr("lecture")},
+		{name: "空文字列", prefecture: "", eventType: ""},
+		{name: "空白だけ", prefecture: "  ", eventType: " "},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, repo := newTestServer(notifier)
+			rec := doRequest(e, http.MethodPost, "/api/v1/event/new", fmt.Sprintf(`{
+        "title":"Normalize",
+        "organizer":"Test Org",
+        "startDate":"2025-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2025-01-01",
+        "endTime":"10:00:00",
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall",
+        "prefecture":%q,
+        "eventType":%q
+    }`, tt.prefecture, tt.eventType))
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
 
-	originalSlackPoster := handler.SlackPoster
-	defer func() { handler.SlackPoster = originalSlackPoster }()
-	handler.SlackPoster = func(msg string) error {
-		return assertAnError // 適当なエラーを返す
+			events, err := repo.GetEvents(context.Background(), repository.GetEventsParams{Status: repository.StatusPending})
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			require.Equal(t, tt.wantPrefecture, events[0].Prefecture)
+			require.Equal(t, tt.wantType, events[0].EventType)
+		})
 	}
+}
 
-	h := handler.New(mockRepo)
-	e := echo.New()
+// TestCreateEvent_SlackFail はSlack通知が失敗するケース
+func TestCreateEvent_SlackFail(t *testing.T) {
+	slackPoster := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		return assertAnError // 適当なエラーを返す
+	})
+
+	e, repo := newTestServer(slackPoster)
 
 	reqBody := `{
         "title":"Fail Slack",
@@ -98,20 +146,240 @@ func TestCreateEvent_SlackFail(t *testing.T) {
         "startTime":"09:00:00",
         "endDate":"2025-01-01",
         "endTime":"10:00:00",
-        "email":"test@example.com"
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
     }`
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/event/new", bytes.NewBufferString(reqBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	rec := httptest.NewRecorder()
-
-	c := e.NewContext(req, rec)
-	err := h.CreateEvent(c)
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", reqBody)
 
 	// Slack通知で失敗 → 500エラーを想定
-	require.Error(t, err)
-	httpErr, ok := err.(*echo.HTTPError)
-	require.True(t, ok)
-	require.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// 通知に失敗したらROLLBACKし、イベントは残らない
+	events, err := repo.GetEvents(context.Background(), repository.GetEventsParams{Status: repository.StatusAll})
+	require.NoError(t, err)
+	require.Empty(t, events)
 }
 
 // ほかにも、バリデーションエラー、DBエラーなど様々なケースを網羅
+
+// TestGetApprovalConfirmation は通知のリンクを開いても承認されず、確認内容だけを返すことを確認します。
+func TestGetApprovalConfirmation(t *testing.T) {
+	var messages []string
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	e, repo := newTestServer(notifier)
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", `{
+        "title":"Link Preview",
+        "organizer":"Test Org",
+        "startDate":"2025-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2025-01-01",
+        "endTime":"10:00:00",
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
+    }`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var created handler.CreateEventResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	id, err := strconv.Atoi(created.ID)
+	require.NoError(t, err)
+	link := fmt.Sprintf("/events/update/%s?authcode=", created.ID)
+	require.Contains(t, messages[0], link)
+	authCode := strings.Fields(messages[0][strings.Index(messages[0], link)+len(link):])[0]
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"authcode", "?authcode=" + authCode, http.StatusOK},
+		{"legacy auth_code", "?auth_code=" + authCode, http.StatusOK},
+		{"missing", "", http.StatusBadRequest},
+		{"malformed", "?authcode=not-a-uuid", http.StatusBadRequest},
+		{"wrong", "?authcode=00000000-0000-4000-8000-000000000000", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/api/v1/event/update/"+created.ID+tt.query, "")
+			require.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want == http.StatusOK {
+				var res handler.ApprovalConfirmationResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+				require.False(t, res.IsAuthenticated)
+				require.Equal(t, "Link Preview", res.Event.Title)
+				require.Equal(t, "/api/v1/event/"+created.ID+"/approve", res.ApproveURL)
+				require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			}
+
+			// GETでは承認しない
+			event, err := repo.GetEventForReview(context.Background(), id)
+			require.NoError(t, err)
+			require.False(t, event.IsAuthenticated)
+			rec = doRequest(e, http.MethodGet, "/api/v1/event/"+created.ID, "")
+			require.Equal(t, http.StatusNotFound, rec.Code, "the event must stay pending")
+		})
+	}
+
+	// 承認はPOSTで行い、そのあとの確認では承認済みと返す
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+created.ID+"/approve", fmt.Sprintf(`{"authcode":%q}`, authCode))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/update/"+created.ID+"?authcode="+authCode, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var res handler.ApprovalConfirmationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	require.True(t, res.IsAuthenticated)
+}
+
+// TestRotateAuthCode_CannotApprove は、再発行した認証リンクで申請者が自分のイベントを承認できず、
+// 運営者に通知した承認リンクは使えるままであることを確かめます。
+func TestRotateAuthCode_CannotApprove(t *testing.T) {
+	var mailBody, slackMessage string
+	mailer := service.MailerFunc(func(to, subject, body string) error {
+		mailBody = body
+		return nil
+	})
+	repo := repository.NewMemory()
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		slackMessage = msg
+		return nil
+	})
+	h := handler.New(repo, service.New(repo, notifier, mailer), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", `{
+        "title":"Self Approval",
+        "organizer":"Test Org",
+        "startDate":"2025-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2025-01-01",
+        "endTime":"10:00:00",
+        "email":"test@example.com",
+        "deliveryMode":"offline",
+        "venue":"Test Hall"
+    }`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var created handler.CreateEventResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+created.ID+"/authcode", `{"email":"test@example.com"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	link := fmt.Sprintf("/events/update/%s?authcode=", created.ID)
+	require.Contains(t, mailBody, link)
+	authCode := strings.Fields(mailBody[strings.Index(mailBody, link)+len(link):])[0]
+
+	// 再発行したコードで閲覧・編集はできる
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/"+created.ID+"?authcode="+authCode, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+created.ID+"/approve", fmt.Sprintf(`{"authcode":%q}`, authCode))
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/"+created.ID, "")
+	require.Equal(t, http.StatusNotFound, rec.Code, "the event must stay pending")
+
+	// 再発行しても、Slackの承認リンクで承認できる
+	require.Contains(t, slackMessage, link)
+	approvalCode := strings.Fields(slackMessage[strings.Index(slackMessage, link)+len(link):])[0]
+	require.NotEqual(t, authCode, approvalCode)
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+created.ID+"/approve", fmt.Sprintf(`{"authcode":%q}`, approvalCode))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/api/v1/event/"+created.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// TestUpdateEvent_ApprovedEvent は、主催者が承認済みのイベントを編集すると非公開に戻り、
+// Slackに通知した新しい承認リンクで承認し直せることを確かめます。
+func TestUpdateEvent_ApprovedEvent(t *testing.T) {
+	var mailBody string
+	mailer := service.MailerFunc(func(to, subject, body string) error {
+		mailBody = body
+		return nil
+	})
+	var messages []string
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, notifier, mailer), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+	h.SetupV2Routes(e.Group("/api/v2"))
+	id, approvalCode := submitAndApprove(t, e, &messages, "Approved Event")
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/"+id+"/authcode", `{"email":"test@example.com"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	link := fmt.Sprintf("/events/update/%s?authcode=", id)
+	require.Contains(t, mailBody, link)
+	authCode := strings.Fields(mailBody[strings.Index(mailBody, link)+len(link):])[0]
+
+	// 運営者が承認リンクで編集しても公開されたまま
+	rec = doRequest(e, http.MethodPatch, "/api/v2/events/"+id+"?authcode="+approvalCode, `{"title":"Fixed Typo"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/api/v2/events/"+id, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "Fixed Typo")
+
+	rec = doRequest(e, http.MethodPatch, "/api/v2/events/"+id+"?authcode="+authCode, `{"title":"Rewritten Event"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "Rewritten Event")
+	rec = doRequest(e, http.MethodGet, "/api/v2/events/"+id, "")
+	require.Equal(t, http.StatusNotFound, rec.Code, "the edited event must wait for approval")
+
+	msg := messages[len(messages)-1]
+	require.Contains(t, msg, "Rewritten Event")
+	require.Contains(t, msg, link)
+	newApprovalCode := strings.Fields(msg[strings.Index(msg, link)+len(link):])[0]
+	require.NotEqual(t, approvalCode, newApprovalCode)
+	rec = doRequest(e, http.MethodPost, "/api/v1/event/"+id+"/approve", fmt.Sprintf(`{"authcode":%q}`, newApprovalCode))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/api/v2/events/"+id, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "Rewritten Event")
+}
+
+// TestUpdateEvent_DeliveryPatch は、PATCHで開催形態を deliveryMode と isOnline/isOffline のどちらの形式でも変えられることを確かめます。
+func TestUpdateEvent_DeliveryPatch(t *testing.T) {
+	var messages []string
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, notifier, nil), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+	h.SetupV2Routes(e.Group("/api/v2"))
+	id, authCode := submitAndApprove(t, e, &messages, "Offline Event")
+	path := "/api/v2/events/" + id + "?authcode=" + authCode
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"isOnline/isOffline だけ", `{"isOnline":true,"isOffline":false,"onlineLectureUrl":"https://example.com/live"}`, "online"},
+		{"isOffline だけ", `{"isOffline":true}`, "hybrid"},
+		{"deliveryMode だけ", `{"deliveryMode":"offline"}`, "offline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodPatch, path, tt.body)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			require.Contains(t, rec.Body.String(), fmt.Sprintf(`"deliveryMode":%q`, tt.want))
+		})
+	}
+
+	rec := doRequest(e, http.MethodPatch, path, `{"deliveryMode":"online","isOnline":false,"isOffline":true}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
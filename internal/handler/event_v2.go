@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+// eventRequest は登録済みのイベントを CreateEventRequest の形に戻します。PATCHで差分を当てる元になります。
+// 都道府県から近似した位置情報は含めず、更新後の住所から求め直します。
+func eventRequest(event *repository.Event) *CreateEventRequest {
+	deliveryMode := event.DeliveryMode
+	req := &CreateEventRequest{
+		Title:             event.Title,
+		Organizer:         event.Organizer,
+		StartDate:         event.StartDate,
+		StartTime:         event.StartTime,
+		EndDate:           event.EndDate,
+		EndTime:           event.EndTime,
+		Email:             event.Email,
+		Prefecture:        event.Prefecture,
+		EventType:         event.EventType,
+		DeliveryMode:      &deliveryMode,
+		IsOnline:          event.IsOnline,
+		IsOffline:         event.IsOffline,
+		OfficialURL:       event.OfficialURL,
+		OnlineLectureURL:  event.OnlineLectureURL,
+		Venue:             event.Venue,
+		Target:            event.Target,
+		Capacity:          event.Capacity,
+		Description:       event.Description,
+		Tags:              event.Tags,
+		Speakers:          convertSpeakersToResponse(event.Speakers),
+		Schedule:          convertSchedulesToResponse(event.Schedule, event.StartDate),
+		RecurrenceRule:    event.RecurrenceRule,
+		RecurrenceDates:   event.RecurrenceDates,
+		RecurrenceExDates: event.RecurrenceExDates,
+		VenueAddress:      event.VenueAddress,
+		VenuePostalCode:   event.VenuePostalCode,
+	}
+	if event.LocationPrecision != nil && *event.LocationPrecision == string(geo.PrecisionExact) {
+		req.Latitude = event.Latitude
+		req.Longitude = event.Longitude
+	}
+	return req
+}
+
+// eventURL はv2でのイベントのURLです。
+func eventURL(id int) string {
+	return fmt.Sprintf("/api/v2/events/%d", id)
+}
+
+// authorizeEvent は authcode クエリを確かめて、主催者として操作できるイベントを返します。
+// イベントがなければ404、認証コードが正しくなければ403を返します。
+func (h *Handler) authorizeEvent(c echo.Context) (*repository.Event, uuid.UUID, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+	authCode, err := queryAuthCode(c)
+	if err != nil {
+		return nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+
+	event, err := h.svc.AuthorizeEvent(c.Request().Context(), id, authCode)
+	if err != nil {
+		return nil, uuid.Nil, ownerActionError(err, "failed to retrieve event")
+	}
+	return event, authCode, nil
+}
+
+// ownerActionError は主催者の操作でのサービスのエラーをHTTPのエラーに変換します。
+func ownerActionError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	case errors.Is(err, service.ErrInvalidAuthCode):
+		return echo.NewHTTPError(http.StatusForbidden, "invalid auth code")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, message).SetInternal(err)
+	}
+}
+
+// organizerEventResponse は主催者向けに、オンライン講義URLを伏せずにイベントを返します。
+func (h *Handler) organizerEventResponse(c echo.Context, status int, id int, authCode uuid.UUID) error {
+	event, err := h.repo.GetEvent(c.Request().Context(), id, authCode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
+	}
+	if event == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	}
+
+	res := h.newGetEventResponse(event)
+	res.OnlineLectureURL = event.OnlineLectureURL
+	return c.JSON(status, res)
+}
+
+// POST /api/v2/events
+// 登録内容は POST /api/v1/event/new と同じです。作成したイベントのURLを Location で返します。
+func (h *Handler) CreateEventV2(c echo.Context) error {
+	req := new(CreateEventRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+
+	eventID, err := h.submitEvent(c, req)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, eventURL(eventID))
+	return c.JSON(http.StatusCreated, CreateEventResponse{
+		ID: strconv.Itoa(eventID),
+	})
+}
+
+// PATCH /api/v2/events/:id?authcode=
+// JSON Merge Patch (RFC 7396) としてボディのフィールドだけを書き換えます。null を指定した項目は削除されます。
+// 主催者が承認済みのイベントを書き換えた場合は未承認に戻し、運営者に承認し直してもらいます。
+// 開催形態は deliveryMode と isOnline/isOffline のうち、ボディで指定した形式から決めます。
+func (h *Handler) UpdateEvent(c echo.Context) error {
+	event, authCode, err := h.authorizeEvent(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+	req := eventRequest(event)
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+	mergeDeliveryPatch(req, patch)
+
+	params, err := h.eventParams(c, req)
+	if err != nil {
+		return err
+	}
+
+	orphaned, err := h.svc.UpdateEvent(c.Request().Context(), event.ID, authCode, params)
+	if err != nil {
+		return ownerActionError(err, "failed to update event")
+	}
+	h.deleteImages(c, orphaned...)
+	h.invalidateEvents(c)
+
+	return h.organizerEventResponse(c, http.StatusOK, event.ID, authCode)
+}
+
+// DELETE /api/v2/events/:id?authcode=
+// イベントを取り下げます。未承認のイベントの却下にも使います。
+func (h *Handler) DeleteEvent(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+	authCode, err := queryAuthCode(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+
+	keys, err := h.svc.DeleteEvent(c.Request().Context(), id, authCode)
+	if err != nil {
+		return ownerActionError(err, "failed to delete event")
+	}
+	h.deleteImages(c, keys...)
+	h.ogCache.Delete(id)
+	h.invalidateEvents(c)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// POST /api/v2/events/:id/approval
+// イベントを承認し、承認後のイベントを返します。承認済みの場合は409を返します。
+func (h *Handler) CreateEventApproval(c echo.Context) error {
+	var req ApproveEventRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	if err := h.approveEvent(c, id, req.AuthCode); err != nil {
+		if errors.Is(err, service.ErrAlreadyApproved) {
+			return echo.NewHTTPError(http.StatusConflict, AlreadyApproved)
+		}
+		return err
+	}
+
+	authCode, _ := uuid.Parse(req.AuthCode)
+	return h.organizerEventResponse(c, http.StatusCreated, id, authCode)
+}
@@ -0,0 +1,9 @@
+package handler
+
+// テストからスケジュールの変換と検証を確かめるために公開します。
+var (
+	ValidateEventPeriod = validateEventPeriod
+	NormalizeSchedules  = normalizeSchedules
+	GroupScheduleByDay  = groupScheduleByDay
+	ShiftScheduleDates  = shiftScheduleDates
+)
@@ -1,18 +1,72 @@
 package handler
 
 import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/cache"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/ogp"
+	"github.com/ras0q/go-backend-template/internal/pkg/storage"
 	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+// Repository はハンドラーが直接使うDBアクセスです。
+// 複数の処理をまとめる業務の流れは service.Service を通します。
+type Repository interface {
+	GetEvents(ctx context.Context, params repository.GetEventsParams) ([]*repository.Event, error)
+	GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*repository.Event, error)
+	VerifyAuthCode(ctx context.Context, id int, authCode uuid.UUID) (bool, error)
+	SetPosterImage(ctx context.Context, id int, imageKey, thumbnailKey string) ([]string, error)
+	SetSpeakerPhoto(ctx context.Context, id, index int, photoKey, thumbnailKey string) ([]string, error)
+	UpsertOccurrenceOverride(ctx context.Context, o repository.OccurrenceOverride) error
+	VerifyRegistration(ctx context.Context, eventID int, token uuid.UUID) (bool, error)
+	GetAdminByToken(ctx context.Context, token uuid.UUID) (*repository.Admin, error)
+	GetUsers(ctx context.Context) ([]*repository.User, error)
+	CreateUser(ctx context.Context, params repository.CreateUserParams) (uuid.UUID, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (*repository.User, error)
+}
+
+var (
+	_ Repository = (*repository.Repository)(nil)
+	_ Repository = (*repository.Memory)(nil)
+)
+
 type Handler struct {
-	repo *repository.Repository
+	repo     Repository
+	svc      *service.Service
+	geocoder geo.Geocoder
+	storage  storage.Storage
+
+	ogRenderer *ogp.Renderer
+	ogCache    *ogp.Cache
+
+	// respCache は公開しているイベントのレスポンスのキャッシュです。
+	respCache cache.Cache
+
+	// shuttingDown はサーバーが終了処理に入ったかです。BeginShutdown で立てます。
+	shuttingDown atomic.Bool
+	// healthChecks は /readyz で確かめる依存先です。
+	healthChecks []HealthCheck
 }
 
-func New(repo *repository.Repository) *Handler {
+// New はハンドラーを作成します。respCache が nil の場合は、プロセス内のキャッシュを使います。
+func New(repo Repository, svc *service.Service, geocoder geo.Geocoder, storage storage.Storage, ogRenderer *ogp.Renderer, respCache cache.Cache) *Handler {
+	if respCache == nil {
+		respCache = cache.NewMemory(responseCacheSize)
+	}
 	return &Handler{
-		repo: repo,
+		repo:       repo,
+		svc:        svc,
+		geocoder:   geocoder,
+		storage:    storage,
+		ogRenderer: ogRenderer,
+		ogCache:    ogp.NewCache(ogCacheSize),
+		respCache:  respCache,
 	}
 }
 
@@ -23,6 +77,10 @@ func (h *Handler) SetupRoutes(api *echo.Group) {
 		pingAPI.GET("", h.Ping)
 	}
 
+	// API document
+	api.GET("/openapi.json", h.GetOpenAPI)
+	api.GET("/docs", h.GetAPIDocs)
+
 	// // user API
 	// userAPI := api.Group("/users")
 	// {
@@ -35,11 +93,30 @@ func (h *Handler) SetupRoutes(api *echo.Group) {
 	eventAPI := api.Group("/event")
 	{
 		eventAPI.GET("/all", h.GetEvents)
+		eventAPI.GET("/all.ics", h.GetEventsICS)
+		eventAPI.GET("/nearby", h.GetNearbyEvents)
 		eventAPI.POST("/new", h.CreateEvent)
 		eventAPI.GET("/:id", h.GetEvent)
-		eventAPI.GET("/update/:id", h.UpdateEvent)
+		eventAPI.GET("/:id/ics", h.GetEventICS)
+		eventAPI.GET("/:id/og.png", h.GetEventOGImage)
+		eventAPI.GET("/update/:id", h.GetApprovalConfirmation) // Deprecated
 		eventAPI.POST("/:id/approve", h.ApproveEvent)
 		eventAPI.POST("/:id/reject", h.RejectEvent)
+		eventAPI.POST("/:id/authcode", h.RotateAuthCode)
+		eventAPI.PUT("/:id/occurrences/:date", h.PutOccurrenceOverride)
+		eventAPI.POST("/:id/registrations", h.CreateRegistration)
+		eventAPI.POST("/:id/poster", h.UploadPoster)
+		eventAPI.POST("/:id/speakers/:index/photo", h.UploadSpeakerPhoto)
+	}
+
+	imageAPI := api.Group("/images")
+	{
+		imageAPI.GET("/*", h.GetImage)
+	}
+
+	metadataAPI := api.Group("/metadata")
+	{
+		metadataAPI.GET("", h.GetMetadata)
 	}
 
 	contactAPI := api.Group("/contact")
@@ -47,3 +124,41 @@ func (h *Handler) SetupRoutes(api *echo.Group) {
 		contactAPI.POST("", h.CreateContact)
 	}
 }
+
+// SetupV2Routes は /api/v2 のルートを登録します。
+// イベントは /events 以下のリソースとして扱い、v1と同じ処理はハンドラを共有しています。
+func (h *Handler) SetupV2Routes(api *echo.Group) {
+	api.GET("/ping", h.Ping)
+	api.GET("/openapi.json", h.GetOpenAPIV2)
+	api.GET("/docs", h.GetAPIDocs)
+
+	api.GET("/events.ics", h.GetEventsICS)
+	eventsAPI := api.Group("/events")
+	{
+		eventsAPI.GET("", h.GetEvents)
+		eventsAPI.POST("", h.CreateEventV2)
+		eventsAPI.GET("/nearby", h.GetNearbyEvents)
+		eventsAPI.GET("/:id", h.GetEvent)
+		eventsAPI.PATCH("/:id", h.UpdateEvent)
+		eventsAPI.DELETE("/:id", h.DeleteEvent)
+		eventsAPI.POST("/:id/approval", h.CreateEventApproval)
+		eventsAPI.POST("/:id/authcode", h.RotateAuthCode)
+		eventsAPI.GET("/:id/ics", h.GetEventICS)
+		eventsAPI.GET("/:id/og.png", h.GetEventOGImage)
+		eventsAPI.PUT("/:id/occurrences/:date", h.PutOccurrenceOverride)
+		eventsAPI.POST("/:id/registrations", h.CreateRegistration)
+		eventsAPI.POST("/:id/poster", h.UploadPoster)
+		eventsAPI.POST("/:id/speakers/:index/photo", h.UploadSpeakerPhoto)
+	}
+
+	// 運営者向けAPI。create-admin で発行したトークンが必要です
+	adminAPI := api.Group("/admin", h.requireAdmin)
+	{
+		adminAPI.POST("/events/:id/approval", h.CreateAdminEventApproval)
+		adminAPI.DELETE("/events/:id", h.DeleteAdminEvent)
+	}
+
+	api.GET("/images/*", h.GetImage)
+	api.GET("/metadata", h.GetMetadata)
+	api.POST("/contact", h.CreateContact)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+)
+
+// HealthCheck は /readyz で確かめる依存先です。
+type HealthCheck struct {
+	Name string
+	// Optional の依存先は、失敗しても結果に載せるだけで503にはしません。
+	// 全インスタンスに共通する外部サービスの障害で、全てのインスタンスが振り分け先から外れないようにします。
+	Optional bool
+	// Check は依存先を確かめ、結果の補足(バージョンなど)を返します。
+	Check func(ctx context.Context) (detail string, err error)
+}
+
+// HealthResponse は /healthz と /readyz のレスポンスです。
+type HealthResponse struct {
+	// Status は ok または unavailable です。
+	Status string `json:"status"`
+	// Checks は依存先ごとの結果です。/readyz のみ返します。
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult は依存先1つの結果です。
+type HealthCheckResult struct {
+	// Status は ok または error です。
+	Status     string `json:"status"`
+	Optional   bool   `json:"optional,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// SetupHealthRoutes はコンテナの死活監視(liveness)と振り分けの判定(readiness)のルートを登録します。
+// /ping と異なり、ロードバランサーやオーケストレーターから使うためAPIのパスの外に置きます。
+// checks は /readyz で確かめる依存先です。
+func (h *Handler) SetupHealthRoutes(g *echo.Group, checks ...HealthCheck) {
+	h.healthChecks = checks
+	g.GET("/healthz", h.Healthz)
+	g.GET("/readyz", h.Readyz)
+}
+
+// BeginShutdown はサーバーが終了処理に入ったことを記録します。以降 /readyz は503を返します。
+func (h *Handler) BeginShutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// GET /healthz
+// プロセスが動いているかだけを返します。依存先が落ちても再起動させないよう、DBなどは確かめません。
+func (h *Handler) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// GET /readyz
+// リクエストを受け付けられるかを、依存先ごとの結果とともに返します。
+// 依存先は並行して確かめ、全体で config.ReadinessTimeout までに終わらなければ失敗とします。
+// 終了処理に入った後や、Optional でない依存先が失敗した場合は503を返し、振り分け先から外させます。
+func (h *Handler) Readyz(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), config.ReadinessTimeout())
+	defer cancel()
+
+	// DBのロック待ちなど ctx で中断できない確認があっても、時間内に返す
+	type indexedResult struct {
+		index  int
+		result HealthCheckResult
+	}
+	results := make(chan indexedResult, len(h.healthChecks))
+	for i, check := range h.healthChecks {
+		go func() {
+			results <- indexedResult{index: i, result: runHealthCheck(ctx, check)}
+		}()
+	}
+
+	done := make([]*HealthCheckResult, len(h.healthChecks))
+wait:
+	for range h.healthChecks {
+		select {
+		case r := <-results:
+			done[r.index] = &r.result
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	res := HealthResponse{Status: "ok", Checks: make(map[string]HealthCheckResult, len(h.healthChecks))}
+	for i, check := range h.healthChecks {
+		result := done[i]
+		if result == nil {
+			result = &HealthCheckResult{Status: "error", Optional: check.Optional, Error: ctx.Err().Error(),
+				DurationMs: config.ReadinessTimeout().Milliseconds()}
+		}
+		res.Checks[check.Name] = *result
+		if result.Status != "ok" && !check.Optional {
+			res.Status = "unavailable"
+		}
+	}
+
+	if h.shuttingDown.Load() {
+		res.Status = "unavailable"
+	}
+	if res.Status != "ok" {
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	start := time.Now()
+	detail, err := check.Check(ctx)
+	result := HealthCheckResult{
+		Status:     "ok",
+		Optional:   check.Optional,
+		Detail:     detail,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
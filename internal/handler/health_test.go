@@ -0,0 +1,101 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+)
+
+func TestHealth(t *testing.T) {
+	h := handler.New(nil, nil, nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupHealthRoutes(e.Group(""))
+
+	rec := doRequest(e, http.MethodGet, "/healthz", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodGet, "/readyz", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	// 終了処理に入ったら振り分け先から外させるが、プロセスは生きている
+	h.BeginShutdown()
+	rec = doRequest(e, http.MethodGet, "/readyz", "")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.JSONEq(t, `{"status":"unavailable"}`, rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/healthz", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestReadyz_Checks(t *testing.T) {
+	t.Setenv("READINESS_TIMEOUT", "100ms")
+
+	ok := func(detail string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { return detail, nil }
+	}
+	fail := func(context.Context) (string, error) { return "", assertAnError }
+	hang := func(context.Context) (string, error) {
+		// ctx を見ない確認でも時間内に返す
+		time.Sleep(time.Second)
+		return "", nil
+	}
+
+	tests := []struct {
+		name   string
+		checks []handler.HealthCheck
+		code   int
+		status map[string]string
+	}{
+		{
+			name:   "全て成功",
+			checks: []handler.HealthCheck{{Name: "database", Check: ok("mysql")}, {Name: "migration", Check: ok("version 15")}},
+			code:   http.StatusOK,
+			status: map[string]string{"database": "ok", "migration": "ok"},
+		},
+		{
+			name:   "必須の依存先が失敗",
+			checks: []handler.HealthCheck{{Name: "database", Check: fail}, {Name: "migration", Check: ok("")}},
+			code:   http.StatusServiceUnavailable,
+			status: map[string]string{"database": "error", "migration": "ok"},
+		},
+		{
+			name:   "任意の依存先の失敗は振り分けに影響しない",
+			checks: []handler.HealthCheck{{Name: "database", Check: ok("")}, {Name: "notifier", Optional: true, Check: fail}},
+			code:   http.StatusOK,
+			status: map[string]string{"database": "ok", "notifier": "error"},
+		},
+		{
+			name:   "時間内に終わらない",
+			checks: []handler.HealthCheck{{Name: "database", Check: hang}},
+			code:   http.StatusServiceUnavailable,
+			status: map[string]string{"database": "error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			handler.New(nil, nil, nil, nil, nil, nil).SetupHealthRoutes(e.Group(""), tt.checks...)
+
+			start := time.Now()
+			rec := doRequest(e, http.MethodGet, "/readyz", "")
+			require.Less(t, time.Since(start), time.Second)
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+
+			var res handler.HealthResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			require.Len(t, res.Checks, len(tt.status))
+			for name, status := range tt.status {
+				require.Equal(t, status, res.Checks[name].Status, name)
+				if status == "error" {
+					require.NotEmpty(t, res.Checks[name].Error)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/ics"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+const mimeTextCalendar = "text/calendar; charset=utf-8"
+
+// convertEventToICS はイベントをVEVENTに変換します。
+// シリーズはRRULE/RDATE/EXDATEを持つ1件と、上書きされた回ごとのRECURRENCE-ID付きのVEVENTになります。
+func convertEventToICS(event *repository.Event) ([]ics.Event, error) {
+	start, end, err := eventSpan(event)
+	if err != nil {
+		return nil, err
+	}
+
+	base := ics.Event{
+		UID:     fmt.Sprintf("event-%d@mathday", event.ID),
+		Summary: event.Title,
+		URL:     fmt.Sprintf("%s/events/%d", config.CORE_FRONTEND_URL, event.ID),
+		Start:   start,
+		End:     end,
+	}
+	if event.Description != nil {
+		base.Description = *event.Description
+	}
+	if event.Venue != nil {
+		base.Location = *event.Venue
+	}
+	if !event.IsSeries() {
+		return []ics.Event{base}, nil
+	}
+
+	set, err := recurrenceSet(event)
+	if err != nil {
+		return nil, err
+	}
+	if set.Rule != nil {
+		base.RRule = set.Rule.String()
+	}
+	base.RDates = set.RDates
+	base.ExDates = set.ExDates
+
+	duration := end.Sub(start)
+	var occurrences []ics.Event
+	for _, o := range event.Overrides {
+		recurrenceID, err := parseEventDateTime(o.OccurrenceDate, event.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		if o.IsCancelled {
+			base.ExDates = append(base.ExDates, recurrenceID)
+			continue
+		}
+
+		res := GetEventResponse{
+			Title:     event.Title,
+			StartDate: recurrenceID.Format(dateLayout),
+			StartTime: recurrenceID.Format(timeLayout),
+			EndDate:   recurrenceID.Add(duration).Format(dateLayout),
+			EndTime:   recurrenceID.Add(duration).Format(timeLayout),
+			Venue:     event.Venue,
+		}
+		applyOverride(&res, o)
+
+		occurrence := ics.Event{
+			UID:          base.UID,
+			Summary:      res.Title,
+			Description:  base.Description,
+			URL:          base.URL,
+			RecurrenceID: &recurrenceID,
+		}
+		if res.Venue != nil {
+			occurrence.Location = *res.Venue
+		}
+		if occurrence.Start, err = parseEventDateTime(res.StartDate, res.StartTime); err != nil {
+			return nil, err
+		}
+		if occurrence.End, err = parseEventDateTime(res.EndDate, res.EndTime); err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return append([]ics.Event{base}, occurrences...), nil
+}
+
+// GET /api/v1/event/all.ics
+// 公開されているイベントを購読用のカレンダーとして返します。レスポンスは GetEvents と同じくキャッシュします。
+func (h *Handler) GetEventsICS(c echo.Context) error {
+	return h.serveEvents(c, true, func(now time.Time) (*cachedResponse, error) {
+		events, err := h.repo.GetEvents(c.Request().Context(), repository.GetEventsParams{})
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+
+		cal := ics.Calendar{Name: "Math Day", Stamp: now}
+		for _, event := range events {
+			vevents, err := convertEventToICS(event)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+			}
+			cal.Events = append(cal.Events, vevents...)
+		}
+		return calendarResponse(cal)
+	})
+}
+
+// GET /api/v1/event/:id/ics
+func (h *Handler) GetEventICS(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	codeUUID, err := queryAuthCode(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+
+	return h.serveEvents(c, codeUUID == uuid.Nil, func(now time.Time) (*cachedResponse, error) {
+		event, err := h.repo.GetEvent(c.Request().Context(), id, codeUUID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+		if event == nil {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "event not found")
+		}
+
+		vevents, err := convertEventToICS(event)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+		return calendarResponse(ics.Calendar{Name: event.Title, Events: vevents, Stamp: now})
+	})
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/imageproc"
+	"github.com/ras0q/go-backend-template/internal/pkg/storage"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// multipartOverhead はアップロードされた画像以外にリクエストボディに含まれうるバイト数の見積もりです。
+const multipartOverhead = 1 << 20
+
+type UploadImageResponse struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// setImageURLs はストレージ上のキーから画像のURLを設定します。
+func (h *Handler) setImageURLs(res *GetEventResponse, event *repository.Event) {
+	if h.storage == nil {
+		return
+	}
+	if event.PosterImageKey != nil {
+		u := h.storage.URL(*event.PosterImageKey)
+		res.PosterImageURL = &u
+	}
+	if event.PosterThumbnailKey != nil {
+		u := h.storage.URL(*event.PosterThumbnailKey)
+		res.PosterThumbnailURL = &u
+	}
+	for i, s := range event.Speakers {
+		if i >= len(res.Speakers) {
+			break
+		}
+		if s.PhotoKey != "" {
+			res.Speakers[i].PhotoURL = h.storage.URL(s.PhotoKey)
+		}
+		if s.PhotoThumbnailKey != "" {
+			res.Speakers[i].PhotoThumbnailURL = h.storage.URL(s.PhotoThumbnailKey)
+		}
+	}
+}
+
+// uploadImage はmultipartの file フィールドの画像を検証・縮小してストレージに保存し、
+// 保存した表示用画像とサムネイルのキーを返します。authcode フィールドで主催者であることを確認します。
+func (h *Handler) uploadImage(c echo.Context, keyPrefix string) (int, string, string, error) {
+	if h.storage == nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusServiceUnavailable, "image storage is not configured")
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	maxSize := config.MaxUploadSize()
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+multipartOverhead)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return 0, "", "", echo.NewHTTPError(http.StatusRequestEntityTooLarge, "image is too large").SetInternal(err)
+		}
+		return 0, "", "", echo.NewHTTPError(http.StatusBadRequest, "file is required").SetInternal(err)
+	}
+
+	authCode, err := uuid.Parse(c.FormValue(authCodeKey))
+	if err != nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+	ok, err := h.repo.VerifyAuthCode(c.Request().Context(), id, authCode)
+	if err != nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	if !ok {
+		return 0, "", "", echo.NewHTTPError(http.StatusForbidden, "invalid auth code")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusBadRequest, "failed to read file").SetInternal(err)
+	}
+	defer src.Close()
+
+	img, err := imageproc.Process(src, maxSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, imageproc.ErrTooLarge):
+			return 0, "", "", echo.NewHTTPError(http.StatusRequestEntityTooLarge, "image is too large").SetInternal(err)
+		case errors.Is(err, imageproc.ErrUnsupportedType):
+			return 0, "", "", echo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported image type").SetInternal(err)
+		default:
+			return 0, "", "", echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		}
+	}
+
+	// 画像ごとに新しいキーを使い、CDNやブラウザのキャッシュに古い画像が残らないようにする
+	base := fmt.Sprintf("events/%d/%s-%s", id, keyPrefix, uuid.New())
+	mainKey := base + img.Main.Ext()
+	thumbKey := base + "-thumb" + img.Thumbnail.Ext()
+
+	ctx := c.Request().Context()
+	if err := h.storage.Put(ctx, mainKey, bytes.NewReader(img.Main.Data), img.Main.ContentType); err != nil {
+		return 0, "", "", echo.NewHTTPError(http.StatusInternalServerError, "failed to save image").SetInternal(err)
+	}
+	if err := h.storage.Put(ctx, thumbKey, bytes.NewReader(img.Thumbnail.Data), img.Thumbnail.ContentType); err != nil {
+		h.deleteImages(c, mainKey)
+		return 0, "", "", echo.NewHTTPError(http.StatusInternalServerError, "failed to save image").SetInternal(err)
+	}
+
+	return id, mainKey, thumbKey, nil
+}
+
+// deleteImages は不要になった画像を削除します。失敗してもリクエストは失敗させずログに残します。
+func (h *Handler) deleteImages(c echo.Context, keys ...string) {
+	for _, key := range keys {
+		if err := h.storage.Delete(c.Request().Context(), key); err != nil {
+			c.Logger().Warnf("delete image %s: %v", key, err)
+		}
+	}
+}
+
+// POST /api/v1/event/:id/poster
+// multipart/form-data で file (JPEG/PNG/GIF/WebP) と authcode を受け取り、ポスター画像を設定します。
+func (h *Handler) UploadPoster(c echo.Context) error {
+	id, mainKey, thumbKey, err := h.uploadImage(c, "poster")
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	old, err := h.repo.SetPosterImage(ctx, id, mainKey, thumbKey)
+	if err != nil {
+		h.deleteImages(c, mainKey, thumbKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "event not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	h.deleteImages(c, old...)
+	h.invalidateEvents(c)
+
+	return c.JSON(http.StatusOK, UploadImageResponse{
+		URL:          h.storage.URL(mainKey),
+		ThumbnailURL: h.storage.URL(thumbKey),
+	})
+}
+
+// POST /api/v1/event/:id/speakers/:index/photo
+// index 番目(0始まり)のスピーカーの顔写真を設定します。受け取る値は UploadPoster と同じです。
+func (h *Handler) UploadSpeakerPhoto(c echo.Context) error {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid speaker index").SetInternal(err)
+	}
+
+	id, mainKey, thumbKey, err := h.uploadImage(c, "speaker")
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	old, err := h.repo.SetSpeakerPhoto(ctx, id, index, mainKey, thumbKey)
+	if err != nil {
+		h.deleteImages(c, mainKey, thumbKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "speaker not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	h.deleteImages(c, old...)
+	h.invalidateEvents(c)
+
+	return c.JSON(http.StatusOK, UploadImageResponse{
+		URL:          h.storage.URL(mainKey),
+		ThumbnailURL: h.storage.URL(thumbKey),
+	})
+}
+
+// GET /api/v1/images/*, GET /api/v2/images/*
+// ストレージ上の画像を配信します。キーは画像ごとに一意なので、長期間キャッシュさせます。
+func (h *Handler) GetImage(c echo.Context) error {
+	key := c.Param("*")
+	if h.storage == nil || !storage.ValidKey(key) {
+		return echo.NewHTTPError(http.StatusNotFound, "image not found")
+	}
+
+	rc, contentType, err := h.storage.Get(c.Request().Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "image not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	defer rc.Close()
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return c.Stream(http.StatusOK, contentType, rc)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+	"github.com/ras0q/go-backend-template/internal/pkg/eventtype"
+	"github.com/ras0q/go-backend-template/internal/pkg/prefecture"
+)
+
+type GetMetadataResponse struct {
+	Prefectures   []prefecture.Prefecture `json:"prefectures"`
+	EventTypes    []eventtype.EventType   `json:"eventTypes"`
+	DeliveryModes []deliverymode.Option   `json:"deliveryModes"`
+}
+
+// normalizePrefecture は都道府県の表記をJISコードに揃えます。空の場合は未指定として nil を、解釈できない値はそのまま返します。
+func normalizePrefecture(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	if p, ok := prefecture.Lookup(*s); ok {
+		return &p.Code
+	}
+	return s
+}
+
+// normalizeEventType はイベント種別の表記をIDに揃えます。空の場合は未指定として nil を、解釈できない値はそのまま返します。
+func normalizeEventType(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	if t, ok := eventtype.Lookup(*s); ok {
+		return &t.ID
+	}
+	return s
+}
+
+// prefectureName はJISコードから都道府県名を返します。コードとして解釈できない値はそのまま返します。
+func prefectureName(code *string) *string {
+	if code == nil {
+		return nil
+	}
+	if p, ok := prefecture.Lookup(*code); ok {
+		return &p.Name
+	}
+	return code
+}
+
+// eventTypeName は種別IDから日本語名を返します。
+func eventTypeName(id *string) *string {
+	if id == nil {
+		return nil
+	}
+	if t, ok := eventtype.Lookup(*id); ok {
+		return &t.Name
+	}
+	return nil
+}
+
+// GET /api/v1/metadata
+// フォームの選択肢に使う都道府県・イベント種別・開催形態の一覧を返します。
+func (h *Handler) GetMetadata(c echo.Context) error {
+	res := GetMetadataResponse{
+		Prefectures:   prefecture.All(),
+		EventTypes:    eventtype.All(),
+		DeliveryModes: deliverymode.All(),
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+const (
+	defaultNearbyRadiusKm = 50.0
+	maxNearbyRadiusKm     = 500.0
+)
+
+var postalCodePattern = regexp.MustCompile(`^\d{3}-?\d{4}$`)
+
+// notNilIf は cond が真のときだけ値の指定を必須にします(緯度と経度は両方指定する必要がある)。
+func notNilIf(cond bool) vd.Rule {
+	return vd.By(func(value interface{}) error {
+		if !cond {
+			return nil
+		}
+		return vd.Validate(value, vd.NotNil)
+	})
+}
+
+type NearbyEventResponse struct {
+	GetEventResponse
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// locateVenue は会場の座標を決めます。主催者が座標を指定していればそれを使い、
+// なければジオコーダーで都道府県・住所から近似します。特定できない場合は全てnilを返します。
+func (h *Handler) locateVenue(c echo.Context, req *CreateEventRequest) (*float64, *float64, *string) {
+	if req.Latitude != nil && req.Longitude != nil {
+		precision := string(geo.PrecisionExact)
+		return req.Latitude, req.Longitude, &precision
+	}
+	if h.geocoder == nil {
+		return nil, nil, nil
+	}
+
+	addr := geo.Address{}
+	if req.Prefecture != nil {
+		addr.Prefecture = *req.Prefecture
+	}
+	if req.VenueAddress != nil {
+		addr.Address = *req.VenueAddress
+	}
+	if req.VenuePostalCode != nil {
+		addr.PostalCode = *req.VenuePostalCode
+	}
+
+	p, precision, err := h.geocoder.Geocode(c.Request().Context(), addr)
+	if err != nil {
+		// 位置情報がなくてもイベントは登録できるので、失敗はログに残すだけにする
+		if !errors.Is(err, geo.ErrNotFound) {
+			c.Logger().Warnf("geocode venue: %v", err)
+		}
+		return nil, nil, nil
+	}
+
+	p2 := string(precision)
+	return &p.Lat, &p.Lng, &p2
+}
+
+// GET /api/v1/event/nearby?lat=&lng=&radius=
+// 承認済みのイベントのうち、指定地点から radius km 以内のものを近い順に返します。
+func (h *Handler) GetNearbyEvents(c echo.Context) error {
+	lat, err := strconv.ParseFloat(c.QueryParam("lat"), 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid lat").SetInternal(err)
+	}
+	lng, err := strconv.ParseFloat(c.QueryParam("lng"), 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid lng").SetInternal(err)
+	}
+	center := geo.Point{Lat: lat, Lng: lng}
+	if !center.Valid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "lat/lng out of range")
+	}
+
+	radius := defaultNearbyRadiusKm
+	if r := c.QueryParam("radius"); r != "" {
+		radius, err = strconv.ParseFloat(r, 64)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusKm {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid radius").SetInternal(err)
+		}
+	}
+
+	bounds := geo.BoundsAround(center, radius)
+	events, err := h.repo.GetEvents(c.Request().Context(), repository.GetEventsParams{Bounds: &bounds})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
+	res := []NearbyEventResponse{}
+	for _, event := range events {
+		if event.Latitude == nil || event.Longitude == nil {
+			continue
+		}
+		d := geo.DistanceKm(center, geo.Point{Lat: *event.Latitude, Lng: *event.Longitude})
+		if d > radius {
+			continue
+		}
+		res = append(res, NearbyEventResponse{
+			GetEventResponse: h.newGetEventResponse(event),
+			DistanceKm:       d,
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].DistanceKm < res[j].DistanceKm })
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/ogp"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+const (
+	ogSiteName  = "Math Day"
+	ogCacheSize = 256
+)
+
+var weekdays = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+// ogImageURL はイベントのOGP画像のURLを返します。
+func ogImageURL(id int) string {
+	return fmt.Sprintf("%s/api/v2/events/%d/og.png", config.CORE_BACKEND_URL, id)
+}
+
+// formatEventPeriod は「2025年3月14日(金) 13:00〜17:00」の形式で開催日時を表します。
+func formatEventPeriod(event *repository.Event) string {
+	start, end, err := eventSpan(event)
+	if err != nil {
+		return event.StartDate
+	}
+
+	s := fmt.Sprintf("%d年%d月%d日(%s) %s", start.Year(), start.Month(), start.Day(), weekdays[start.Weekday()], start.Format(clockLayout))
+	if start.Format(dateLayout) == end.Format(dateLayout) {
+		s += "〜" + end.Format(clockLayout)
+	} else {
+		s += fmt.Sprintf(" 〜 %d月%d日(%s) %s", end.Month(), end.Day(), weekdays[end.Weekday()], end.Format(clockLayout))
+	}
+	if event.IsSeries() {
+		s += " ほか"
+	}
+	return s
+}
+
+func newOGCard(event *repository.Event) ogp.Card {
+	card := ogp.Card{
+		SiteName:  ogSiteName,
+		Title:     event.Title,
+		Date:      formatEventPeriod(event),
+		Organizer: event.Organizer,
+	}
+	if name := prefectureName(event.Prefecture); name != nil {
+		card.Prefecture = *name
+	}
+	return card
+}
+
+// GET /api/v1/event/:id/og.png
+// 承認済みイベントのOGP画像を返します。画像は内容のハッシュごとにキャッシュし、イベントが編集されると作り直します。
+func (h *Handler) GetEventOGImage(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+	if h.ogRenderer == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "og image is not available")
+	}
+
+	event, err := h.repo.GetEvent(c.Request().Context(), id, uuid.Nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	if event == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	}
+
+	card := newOGCard(event)
+	hash := card.Hash()
+	etag := strconv.Quote(hash)
+
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "public, max-age=3600")
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	img, ok := h.ogCache.Get(id, hash)
+	if !ok {
+		img, err = h.ogRenderer.Render(card)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to render og image").SetInternal(err)
+		}
+		h.ogCache.Put(id, hash, img)
+	}
+
+	http.ServeContent(c.Response(), c.Request(), "og.png", time.Time{}, bytes.NewReader(img))
+	return nil
+}
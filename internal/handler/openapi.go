@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+)
+
+// apiOperation はOpenAPIドキュメントに載せるエンドポイントです。
+// Request と Response にはハンドラで使っている型のゼロ値を指定し、スキーマはそこから生成します。
+type apiOperation struct {
+	Method  string
+	Path    string // echoのルート形式(/event/:id)
+	Summary string
+	Tag     string
+	Query   []apiParam
+	// Request はJSONのリクエストボディの型です。Form を指定した場合は multipart/form-data になります。
+	Request interface{}
+	Form    []apiParam
+	Status  int
+	// Deprecated は廃止予定のエンドポイントであることを表します。
+	Deprecated bool
+	// Admin は運営者のトークン(Authorization: Bearer)が必要なエンドポイントであることを表します。
+	Admin bool
+	// Response はJSONのレスポンスボディの型です。ContentType を指定した場合はJSON以外の形式になります。
+	Response    interface{}
+	ContentType string
+}
+
+type apiParam struct {
+	Name        string
+	Description string
+	Schema      *openapi3.Schema
+	Required    bool
+	Deprecated  bool
+}
+
+var authCodeParam = apiParam{Name: authCodeKey, Description: "イベント作成時に発行された認証コード", Schema: openapi3.NewUUIDSchema()}
+
+var requiredAuthCodeParam = apiParam{Name: authCodeParam.Name, Description: authCodeParam.Description, Schema: authCodeParam.Schema, Required: true}
+
+var imageUploadForm = []apiParam{
+	{Name: "file", Description: "JPEG/PNG/GIF/WebP", Schema: openapi3.NewStringSchema().WithFormat("binary"), Required: true},
+	{Name: "authcode", Description: authCodeParam.Description, Schema: openapi3.NewUUIDSchema(), Required: true},
+}
+
+// apiOperations は SetupRoutes で登録している全てのエンドポイントです。
+// ルートを追加・変更したときはここも更新してください(TestOpenAPI_CoversRoutes で検査しています)。
+var apiOperations = []apiOperation{
+	{Method: http.MethodGet, Path: "/ping", Summary: "死活確認", Tag: "system", Status: http.StatusOK, Response: "", ContentType: echo.MIMETextPlain},
+	{Method: http.MethodGet, Path: "/openapi.json", Summary: "このAPIのOpenAPIドキュメント", Tag: "system", Status: http.StatusOK, Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/docs", Summary: "Swagger UI", Tag: "system", Status: http.StatusOK, Response: "", ContentType: echo.MIMETextHTML},
+
+	{
+		Method: http.MethodGet, Path: "/event/all", Summary: "承認済みイベントの一覧", Tag: "event",
+		Query: []apiParam{
+			{Name: "from", Description: "期間の開始日。指定するとシリーズを各回に展開します", Schema: openapi3.NewDateTimeSchema().WithFormat("date")},
+			{Name: "to", Description: "期間の終了日", Schema: openapi3.NewDateTimeSchema().WithFormat("date")},
+		},
+		Status: http.StatusOK, Response: []GetEventResponse{},
+	},
+	{Method: http.MethodGet, Path: "/event/all.ics", Summary: "承認済みイベントのiCalendarフィード", Tag: "event", Status: http.StatusOK, Response: "", ContentType: mimeTextCalendar},
+	{
+		Method: http.MethodGet, Path: "/event/nearby", Summary: "指定地点の近くのイベント", Tag: "event",
+		Query: []apiParam{
+			{Name: "lat", Schema: openapi3.NewFloat64Schema(), Required: true},
+			{Name: "lng", Schema: openapi3.NewFloat64Schema(), Required: true},
+			{Name: "radius", Description: "半径(km)。既定値は50、最大500", Schema: openapi3.NewFloat64Schema()},
+		},
+		Status: http.StatusOK, Response: []NearbyEventResponse{},
+	},
+	{Method: http.MethodPost, Path: "/event/new", Summary: "イベントの申請", Tag: "event", Request: CreateEventRequest{}, Status: http.StatusOK, Response: CreateEventResponse{}},
+	{
+		Method: http.MethodGet, Path: "/event/:id", Summary: "イベントの詳細", Tag: "event",
+		Query: []apiParam{
+			authCodeParam,
+			{Name: "attendee", Description: "参加登録時にメールで送る参加用リンクのトークン", Schema: openapi3.NewUUIDSchema()},
+		},
+		Status: http.StatusOK, Response: GetEventResponse{},
+	},
+	{Method: http.MethodGet, Path: "/event/:id/ics", Summary: "イベントのiCalendarファイル", Tag: "event", Query: []apiParam{authCodeParam}, Status: http.StatusOK, Response: "", ContentType: mimeTextCalendar},
+	{Method: http.MethodGet, Path: "/event/:id/og.png", Summary: "イベントのOGP画像", Tag: "event", Status: http.StatusOK, Response: "", ContentType: "image/png"},
+	{
+		Method: http.MethodGet, Path: "/event/update/:id", Summary: "承認内容の確認(承認は POST /event/{id}/approve)", Tag: "event", Deprecated: true,
+		Query:  []apiParam{authCodeParam, {Name: legacyAuthCodeKey, Description: "authcode の旧名", Schema: openapi3.NewUUIDSchema(), Deprecated: true}},
+		Status: http.StatusOK, Response: ApprovalConfirmationResponse{},
+	},
+	{Method: http.MethodPost, Path: "/event/:id/approve", Summary: "イベントの承認", Tag: "event", Request: ApproveEventRequest{}, Status: http.StatusOK, Response: UpdateEventResponse{}},
+	{Method: http.MethodPost, Path: "/event/:id/reject", Summary: "イベントの却下", Tag: "event", Request: RejectEventRequest{}, Status: http.StatusOK, Response: UpdateEventResponse{}},
+	{Method: http.MethodPost, Path: "/event/:id/authcode", Summary: "認証リンクの再発行", Tag: "event", Request: RotateAuthCodeRequest{}, Status: http.StatusAccepted, Response: UpdateEventResponse{}},
+	{Method: http.MethodPut, Path: "/event/:id/occurrences/:date", Summary: "シリーズの1回分の変更・中止", Tag: "event", Request: PutOccurrenceOverrideRequest{}, Status: http.StatusOK, Response: UpdateEventResponse{}},
+	{Method: http.MethodPost, Path: "/event/:id/registrations", Summary: "参加登録", Tag: "event", Request: CreateRegistrationRequest{}, Status: http.StatusAccepted, Response: UpdateEventResponse{}},
+	{
+		Method: http.MethodPost, Path: "/event/:id/poster", Summary: "ポスター画像のアップロード", Tag: "image",
+		Form:   imageUploadForm,
+		Status: http.StatusOK, Response: UploadImageResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/event/:id/speakers/:index/photo", Summary: "スピーカーの顔写真のアップロード", Tag: "image",
+		Form:   imageUploadForm,
+		Status: http.StatusOK, Response: UploadImageResponse{},
+	},
+	{Method: http.MethodGet, Path: "/images/*", Summary: "アップロードされた画像", Tag: "image", Status: http.StatusOK, Response: "", ContentType: "image/*"},
+
+	{Method: http.MethodGet, Path: "/metadata", Summary: "都道府県・イベント種別・開催形式の一覧", Tag: "metadata", Status: http.StatusOK, Response: GetMetadataResponse{}},
+	{Method: http.MethodPost, Path: "/contact", Summary: "お問い合わせ", Tag: "contact", Request: CreateContactRequest{}, Status: http.StatusOK, Response: ""},
+}
+
+// apiOperationsV2 は SetupV2Routes で登録している全てのエンドポイントです。
+var apiOperationsV2 = []apiOperation{
+	{Method: http.MethodGet, Path: "/ping", Summary: "死活確認", Tag: "system", Status: http.StatusOK, Response: "", ContentType: echo.MIMETextPlain},
+	{Method: http.MethodGet, Path: "/openapi.json", Summary: "このAPIのOpenAPIドキュメント", Tag: "system", Status: http.StatusOK, Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/docs", Summary: "Swagger UI", Tag: "system", Status: http.StatusOK, Response: "", ContentType: echo.MIMETextHTML},
+
+	{
+		Method: http.MethodGet, Path: "/events", Summary: "承認済みイベントの一覧", Tag: "event",
+		Query: []apiParam{
+			{Name: "from", Description: "期間の開始日。指定するとシリーズを各回に展開します", Schema: openapi3.NewDateTimeSchema().WithFormat("date")},
+			{Name: "to", Description: "期間の終了日", Schema: openapi3.NewDateTimeSchema().WithFormat("date")},
+		},
+		Status: http.StatusOK, Response: []GetEventResponse{},
+	},
+	{Method: http.MethodPost, Path: "/events", Summary: "イベントの申請", Tag: "event", Request: CreateEventRequest{}, Status: http.StatusCreated, Response: CreateEventResponse{}},
+	{Method: http.MethodGet, Path: "/events.ics", Summary: "承認済みイベントのiCalendarフィード", Tag: "event", Status: http.StatusOK, Response: "", ContentType: mimeTextCalendar},
+	{
+		Method: http.MethodGet, Path: "/events/nearby", Summary: "指定地点の近くのイベント", Tag: "event",
+		Query: []apiParam{
+			{Name: "lat", Schema: openapi3.NewFloat64Schema(), Required: true},
+			{Name: "lng", Schema: openapi3.NewFloat64Schema(), Required: true},
+			{Name: "radius", Description: "半径(km)。既定値は50、最大500", Schema: openapi3.NewFloat64Schema()},
+		},
+		Status: http.StatusOK, Response: []NearbyEventResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/events/:id", Summary: "イベントの詳細", Tag: "event",
+		Query: []apiParam{
+			authCodeParam,
+			{Name: "attendee", Description: "参加登録時にメールで送る参加用リンクのトークン", Schema: openapi3.NewUUIDSchema()},
+		},
+		Status: http.StatusOK, Response: GetEventResponse{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/events/:id", Summary: "イベントの変更(JSON Merge Patch)", Tag: "event",
+		Query:   []apiParam{requiredAuthCodeParam},
+		Request: CreateEventRequest{}, Status: http.StatusOK, Response: GetEventResponse{},
+	},
+	{Method: http.MethodDelete, Path: "/events/:id", Summary: "イベントの取り下げ・却下", Tag: "event", Query: []apiParam{requiredAuthCodeParam}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/events/:id/approval", Summary: "イベントの承認", Tag: "event", Request: ApproveEventRequest{}, Status: http.StatusCreated, Response: GetEventResponse{}},
+	{Method: http.MethodPost, Path: "/events/:id/authcode", Summary: "認証リンクの再発行", Tag: "event", Request: RotateAuthCodeRequest{}, Status: http.StatusAccepted, Response: UpdateEventResponse{}},
+	{Method: http.MethodGet, Path: "/events/:id/ics", Summary: "イベントのiCalendarファイル", Tag: "event", Query: []apiParam{authCodeParam}, Status: http.StatusOK, Response: "", ContentType: mimeTextCalendar},
+	{Method: http.MethodGet, Path: "/events/:id/og.png", Summary: "イベントのOGP画像", Tag: "event", Status: http.StatusOK, Response: "", ContentType: "image/png"},
+	{Method: http.MethodPut, Path: "/events/:id/occurrences/:date", Summary: "シリーズの1回分の変更・中止", Tag: "event", Request: PutOccurrenceOverrideRequest{}, Status: http.StatusOK, Response: UpdateEventResponse{}},
+	{Method: http.MethodPost, Path: "/events/:id/registrations", Summary: "参加登録", Tag: "event", Request: CreateRegistrationRequest{}, Status: http.StatusAccepted, Response: UpdateEventResponse{}},
+	{
+		Method: http.MethodPost, Path: "/events/:id/poster", Summary: "ポスター画像のアップロード", Tag: "image",
+		Form:   imageUploadForm,
+		Status: http.StatusOK, Response: UploadImageResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/events/:id/speakers/:index/photo", Summary: "スピーカーの顔写真のアップロード", Tag: "image",
+		Form:   imageUploadForm,
+		Status: http.StatusOK, Response: UploadImageResponse{},
+	},
+	{Method: http.MethodGet, Path: "/images/*", Summary: "アップロードされた画像", Tag: "image", Status: http.StatusOK, Response: "", ContentType: "image/*"},
+
+	{Method: http.MethodPost, Path: "/admin/events/:id/approval", Summary: "運営者によるイベントの承認", Tag: "admin", Admin: true, Status: http.StatusCreated, Response: GetEventResponse{}},
+	{Method: http.MethodDelete, Path: "/admin/events/:id", Summary: "運営者によるイベントの却下", Tag: "admin", Admin: true, Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/metadata", Summary: "都道府県・イベント種別・開催形式の一覧", Tag: "metadata", Status: http.StatusOK, Response: GetMetadataResponse{}},
+	{Method: http.MethodPost, Path: "/contact", Summary: "お問い合わせ", Tag: "contact", Request: CreateContactRequest{}, Status: http.StatusOK, Response: ""},
+}
+
+// pathParamSchemas はパスパラメータの型です。ここにないものは文字列として扱います。
+var pathParamSchemas = map[string]func() *openapi3.Schema{
+	"id":    openapi3.NewIntegerSchema,
+	"index": openapi3.NewIntegerSchema,
+	"date":  func() *openapi3.Schema { return openapi3.NewStringSchema().WithFormat("date") },
+}
+
+// adminSecurityScheme は運営者のトークンを表すセキュリティスキームの名前です。
+const adminSecurityScheme = "adminToken"
+
+var echoPathParam = regexp.MustCompile(`:(\w+)|\*`)
+
+// openAPIPath はechoのルートをOpenAPIのパス(/event/{id})に変換します。
+func openAPIPath(path string) string {
+	return echoPathParam.ReplaceAllStringFunc(path, func(s string) string {
+		if s == "*" {
+			return "{key}"
+		}
+		return "{" + s[1:] + "}"
+	})
+}
+
+// OpenAPI は apiOperations から /api/v1 の OpenAPI 3 のドキュメントを生成します。
+func OpenAPI() (*openapi3.T, error) {
+	return newOpenAPI(&openapi3.Info{
+		Title:       "Math Day API",
+		Version:     "1.0.0",
+		Description: "v1は廃止予定です。/api/v2 に移行してください。",
+	}, "/api/v1", apiOperations)
+}
+
+// OpenAPIV2 は apiOperationsV2 から /api/v2 の OpenAPI 3 のドキュメントを生成します。
+func OpenAPIV2() (*openapi3.T, error) {
+	return newOpenAPI(&openapi3.Info{
+		Title:   "Math Day API",
+		Version: "2.0.0",
+	}, "/api/v2", apiOperationsV2)
+}
+
+func newOpenAPI(info *openapi3.Info, server string, operations []apiOperation) (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Servers: openapi3.Servers{{URL: server}},
+		Paths:   openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"Error": openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+					WithProperty("message", openapi3.NewStringSchema())),
+			},
+		},
+	}
+
+	schemaRef := func(v interface{}) (*openapi3.SchemaRef, error) {
+		return openapi3gen.NewSchemaRefForValue(v, doc.Components.Schemas,
+			openapi3gen.CreateComponentSchemas(openapi3gen.ExportComponentSchemasOptions{
+				ExportComponentSchemas: true,
+				ExportTopLevelSchema:   true,
+			}),
+			openapi3gen.CreateTypeNameGenerator(schemaName),
+		)
+	}
+
+	for _, o := range operations {
+		op := openapi3.NewOperation()
+		op.Summary = o.Summary
+		op.Tags = []string{o.Tag}
+		op.OperationID = operationID(o)
+		op.Deprecated = o.Deprecated
+		if o.Admin {
+			op.Security = &openapi3.SecurityRequirements{openapi3.NewSecurityRequirement().Authenticate(adminSecurityScheme)}
+			doc.Components.SecuritySchemes = openapi3.SecuritySchemes{
+				adminSecurityScheme: &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("http").
+					WithScheme("bearer").
+					WithDescription("create-admin コマンドで発行した運営者のトークン")},
+			}
+		}
+
+		for _, m := range echoPathParam.FindAllStringSubmatch(o.Path, -1) {
+			name, schema := "key", openapi3.NewStringSchema()
+			if m[1] != "" {
+				name = m[1]
+				if f, ok := pathParamSchemas[name]; ok {
+					schema = f()
+				}
+			}
+			op.AddParameter(openapi3.NewPathParameter(name).WithSchema(schema))
+		}
+		for _, p := range o.Query {
+			param := openapi3.NewQueryParameter(p.Name).
+				WithDescription(p.Description).
+				WithRequired(p.Required).
+				WithSchema(p.Schema)
+			param.Deprecated = p.Deprecated
+			op.AddParameter(param)
+		}
+
+		switch {
+		case o.Form != nil:
+			form := openapi3.NewObjectSchema()
+			for _, p := range o.Form {
+				form.WithProperty(p.Name, p.Schema)
+				if p.Required {
+					form.Required = append(form.Required, p.Name)
+				}
+			}
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithSchema(form, []string{echo.MIMEMultipartForm})}
+		case o.Request != nil:
+			ref, err := schemaRef(o.Request)
+			if err != nil {
+				return nil, fmt.Errorf("generate schema for %s %s: %w", o.Method, o.Path, err)
+			}
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithJSONSchemaRef(ref)}
+		}
+
+		res := openapi3.NewResponse().WithDescription(http.StatusText(o.Status))
+		switch {
+		case o.ContentType != "":
+			res.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{o.ContentType}))
+		case o.Response == nil:
+			// 204 No Content など本文のないレスポンス
+		default:
+			ref, err := schemaRef(o.Response)
+			if err != nil {
+				return nil, fmt.Errorf("generate schema for %s %s: %w", o.Method, o.Path, err)
+			}
+			res.WithJSONSchemaRef(ref)
+		}
+		op.Responses = openapi3.NewResponsesWithCapacity(2)
+		op.AddResponse(o.Status, res)
+		op.Responses.Set("default", &openapi3.ResponseRef{Value: openapi3.NewResponse().
+			WithDescription("Error").
+			WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/Error", nil))})
+
+		doc.AddOperation(openAPIPath(o.Path), o.Method, op)
+	}
+	allowNullArrays(doc.Components.Schemas)
+
+	// 生成した $ref を解決しておき、検証やリクエストの照合に使えるようにする
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return nil, fmt.Errorf("resolve refs: %w", err)
+	}
+
+	return doc, nil
+}
+
+// schemaNames は型名だけでは意味が分かりにくいスキーマの名前です。
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeOf(deliverymode.Option{}): "DeliveryModeOption",
+}
+
+func schemaName(t reflect.Type) string {
+	if name, ok := schemaNames[t]; ok {
+		return name
+	}
+	return t.Name()
+}
+
+// allowNullArrays は配列のプロパティを nullable にします。Goのnilのスライスは null としてエンコードされるためです。
+func allowNullArrays(schemas openapi3.Schemas) {
+	for _, ref := range schemas {
+		if ref.Value == nil {
+			continue
+		}
+		for _, prop := range ref.Value.Properties {
+			if prop.Value != nil && prop.Value.Type.Is(openapi3.TypeArray) {
+				prop.Value.Nullable = true
+			}
+		}
+	}
+}
+
+// operationID は "GET /event/:id/ics" から "getEventIdIcs" のようなIDを作ります。
+func operationID(o apiOperation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(o.Method))
+	for _, word := range regexp.MustCompile(`[A-Za-z0-9]+`).FindAllString(o.Path, -1) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if strings.HasSuffix(o.Path, "*") {
+		b.WriteString("Key")
+	}
+	return b.String()
+}
+
+func marshalOpenAPI(newDoc func() (*openapi3.T, error)) func() ([]byte, error) {
+	return sync.OnceValues(func() ([]byte, error) {
+		doc, err := newDoc()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	})
+}
+
+var (
+	openAPIJSON   = marshalOpenAPI(OpenAPI)
+	openAPIV2JSON = marshalOpenAPI(OpenAPIV2)
+)
+
+// GET /api/v1/openapi.json
+func (h *Handler) GetOpenAPI(c echo.Context) error {
+	b, err := openAPIJSON()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, b)
+}
+
+// GET /api/v2/openapi.json
+func (h *Handler) GetOpenAPIV2(c echo.Context) error {
+	b, err := openAPIV2JSON()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, b)
+}
+
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUIHTML = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>Math Day API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// GET /api/v1/docs, GET /api/v2/docs
+// 同じ階層の openapi.json を表示するSwagger UIです。
+func (h *Handler) GetAPIDocs(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUIHTML)
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+)
+
+func TestOpenAPI_Valid(t *testing.T) {
+	doc, err := handler.OpenAPI()
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	// ハンドラの型から生成されていることを確認する
+	event := doc.Components.Schemas["GetEventResponse"]
+	require.NotNil(t, event)
+	require.Contains(t, event.Value.Properties, "descriptionHtml")
+	require.Contains(t, doc.Components.Schemas["CreateEventRequest"].Value.Properties, "recurrenceRule")
+}
+
+// SetupRoutes・SetupV2Routes で登録したルートとドキュメントのエンドポイントが過不足なく一致することを確認します。
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	tests := []struct {
+		prefix string
+		newDoc func() (*openapi3.T, error)
+		setup  func(h *handler.Handler, g *echo.Group)
+		want   string
+	}{
+		{"/api/v1", handler.OpenAPI, (*handler.Handler).SetupRoutes, http.MethodGet + " /event/{id}"},
+		{"/api/v2", handler.OpenAPIV2, (*handler.Handler).SetupV2Routes, http.MethodPatch + " /events/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			doc, err := tt.newDoc()
+			require.NoError(t, err)
+			require.NoError(t, doc.Validate(context.Background()))
+
+			e := echo.New()
+			tt.setup(handler.New(nil, nil, nil, nil, nil, nil), e.Group(tt.prefix))
+
+			param := regexp.MustCompile(`:(\w+)`)
+			routes := map[string]bool{}
+			for _, r := range e.Routes() {
+				if !strings.HasPrefix(r.Path, tt.prefix+"/") || r.Method == echo.RouteNotFound {
+					continue
+				}
+				path := param.ReplaceAllString(strings.TrimPrefix(r.Path, tt.prefix), "{$1}")
+				path = strings.Replace(path, "*", "{key}", 1)
+				routes[r.Method+" "+path] = true
+			}
+
+			documented := map[string]bool{}
+			for path, item := range doc.Paths.Map() {
+				for method := range item.Operations() {
+					documented[method+" "+path] = true
+				}
+			}
+
+			require.Equal(t, routes, documented)
+			require.True(t, documented[tt.want])
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+// jst はイベントの日時(DATE/TIMEカラム)が表すタイムゾーンです。
+var jst = time.FixedZone("JST", 9*60*60)
+
+type CreateRegistrationRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// floatingNow は現在時刻を、イベントの日時と比較できるタイムゾーンなしの日本時間で返します。
+func floatingNow(now time.Time) time.Time {
+	n := now.In(jst)
+	return time.Date(n.Year(), n.Month(), n.Day(), n.Hour(), n.Minute(), n.Second(), 0, time.UTC)
+}
+
+// setLectureURLVisibility はオンライン講義URLを、開始 config.OnlineURLRevealWindow 前から終了までの間だけ公開します。
+// それ以外の時間はURLを伏せ、hasOnlineLectureUrl と公開開始時刻のみを返します。
+// 期間指定で展開していないシリーズは、直近の回を基準にします。
+// 公開・非公開が次に切り替わる時刻は、キャッシュの期限のために res に記録します。
+func setLectureURLVisibility(res *GetEventResponse, event *repository.Event, now time.Time) {
+	res.OnlineLectureURL = nil
+	res.OnlineLectureURLVisibleFrom = nil
+	res.lectureURLChangesAt = time.Time{}
+	res.HasOnlineLectureURL = event.OnlineLectureURL != nil && *event.OnlineLectureURL != ""
+	if !res.HasOnlineLectureURL {
+		return
+	}
+
+	start, err := parseEventDateTime(res.StartDate, res.StartTime)
+	if err != nil {
+		return
+	}
+	end, err := parseEventDateTime(res.EndDate, res.EndTime)
+	if err != nil {
+		return
+	}
+
+	current := floatingNow(now)
+	window := config.OnlineURLRevealWindow()
+	if event.IsSeries() && res.OccurrenceDate == nil {
+		set, err := recurrenceSet(event)
+		if err != nil {
+			return
+		}
+		duration := end.Sub(start)
+		next := set.Between(current.Add(-duration), current.AddDate(2, 0, 0))
+		if len(next) == 0 {
+			return
+		}
+		start, end = next[0], next[0].Add(duration)
+	}
+
+	visibleFrom := start.Add(-window)
+	s := fromFloating(visibleFrom).Format(time.RFC3339)
+	res.OnlineLectureURLVisibleFrom = &s
+
+	switch {
+	case current.Before(visibleFrom):
+		res.lectureURLChangesAt = fromFloating(visibleFrom)
+	case !current.After(end):
+		res.OnlineLectureURL = event.OnlineLectureURL
+		// 終了時刻ちょうどまでは公開する
+		res.lectureURLChangesAt = fromFloating(end).Add(time.Second)
+	}
+}
+
+// fromFloating は floatingNow と同じタイムゾーンなしの日本時間を、実際の時刻に戻します。
+func fromFloating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, jst)
+}
+
+// nextLectureURLChange は responses のうち、オンライン講義URLの公開・非公開が最も早く切り替わる時刻を返します。
+// どれも切り替わらない場合はゼロ値を返します。
+func nextLectureURLChange(responses ...GetEventResponse) time.Time {
+	var next time.Time
+	for _, res := range responses {
+		if t := res.lectureURLChangesAt; !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// canViewLectureURL は主催者(有効な認証コード)または参加登録者(attendeeトークン)かを判定します。
+func (h *Handler) canViewLectureURL(c echo.Context, id int, authCode uuid.UUID) (bool, error) {
+	ctx := c.Request().Context()
+	if authCode != uuid.Nil {
+		ok, err := h.repo.VerifyAuthCode(ctx, id, authCode)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	attendee := c.QueryParam("attendee")
+	if attendee == "" {
+		return false, nil
+	}
+	token, err := uuid.Parse(attendee)
+	if err != nil {
+		return false, nil
+	}
+	return h.repo.VerifyRegistration(ctx, id, token)
+}
+
+// CreateRegistration は承認済みイベントへの参加登録を行い、オンライン講義URLの閲覧に使う参加用リンクをメールで送ります。
+// メールアドレスの持ち主か確かめられないため、トークンはレスポンスには含めません。
+// POST /api/v1/event/:id/registrations
+func (h *Handler) CreateRegistration(c echo.Context) error {
+	var req CreateRegistrationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	if err := vd.ValidateStruct(
+		&req,
+		vd.Field(&req.Name, vd.Required),
+		vd.Field(&req.Email, vd.Required, is.Email),
+	); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+
+	err = h.svc.RegisterForEvent(c.Request().Context(), repository.CreateRegistrationParams{
+		EventID: id,
+		Name:    req.Name,
+		Email:   req.Email,
+	})
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	case errors.Is(err, service.ErrNotification):
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to send mail").SetInternal(err)
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to register").SetInternal(err)
+	}
+
+	res := UpdateEventResponse{
+		Message: "A link to the event has been sent to the email address",
+	}
+	return c.JSON(http.StatusAccepted, res)
+}
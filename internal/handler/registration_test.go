@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+type sentMail struct {
+	to   string
+	body string
+}
+
+// queryParamIn は本文にあるリンクから、prefix に続くクエリの値を取り出します。
+func queryParamIn(t *testing.T, text, prefix string) string {
+	t.Helper()
+	require.Contains(t, text, prefix)
+	return strings.Fields(text[strings.Index(text, prefix)+len(prefix):])[0]
+}
+
+func TestCreateRegistration(t *testing.T) {
+	var messages []string
+	var mails []sentMail
+	notifier := service.NotifierFunc(func(ctx context.Context, msg string) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	mailer := service.MailerFunc(func(to, subject, body string) error {
+		mails = append(mails, sentMail{to: to, body: body})
+		return nil
+	})
+	repo := repository.NewMemory()
+	h := handler.New(repo, service.New(repo, notifier, mailer), nil, nil, nil, nil)
+	e := echo.New()
+	h.SetupRoutes(e.Group("/api/v1"))
+
+	// 講義URLがまだ公開されない、先の日付のオンラインイベント
+	rec := doRequest(e, http.MethodPost, "/api/v1/event/new", `{
+        "title":"Online Lecture",
+        "organizer":"Test Org",
+        "startDate":"2099-01-01",
+        "startTime":"09:00:00",
+        "endDate":"2099-01-01",
+        "endTime":"10:00:00",
+        "email":"organizer@example.com",
+        "deliveryMode":"online",
+        "onlineLectureUrl":"https://zoom.example.com/j/1"
+    }`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var created handler.CreateEventResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	path := "/api/v1/event/" + created.ID
+
+	register := func(name, email string) {
+		t.Helper()
+		rec := doRequest(e, http.MethodPost, path+"/registrations", fmt.Sprintf(`{"name":%q,"email":%q}`, name, email))
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		require.NotContains(t, rec.Body.String(), "token")
+	}
+	lectureURL := func(query string) *string {
+		t.Helper()
+		rec := doRequest(e, http.MethodGet, path+query, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var event handler.GetEventResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &event))
+		require.True(t, event.HasOnlineLectureURL)
+		return event.OnlineLectureURL
+	}
+
+	t.Run("未承認のイベントには登録できない", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPost, path+"/registrations", `{"name":"山田","email":"yamada@example.com"}`)
+		require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+		require.Empty(t, mails)
+	})
+
+	authCode := queryParamIn(t, messages[len(messages)-1], fmt.Sprintf("/events/update/%s?authcode=", created.ID))
+	rec = doRequest(e, http.MethodPost, path+"/approve", fmt.Sprintf(`{"authcode":%q}`, authCode))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	attendeeLink := fmt.Sprintf("/events/%s?attendee=", created.ID)
+	var token string
+	t.Run("トークンは登録したアドレスへのメールでだけ渡す", func(t *testing.T) {
+		register("山田", "yamada@example.com")
+		require.Len(t, mails, 1)
+		require.Equal(t, "yamada@example.com", mails[0].to)
+		token = queryParamIn(t, mails[0].body, attendeeLink)
+
+		require.Nil(t, lectureURL(""))
+		require.Nil(t, lectureURL("?attendee=00000000-0000-0000-0000-000000000001"))
+		require.Equal(t, "https://zoom.example.com/j/1", *lectureURL("?attendee=" + token))
+		require.Equal(t, "https://zoom.example.com/j/1", *lectureURL("?authcode=" + authCode))
+	})
+
+	t.Run("他人が同じアドレスで申し込んでも以前のリンクは使える", func(t *testing.T) {
+		register("なりすまし", "Yamada@Example.com")
+		require.Len(t, mails, 2)
+		require.Equal(t, "Yamada@Example.com", mails[1].to)
+		resent := queryParamIn(t, mails[1].body, attendeeLink)
+		require.NotEqual(t, token, resent)
+
+		require.Equal(t, "https://zoom.example.com/j/1", *lectureURL("?attendee=" + token))
+		require.Equal(t, "https://zoom.example.com/j/1", *lectureURL("?attendee=" + resent))
+	})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+)
+
+const clockLayout = "15:04"
+
+// normalizeClock は "09:00" や "09:00:00" を "09:00" に揃えます。解釈できない場合はそのまま返します。
+func normalizeClock(s string) string {
+	for _, layout := range []string{clockLayout, timeLayout} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(clockLayout)
+		}
+	}
+	return s
+}
+
+// normalizeSchedules は旧形式(time/speaker)の項目を新形式に変換し、日付のない項目をイベントの開始日に割り当てます。
+func normalizeSchedules(schedules []Schedule, eventStartDate string) []Schedule {
+	result := make([]Schedule, len(schedules))
+	for i, s := range schedules {
+		if s.StartTime == "" {
+			s.StartTime = s.Time
+		}
+		if len(s.Speakers) == 0 && s.Speaker != "" {
+			s.Speakers = []string{s.Speaker}
+		}
+		if s.Date == "" {
+			s.Date = eventStartDate
+		}
+		s.StartTime = normalizeClock(s.StartTime)
+		if s.EndTime != "" {
+			s.EndTime = normalizeClock(s.EndTime)
+		}
+		s.Time, s.Speaker = "", ""
+		result[i] = s
+	}
+	return result
+}
+
+// groupScheduleByDay はスケジュールを日付・開始時刻順に並べ、日付ごとにまとめます。
+func groupScheduleByDay(schedules []Schedule) []ScheduleDay {
+	sorted := make([]Schedule, len(schedules))
+	copy(sorted, schedules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date < sorted[j].Date
+		}
+		return sorted[i].StartTime < sorted[j].StartTime
+	})
+
+	days := []ScheduleDay{}
+	for _, s := range sorted {
+		if len(days) == 0 || days[len(days)-1].Date != s.Date {
+			days = append(days, ScheduleDay{Date: s.Date})
+		}
+		days[len(days)-1].Items = append(days[len(days)-1].Items, s)
+	}
+	return days
+}
+
+// shiftScheduleDates はシリーズの各回に合わせてスケジュールの日付を days 日ずらします。
+func shiftScheduleDates(schedules []Schedule, days int) []Schedule {
+	if days == 0 {
+		return schedules
+	}
+	result := make([]Schedule, len(schedules))
+	for i, s := range schedules {
+		if d, err := time.Parse(dateLayout, s.Date); err == nil {
+			s.Date = d.AddDate(0, 0, days).Format(dateLayout)
+		}
+		result[i] = s
+	}
+	return result
+}
+
+// validateEventPeriod はイベントの終了が開始より後であること、
+// およびスケジュールの各枠がイベントの期間内に収まっていることを検証します。
+func validateEventPeriod(req *CreateEventRequest) error {
+	start, err := parseEventDateTime(req.StartDate, req.StartTime)
+	if err != nil {
+		return vd.Errors{"startTime": errors.New("must be a valid date and time")}
+	}
+	end, err := parseEventDateTime(req.EndDate, req.EndTime)
+	if err != nil {
+		return vd.Errors{"endTime": errors.New("must be a valid date and time")}
+	}
+	if end.Before(start) {
+		return vd.Errors{"endDate": errors.New("must not be before the start")}
+	}
+
+	speakerNames := make(map[string]bool, len(req.Speakers))
+	for _, s := range req.Speakers {
+		speakerNames[s.Name] = true
+	}
+
+	multiDay := req.StartDate != req.EndDate
+	itemErrs := vd.Errors{}
+	for i, s := range req.Schedule {
+		key := strconv.Itoa(i)
+		for _, name := range s.Speakers {
+			if !speakerNames[name] {
+				itemErrs[key] = errors.New("speakers must refer to names in speakers")
+				break
+			}
+		}
+		if itemErrs[key] != nil {
+			continue
+		}
+
+		if multiDay && s.Date == "" {
+			itemErrs[key] = errors.New("date is required for multi-day events")
+			continue
+		}
+		item := normalizeSchedules([]Schedule{s}, req.StartDate)[0]
+		if item.Title == "" {
+			itemErrs[key] = errors.New("title is required")
+			continue
+		}
+		itemStart, err := parseEventDateTime(item.Date, item.StartTime)
+		if err != nil {
+			itemErrs[key] = errors.New("date and startTime must be valid")
+			continue
+		}
+		itemEnd := itemStart
+		if item.EndTime != "" {
+			if itemEnd, err = parseEventDateTime(item.Date, item.EndTime); err != nil {
+				itemErrs[key] = errors.New("endTime must be valid")
+				continue
+			}
+			if itemEnd.Before(itemStart) {
+				itemErrs[key] = errors.New("endTime must not be before startTime")
+				continue
+			}
+		}
+		if itemStart.Before(start) || itemEnd.After(end) {
+			itemErrs[key] = errors.New("must be within the event period")
+		}
+	}
+	if len(itemErrs) > 0 {
+		return vd.Errors{"schedule": itemErrs}
+	}
+
+	return nil
+}
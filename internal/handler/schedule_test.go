@@ -0,0 +1,277 @@
+package handler_test
+
+import (
+	"strings"
+	"testing"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+)
+
+// errorAt は入れ子になった vd.Errors から path ("schedule.0" など) のエラーを取り出します。
+func errorAt(t *testing.T, err error, path string) error {
+	t.Helper()
+	for _, key := range strings.Split(path, ".") {
+		errs, ok := err.(vd.Errors)
+		require.Truef(t, ok, "%s: want vd.Errors, got %v", path, err)
+		err = errs[key]
+	}
+	return err
+}
+
+func TestValidateEventPeriod(t *testing.T) {
+	speakers := []handler.Speaker{{Name: "山田"}}
+	// event は 2025-01-01 09:00〜17:00 の1日のイベントです。
+	event := func(schedule ...handler.Schedule) *handler.CreateEventRequest {
+		return &handler.CreateEventRequest{
+			StartDate: "2025-01-01", StartTime: "09:00:00",
+			EndDate: "2025-01-01", EndTime: "17:00:00",
+			Speakers: speakers,
+			Schedule: schedule,
+		}
+	}
+	// multiDay は 2025-01-01 22:00〜翌日 02:00 の日をまたぐイベントです。
+	multiDay := func(schedule ...handler.Schedule) *handler.CreateEventRequest {
+		return &handler.CreateEventRequest{
+			StartDate: "2025-01-01", StartTime: "22:00",
+			EndDate: "2025-01-02", EndTime: "02:00",
+			Schedule: schedule,
+		}
+	}
+
+	tests := []struct {
+		name string
+		req  *handler.CreateEventRequest
+		// wantPath は期待するエラーの位置です。空の場合はエラーにならないことを確かめます。
+		wantPath    string
+		wantMessage string
+	}{
+		{name: "スケジュールなし", req: event()},
+		{
+			name: "開始と終了が同時刻",
+			req:  &handler.CreateEventRequest{StartDate: "2025-01-01", StartTime: "09:00", EndDate: "2025-01-01", EndTime: "09:00"},
+		},
+		{
+			name:     "終了時刻が開始より前",
+			req:      &handler.CreateEventRequest{StartDate: "2025-01-01", StartTime: "09:00", EndDate: "2025-01-01", EndTime: "08:59"},
+			wantPath: "endDate", wantMessage: "must not be before the start",
+		},
+		{
+			name:     "終了日が開始日より前",
+			req:      &handler.CreateEventRequest{StartDate: "2025-01-02", StartTime: "09:00", EndDate: "2025-01-01", EndTime: "17:00"},
+			wantPath: "endDate", wantMessage: "must not be before the start",
+		},
+		{
+			name:     "開始日時が不正",
+			req:      &handler.CreateEventRequest{StartDate: "2025-02-30", StartTime: "09:00", EndDate: "2025-03-01", EndTime: "17:00"},
+			wantPath: "startTime", wantMessage: "must be a valid date and time",
+		},
+		{
+			name:     "終了日時が不正",
+			req:      &handler.CreateEventRequest{StartDate: "2025-01-01", StartTime: "09:00", EndDate: "2025-01-01", EndTime: "25:00"},
+			wantPath: "endTime", wantMessage: "must be a valid date and time",
+		},
+		{
+			name: "開始と終了ちょうどの枠",
+			req: event(
+				handler.Schedule{StartTime: "09:00:00", EndTime: "10:00", Title: "開会"},
+				handler.Schedule{Date: "2025-01-01", StartTime: "16:00", EndTime: "17:00:00", Title: "閉会", Speakers: []string{"山田"}},
+			),
+		},
+		{
+			name:     "開始前に始まる枠",
+			req:      event(handler.Schedule{StartTime: "08:59", Title: "受付"}),
+			wantPath: "schedule.0", wantMessage: "must be within the event period",
+		},
+		{
+			name:     "終了後に終わる枠",
+			req:      event(handler.Schedule{StartTime: "16:00", EndTime: "17:01", Title: "懇親会"}),
+			wantPath: "schedule.0", wantMessage: "must be within the event period",
+		},
+		{
+			name:     "別の日の枠",
+			req:      event(handler.Schedule{Date: "2025-01-02", StartTime: "10:00", Title: "翌日"}),
+			wantPath: "schedule.0", wantMessage: "must be within the event period",
+		},
+		{
+			name:     "終了が開始より前の枠",
+			req:      event(handler.Schedule{StartTime: "11:00", EndTime: "10:00", Title: "逆転"}),
+			wantPath: "schedule.0", wantMessage: "endTime must not be before startTime",
+		},
+		{
+			name:     "旧形式の枠も範囲を確かめる",
+			req:      event(handler.Schedule{Time: "18:00:00", Speaker: "山田", Title: "旧形式"}),
+			wantPath: "schedule.0", wantMessage: "must be within the event period",
+		},
+		{
+			name:     "登録されていないスピーカー",
+			req:      event(handler.Schedule{StartTime: "10:00", Title: "講演", Speakers: []string{"佐藤"}}),
+			wantPath: "schedule.0", wantMessage: "speakers must refer to names in speakers",
+		},
+		{
+			name:     "タイトルなし",
+			req:      event(handler.Schedule{StartTime: "10:00"}),
+			wantPath: "schedule.0", wantMessage: "title is required",
+		},
+		{
+			name:     "開始時刻が不正な枠",
+			req:      event(handler.Schedule{StartTime: "10時", Title: "講演"}),
+			wantPath: "schedule.0", wantMessage: "date and startTime must be valid",
+		},
+		{
+			name:     "エラーは枠ごとに返す",
+			req:      event(handler.Schedule{StartTime: "10:00", Title: "講演"}, handler.Schedule{StartTime: "10:00", EndTime: "9:00", Title: "逆転"}),
+			wantPath: "schedule.1", wantMessage: "endTime must not be before startTime",
+		},
+		{
+			name: "日をまたぐイベントの翌日の枠",
+			req: multiDay(
+				handler.Schedule{Date: "2025-01-01", StartTime: "22:00", EndTime: "23:59", Title: "観測"},
+				handler.Schedule{Date: "2025-01-02", StartTime: "00:00", EndTime: "02:00", Title: "観測(続き)"},
+			),
+		},
+		{
+			name:     "日をまたぐイベントで日付のない枠",
+			req:      multiDay(handler.Schedule{StartTime: "23:00", Title: "観測"}),
+			wantPath: "schedule.0", wantMessage: "date is required for multi-day events",
+		},
+		{
+			name:     "日をまたぐイベントの終了後の枠",
+			req:      multiDay(handler.Schedule{Date: "2025-01-02", StartTime: "02:01", Title: "片付け"}),
+			wantPath: "schedule.0", wantMessage: "must be within the event period",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handler.ValidateEventPeriod(tt.req)
+			if tt.wantPath == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, errorAt(t, err, tt.wantPath), tt.wantMessage)
+		})
+	}
+}
+
+func TestNormalizeSchedules(t *testing.T) {
+	tests := []struct {
+		name string
+		in   handler.Schedule
+		want handler.Schedule
+	}{
+		{
+			name: "旧形式を新形式に変換する",
+			in:   handler.Schedule{Time: "09:30:00", Speaker: "山田", Title: "講演"},
+			want: handler.Schedule{Date: "2025-01-01", StartTime: "09:30", Title: "講演", Speakers: []string{"山田"}},
+		},
+		{
+			name: "新形式の値を優先する",
+			in:   handler.Schedule{Date: "2025-01-02", StartTime: "10:00", Time: "09:00", Speakers: []string{"佐藤"}, Speaker: "山田", Title: "講演"},
+			want: handler.Schedule{Date: "2025-01-02", StartTime: "10:00", Title: "講演", Speakers: []string{"佐藤"}},
+		},
+		{
+			name: "秒付きの時刻を揃える",
+			in:   handler.Schedule{StartTime: "23:59:00", EndTime: "00:00:00", Title: "深夜"},
+			want: handler.Schedule{Date: "2025-01-01", StartTime: "23:59", EndTime: "00:00", Title: "深夜"},
+		},
+		{
+			name: "解釈できない時刻はそのまま",
+			in:   handler.Schedule{StartTime: "10時", EndTime: "24:00", Title: "講演"},
+			want: handler.Schedule{Date: "2025-01-01", StartTime: "10時", EndTime: "24:00", Title: "講演"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := []handler.Schedule{tt.in}
+			got := handler.NormalizeSchedules(in, "2025-01-01")
+			require.Equal(t, []handler.Schedule{tt.want}, got)
+			require.Equal(t, tt.in, in[0], "the input must not be modified")
+		})
+	}
+}
+
+func TestGroupScheduleByDay(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []handler.Schedule
+		want []handler.ScheduleDay
+	}{
+		{name: "空", in: nil, want: []handler.ScheduleDay{}},
+		{
+			name: "日付と開始時刻で並べる",
+			in: []handler.Schedule{
+				{Date: "2025-01-02", StartTime: "09:00", Title: "2日目"},
+				{Date: "2025-01-01", StartTime: "13:00", Title: "午後"},
+				{Date: "2025-01-01", StartTime: "09:00", Title: "午前"},
+			},
+			want: []handler.ScheduleDay{
+				{Date: "2025-01-01", Items: []handler.Schedule{
+					{Date: "2025-01-01", StartTime: "09:00", Title: "午前"},
+					{Date: "2025-01-01", StartTime: "13:00", Title: "午後"},
+				}},
+				{Date: "2025-01-02", Items: []handler.Schedule{
+					{Date: "2025-01-02", StartTime: "09:00", Title: "2日目"},
+				}},
+			},
+		},
+		{
+			name: "同時刻の枠は元の順のまま",
+			in: []handler.Schedule{
+				{Date: "2025-01-01", StartTime: "10:00", Title: "A会場", Room: "A"},
+				{Date: "2025-01-01", StartTime: "10:00", Title: "B会場", Room: "B"},
+			},
+			want: []handler.ScheduleDay{
+				{Date: "2025-01-01", Items: []handler.Schedule{
+					{Date: "2025-01-01", StartTime: "10:00", Title: "A会場", Room: "A"},
+					{Date: "2025-01-01", StartTime: "10:00", Title: "B会場", Room: "B"},
+				}},
+			},
+		},
+		{
+			name: "月と年をまたぐ",
+			in: []handler.Schedule{
+				{Date: "2025-01-01", StartTime: "00:30", Title: "年明け"},
+				{Date: "2024-12-31", StartTime: "23:30", Title: "大晦日"},
+			},
+			want: []handler.ScheduleDay{
+				{Date: "2024-12-31", Items: []handler.Schedule{{Date: "2024-12-31", StartTime: "23:30", Title: "大晦日"}}},
+				{Date: "2025-01-01", Items: []handler.Schedule{{Date: "2025-01-01", StartTime: "00:30", Title: "年明け"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append([]handler.Schedule(nil), tt.in...)
+			require.Equal(t, tt.want, handler.GroupScheduleByDay(in))
+			require.Equal(t, tt.in, in, "the input must not be reordered")
+		})
+	}
+}
+
+func TestShiftScheduleDates(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		days int
+		want string
+	}{
+		{name: "ずらさない", date: "2025-01-01", days: 0, want: "2025-01-01"},
+		{name: "翌週", date: "2025-01-01", days: 7, want: "2025-01-08"},
+		{name: "月末をまたぐ", date: "2025-01-31", days: 1, want: "2025-02-01"},
+		{name: "年をまたぐ", date: "2024-12-31", days: 1, want: "2025-01-01"},
+		{name: "うるう日", date: "2024-02-28", days: 1, want: "2024-02-29"},
+		{name: "うるう年でない", date: "2025-02-28", days: 1, want: "2025-03-01"},
+		{name: "前にずらす", date: "2025-03-01", days: -1, want: "2025-02-28"},
+		{name: "解釈できない日付はそのまま", date: "2025/01/01", days: 7, want: "2025/01/01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := []handler.Schedule{{Date: tt.date, StartTime: "10:00", Title: "講演"}}
+			got := handler.ShiftScheduleDates(in, tt.days)
+			require.Equal(t, []handler.Schedule{{Date: tt.want, StartTime: "10:00", Title: "講演"}}, got)
+			require.Equal(t, tt.date, in[0].Date, "the input must not be modified")
+		})
+	}
+}
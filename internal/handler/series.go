@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/recurrence"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04:05"
+)
+
+type (
+	OccurrenceOverride struct {
+		OccurrenceDate string  `json:"occurrenceDate"`
+		Title          *string `json:"title"`
+		StartDate      *string `json:"startDate"`
+		StartTime      *string `json:"startTime"`
+		EndDate        *string `json:"endDate"`
+		EndTime        *string `json:"endTime"`
+		Venue          *string `json:"venue"`
+		IsCancelled    bool    `json:"isCancelled"`
+	}
+
+	PutOccurrenceOverrideRequest struct {
+		AuthCode    string  `json:"authcode"`
+		Title       *string `json:"title"`
+		StartDate   *string `json:"startDate"`
+		StartTime   *string `json:"startTime"`
+		EndDate     *string `json:"endDate"`
+		EndTime     *string `json:"endTime"`
+		Venue       *string `json:"venue"`
+		IsCancelled bool    `json:"isCancelled"`
+	}
+)
+
+func convertOverridesToResponse(overrides []repository.OccurrenceOverride) []OccurrenceOverride {
+	if len(overrides) == 0 {
+		return nil
+	}
+	result := make([]OccurrenceOverride, len(overrides))
+	for i, o := range overrides {
+		result[i] = OccurrenceOverride{
+			OccurrenceDate: o.OccurrenceDate,
+			Title:          o.Title,
+			StartDate:      o.StartDate,
+			StartTime:      o.StartTime,
+			EndDate:        o.EndDate,
+			EndTime:        o.EndTime,
+			Venue:          o.Venue,
+			IsCancelled:    o.IsCancelled,
+		}
+	}
+	return result
+}
+
+// parseEventDateTime はDATE/TIMEカラムの文字列をタイムゾーンを持たない時刻に変換します。
+func parseEventDateTime(date, clock string) (time.Time, error) {
+	for _, layout := range []string{dateLayout + " " + timeLayout, dateLayout + " 15:04"} {
+		if t, err := time.Parse(layout, date+" "+clock); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date time: %q %q", date, clock)
+}
+
+// validateRecurrenceRule はRRULE文字列として解釈できるかを検証します。
+var validateRecurrenceRule = vd.By(func(value interface{}) error {
+	rule, _ := value.(*string)
+	if rule == nil {
+		return nil
+	}
+	if _, err := recurrence.Parse(*rule); err != nil {
+		return fmt.Errorf("must be a valid RRULE: %w", err)
+	}
+	return nil
+})
+
+// eventSpan はイベント(シリーズの場合は初回)の開始・終了時刻を返します。
+func eventSpan(event *repository.Event) (time.Time, time.Time, error) {
+	start, err := parseEventDateTime(event.StartDate, event.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseEventDateTime(event.EndDate, event.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// recurrenceSet はシリーズの繰り返し集合を組み立てます。RDATE/EXDATEは初回の開始時刻に揃えます。
+func recurrenceSet(event *repository.Event) (recurrence.Set, error) {
+	start, _, err := eventSpan(event)
+	if err != nil {
+		return recurrence.Set{}, err
+	}
+	set := recurrence.Set{DTStart: start}
+
+	if event.RecurrenceRule != nil {
+		rule, err := recurrence.Parse(*event.RecurrenceRule)
+		if err != nil {
+			return recurrence.Set{}, err
+		}
+		set.Rule = &rule
+	}
+
+	atStartClock := func(dates []string) ([]time.Time, error) {
+		ts := make([]time.Time, len(dates))
+		for i, d := range dates {
+			t, err := parseEventDateTime(d, event.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			ts[i] = t
+		}
+		return ts, nil
+	}
+	if set.RDates, err = atStartClock(event.RecurrenceDates); err != nil {
+		return recurrence.Set{}, err
+	}
+	if set.ExDates, err = atStartClock(event.RecurrenceExDates); err != nil {
+		return recurrence.Set{}, err
+	}
+
+	return set, nil
+}
+
+// expandOccurrences はシリーズを from〜to の間に開始する各回に展開します。
+// 上書きがある回はその値を反映し、中止された回は含めません。
+func (h *Handler) expandOccurrences(event *repository.Event, from, to time.Time) ([]GetEventResponse, error) {
+	set, err := recurrenceSet(event)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := eventSpan(event)
+	if err != nil {
+		return nil, err
+	}
+	duration := end.Sub(start)
+
+	overrides := make(map[string]repository.OccurrenceOverride, len(event.Overrides))
+	for _, o := range event.Overrides {
+		overrides[o.OccurrenceDate] = o
+	}
+
+	var result []GetEventResponse
+	for _, t := range set.Between(from, to) {
+		occurrenceDate := t.Format(dateLayout)
+		res := h.newGetEventResponse(event)
+		res.Overrides = nil
+		res.OccurrenceDate = &occurrenceDate
+		res.StartDate = t.Format(dateLayout)
+		res.StartTime = t.Format(timeLayout)
+		res.EndDate = t.Add(duration).Format(dateLayout)
+		res.EndTime = t.Add(duration).Format(timeLayout)
+		res.Schedule = shiftScheduleDates(res.Schedule, int(t.Sub(start).Hours()/24))
+		res.ScheduleDays = groupScheduleByDay(res.Schedule)
+
+		if o, ok := overrides[occurrenceDate]; ok {
+			if o.IsCancelled {
+				continue
+			}
+			applyOverride(&res, o)
+		}
+		setLectureURLVisibility(&res, event, time.Now())
+		result = append(result, res)
+	}
+
+	return result, nil
+}
+
+func applyOverride(res *GetEventResponse, o repository.OccurrenceOverride) {
+	if o.Title != nil {
+		res.Title = *o.Title
+	}
+	if o.StartDate != nil {
+		res.StartDate = *o.StartDate
+	}
+	if o.StartTime != nil {
+		res.StartTime = *o.StartTime
+	}
+	if o.EndDate != nil {
+		res.EndDate = *o.EndDate
+	}
+	if o.EndTime != nil {
+		res.EndTime = *o.EndTime
+	}
+	if o.Venue != nil {
+		res.Venue = o.Venue
+	}
+}
+
+// sortEventResponses は開始日時順に並べ替えます。
+func sortEventResponses(events []GetEventResponse) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].StartDate != events[j].StartDate {
+			return events[i].StartDate < events[j].StartDate
+		}
+		return events[i].StartTime < events[j].StartTime
+	})
+}
+
+// PutOccurrenceOverride はシリーズ内の1回分の内容を変更・中止します。
+// PUT /api/v1/event/:id/occurrences/:date
+func (h *Handler) PutOccurrenceOverride(c echo.Context) error {
+	var req PutOccurrenceOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").SetInternal(err)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID").SetInternal(err)
+	}
+
+	occurrenceDate, err := time.Parse(dateLayout, c.Param("date"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid occurrence date").SetInternal(err)
+	}
+
+	authCodeUUID, err := uuid.Parse(req.AuthCode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid auth code").SetInternal(err)
+	}
+
+	if err := vd.ValidateStruct(
+		&req,
+		vd.Field(&req.StartDate, vd.Date(dateLayout)),
+		vd.Field(&req.EndDate, vd.Date(dateLayout)),
+		vd.Field(&req.StartTime, vd.Date(timeLayout)),
+		vd.Field(&req.EndTime, vd.Date(timeLayout)),
+	); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("invalid request body: %w", err))
+	}
+
+	ctx := c.Request().Context()
+	ok, err := h.repo.VerifyAuthCode(ctx, id, authCodeUUID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify auth code").SetInternal(err)
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "authentication failed")
+	}
+
+	event, err := h.repo.GetEvent(ctx, id, authCodeUUID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve event").SetInternal(err)
+	}
+	if event == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "event not found")
+	}
+	if !event.IsSeries() {
+		return echo.NewHTTPError(http.StatusBadRequest, "event is not a series")
+	}
+
+	set, err := recurrenceSet(event)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "invalid recurrence").SetInternal(err)
+	}
+	if len(set.Between(occurrenceDate, occurrenceDate.Add(24*time.Hour-time.Second))) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "occurrence not found")
+	}
+
+	err = h.repo.UpsertOccurrenceOverride(ctx, repository.OccurrenceOverride{
+		EventID:        id,
+		OccurrenceDate: occurrenceDate.Format(dateLayout),
+		Title:          req.Title,
+		StartDate:      req.StartDate,
+		StartTime:      req.StartTime,
+		EndDate:        req.EndDate,
+		EndTime:        req.EndTime,
+		Venue:          req.Venue,
+		IsCancelled:    req.IsCancelled,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save occurrence").SetInternal(err)
+	}
+	h.invalidateEvents(c)
+
+	res := UpdateEventResponse{
+		Message: "Occurrence updated successfully",
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+// OpenAPIValidatorConfig は OpenAPIValidator の設定です。
+type OpenAPIValidatorConfig struct {
+	// ValidateResponses を有効にすると、レスポンスもドキュメントと照合し、
+	// 一致しない場合は500を返します。ハンドラとドキュメントのずれを見つけるためのもので、テストで使います。
+	ValidateResponses bool
+}
+
+var openAPIDocs = sync.OnceValues(func() ([]*openapi3.T, error) {
+	v1, err := OpenAPI()
+	if err != nil {
+		return nil, err
+	}
+	v2, err := OpenAPIV2()
+	if err != nil {
+		return nil, err
+	}
+	return []*openapi3.T{v1, v2}, nil
+})
+
+// OpenAPIValidator は /api/v1, /api/v2 以下のリクエストのパス・クエリパラメータとJSONのボディを
+// OpenAPIドキュメントと照合し、一致しない場合は400を返すミドルウェアです。
+// ドキュメントにないルートはそのままハンドラに渡します。
+func OpenAPIValidator(cfg OpenAPIValidatorConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			docs, err := openAPIDocs()
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+			}
+
+			var route *routers.Route
+			for _, doc := range docs {
+				if route = findRoute(doc, c); route != nil {
+					break
+				}
+			}
+			if route == nil {
+				return next(c)
+			}
+
+			pathParams := make(map[string]string, len(c.ParamNames()))
+			for i, name := range c.ParamNames() {
+				if name == "*" {
+					name = "key"
+				}
+				pathParams[name] = c.ParamValues()[i]
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    c.Request(),
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{},
+			}
+			input.Options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+			// 画像などのmultipartのボディはハンドラで検証する
+			if isMultipart(c.Request().Header.Get(echo.HeaderContentType)) {
+				input.Options.ExcludeRequestBody = true
+			}
+
+			if err := openapi3filter.ValidateRequest(c.Request().Context(), input); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+
+			if !cfg.ValidateResponses {
+				return next(c)
+			}
+			return validateResponse(c, next, input)
+		}
+	}
+}
+
+// findRoute はechoでマッチしたルートに対応するドキュメントのオペレーションを探します。
+func findRoute(doc *openapi3.T, c echo.Context) *routers.Route {
+	prefix := ""
+	if len(doc.Servers) > 0 {
+		prefix = doc.Servers[0].URL
+	}
+	path, ok := strings.CutPrefix(c.Path(), prefix)
+	if !ok {
+		return nil
+	}
+	path = openAPIPath(path)
+
+	item := doc.Paths.Value(path)
+	if item == nil {
+		return nil
+	}
+	method := c.Request().Method
+	op := item.GetOperation(method)
+	if op == nil {
+		return nil
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    method,
+		Operation: op,
+	}
+}
+
+// validateResponse はハンドラのレスポンスをバッファしてドキュメントと照合してから書き出します。
+func validateResponse(c echo.Context, next echo.HandlerFunc, input *openapi3filter.RequestValidationInput) error {
+	res := c.Response()
+	original := res.Writer
+	buf := &bufferedWriter{header: original.Header()}
+	res.Writer = buf
+
+	err := next(c)
+	if err != nil {
+		c.Error(err)
+	}
+	res.Writer = original
+
+	status := buf.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	out := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 buf.header,
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	out.SetBodyBytes(buf.body.Bytes())
+	out.Options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+	if !isJSON(buf.header.Get(echo.HeaderContentType)) || status == http.StatusNotModified {
+		out.Options.ExcludeResponseBody = true
+	}
+
+	if verr := openapi3filter.ValidateResponse(c.Request().Context(), out); verr != nil {
+		original.Header().Del(echo.HeaderContentLength)
+		res.Committed, res.Status, res.Size = false, http.StatusOK, 0
+		msg := fmt.Sprintf("response does not match the OpenAPI document: %v", verr)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": msg})
+	}
+
+	if buf.status != 0 {
+		original.WriteHeader(buf.status)
+	}
+	if _, werr := original.Write(buf.body.Bytes()); werr != nil {
+		return werr
+	}
+	return nil
+}
+
+// bufferedWriter はレスポンスを書き出さずに保持する http.ResponseWriter です。
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// schemaErrorMessage はスキーマのエラーを「フィールドのパス: 理由」の短い形式にします。
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if pointer := err.JSONPointer(); len(pointer) > 0 {
+		return fmt.Sprintf("%s: %s", strings.Join(pointer, "."), err.Reason)
+	}
+	return err.Reason
+}
+
+func isMultipart(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == echo.MIMEMultipartForm
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == echo.MIMEApplicationJSON
+}
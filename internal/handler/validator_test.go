@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+)
+
+func newValidatedServer() *echo.Echo {
+	e := echo.New()
+	api := e.Group("/api/v1", handler.OpenAPIValidator(handler.OpenAPIValidatorConfig{ValidateResponses: true}))
+	handler.New(nil, nil, nil, nil, nil, nil).SetupRoutes(api)
+	v2 := e.Group("/api/v2", handler.OpenAPIValidator(handler.OpenAPIValidatorConfig{ValidateResponses: true}))
+	handler.New(nil, nil, nil, nil, nil, nil).SetupV2Routes(v2)
+	return e
+}
+
+func serve(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOpenAPIValidator_Request(t *testing.T) {
+	e := newValidatedServer()
+
+	tests := []struct {
+		name, method, path, body string
+		wantMessage              string
+	}{
+		{"body type", http.MethodPost, "/api/v1/event/new", `{"title": 1}`, "title"},
+		{"missing body", http.MethodPost, "/api/v1/event/1/approve", "", "request body"},
+		{"path parameter", http.MethodGet, "/api/v1/event/abc", "", "in path has an error"},
+		{"required query", http.MethodGet, "/api/v1/event/nearby?lng=139.7", "", "in query has an error"},
+		{"v2 path parameter", http.MethodGet, "/api/v2/events/abc", "", "in path has an error"},
+		{"v2 required auth code", http.MethodDelete, "/api/v2/events/1", "", "in query has an error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, tt.method, tt.path, tt.body)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			require.Contains(t, rec.Body.String(), tt.wantMessage)
+		})
+	}
+}
+
+func TestOpenAPIValidator_Response(t *testing.T) {
+	rec := serve(newValidatedServer(), http.MethodGet, "/api/v1/metadata", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"prefectures"`)
+
+	// ドキュメントと異なるレスポンスは500にする
+	e := echo.New()
+	api := e.Group("/api/v1", handler.OpenAPIValidator(handler.OpenAPIValidatorConfig{ValidateResponses: true}))
+	api.GET("/metadata", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{"prefectures": "all"})
+	})
+	rec = serve(e, http.MethodGet, "/api/v1/metadata", "")
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Contains(t, rec.Body.String(), "prefectures")
+}
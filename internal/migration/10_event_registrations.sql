@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- 参加登録。イベントとメールアドレスの組ごとに1件
+CREATE TABLE IF NOT EXISTS event_registrations (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    event_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_event_registrations_event_email (event_id, email),
+    FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
+
+-- 参加用リンクのトークン。token_hash はトークンのSHA-256ハッシュ
+-- 申し込みでは本人確認をしないため、申し込み直しても以前のトークンは消さず、新しいトークンを追加して登録済みのアドレスに送る
+CREATE TABLE IF NOT EXISTS event_registration_tokens (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    registration_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (registration_id) REFERENCES event_registrations (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_registration_tokens;
+DROP TABLE IF EXISTS event_registrations;
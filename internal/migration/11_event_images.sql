@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- ポスター画像。ストレージ上のキーを保存し、URLは配信時に組み立てる
+ALTER TABLE events
+    ADD COLUMN poster_image_key VARCHAR(255) NULL,
+    ADD COLUMN poster_thumbnail_key VARCHAR(255) NULL;
+
+-- +goose Down
+ALTER TABLE events
+    DROP COLUMN poster_image_key,
+    DROP COLUMN poster_thumbnail_key;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- 運営者。token_hash は create-admin コマンドで発行したトークンのSHA-256ハッシュ
+CREATE TABLE IF NOT EXISTS admins (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_admins_email (email),
+    UNIQUE KEY uq_admins_token_hash (token_hash)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS admins;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- シードで登録したイベントの識別子。同じフィクスチャを何度投入しても重複しないようにする
+-- 本番のイベントは NULL のまま
+ALTER TABLE events
+    ADD COLUMN seed_key VARCHAR(64) NULL,
+    ADD UNIQUE KEY uq_events_seed_key (seed_key);
+
+-- +goose Down
+ALTER TABLE events
+    DROP INDEX uq_events_seed_key,
+    DROP COLUMN seed_key;
@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- 3_a.sql と 4_.sql が挿入していたサンプルのイベント(各2件ずつ)を削除する
+-- 主催者が登録したイベントを消さないよう、タイトル・主催者・連絡先・日付がすべて一致し、シードで登録していない行に限る
+DELETE FROM events
+WHERE seed_key IS NULL
+  AND start_date = '2024-03-14'
+  AND (title, organizer, email) IN (
+      ('数学ミニフォーラム', '東京大学 数理教室', 'info@mathforum.example.com'),
+      ('数学の歴史とパイのお話会', '東京大学カブリIPMU', 'history@mathforum.example.com'),
+      ('ハイブリッド数学セミナー', '数学振興協会', 'hybrid@mathforum.example.com')
+  );
+
+-- +goose Down
+-- 削除したサンプルのイベントは戻さない
+SELECT 1;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- 公開する一覧: is_authenticated で絞り込み、start_date, start_time の順に並べる (GetEvents)
+-- 並べ替えまでインデックスで済むので filesort にならない
+CREATE INDEX idx_events_listing ON events (is_authenticated, start_date, start_time);
+
+-- 近くのイベント: 承認済みのうち緯度の範囲で絞り込む (GetEvents の Bounds)
+CREATE INDEX idx_events_location ON events (is_authenticated, latitude, longitude);
+
+-- 認証コードのハッシュは一意にする。3_a.sql・4_.sql のサンプルが同じコードを持っていたが 14 で削除済み
+-- 未発行(NULL)の行は複数あってよい
+CREATE UNIQUE INDEX uq_events_auth_code_hash ON events (auth_code_hash);
+CREATE UNIQUE INDEX uq_events_approval_code_hash ON events (approval_code_hash);
+
+-- 参加用リンクの確認はトークンのハッシュで引く (VerifyRegistration)
+CREATE UNIQUE INDEX uq_event_registration_tokens_hash ON event_registration_tokens (token_hash);
+
+-- +goose Down
+DROP INDEX uq_event_registration_tokens_hash ON event_registration_tokens;
+DROP INDEX uq_events_approval_code_hash ON events;
+DROP INDEX uq_events_auth_code_hash ON events;
+DROP INDEX idx_events_location ON events;
+DROP INDEX idx_events_listing ON events;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- 説明文のMarkdownを変換したHTML。読み出すたびに変換しないよう、書き込むときに保存する
+-- 既存の行はSQLでは変換できないため NULL のままにし、次に編集されるまでは読み出すときに変換する
+ALTER TABLE events ADD COLUMN description_html TEXT;
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN description_html;
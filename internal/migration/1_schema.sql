@@ -5,3 +5,6 @@ CREATE TABLE IF NOT EXISTS users (
 	email VARCHAR(255) NOT NULL,
 	PRIMARY KEY (id)
 );
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
@@ -24,3 +24,6 @@ CREATE TABLE IF NOT EXISTS events (
     auth_code VARCHAR(36),
     is_authenticated BOOLEAN DEFAULT FALSE
 );
+
+-- +goose Down
+DROP TABLE IF EXISTS events;
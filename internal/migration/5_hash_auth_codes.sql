@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- 認証コードは平文で保存せず、SHA-256ハッシュと有効期限のみを保持する
+-- approval_code_* は申請時に運営者へ通知する承認用のコード、auth_code_* は主催者に発行する閲覧・編集用のコード
+-- 主催者のコードを発行し直しても、運営者の承認用のコードは変わらない
+ALTER TABLE events
+    ADD COLUMN auth_code_hash CHAR(64),
+    ADD COLUMN auth_code_expires_at DATETIME,
+    ADD COLUMN approval_code_hash CHAR(64),
+    ADD COLUMN approval_code_expires_at DATETIME;
+
+-- 既存のコードは申請時に運営者へ通知したものなので承認用としてハッシュ化し、移行時点から7日間だけ有効とする
+UPDATE events
+SET approval_code_hash = SHA2(auth_code, 256),
+    approval_code_expires_at = DATE_ADD(UTC_TIMESTAMP(), INTERVAL 7 DAY)
+WHERE auth_code IS NOT NULL;
+
+ALTER TABLE events DROP COLUMN auth_code;
+
+-- +goose Down
+-- 平文の認証コードはハッシュから戻せないため、auth_code は空のまま戻す
+ALTER TABLE events ADD COLUMN auth_code VARCHAR(36);
+
+ALTER TABLE events
+    DROP COLUMN auth_code_hash,
+    DROP COLUMN auth_code_expires_at,
+    DROP COLUMN approval_code_hash,
+    DROP COLUMN approval_code_expires_at;
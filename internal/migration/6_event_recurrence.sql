@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- イベントシリーズ: RRULE形式の繰り返しルール、追加日(RDATE)、除外日(EXDATE)
+ALTER TABLE events
+    ADD COLUMN recurrence_rule VARCHAR(255),
+    ADD COLUMN recurrence_dates JSON,
+    ADD COLUMN recurrence_exdates JSON;
+
+-- シリーズ内の1回分だけを変更・中止するための上書き
+CREATE TABLE IF NOT EXISTS event_occurrence_overrides (
+    event_id INT NOT NULL,
+    occurrence_date DATE NOT NULL,
+    title VARCHAR(255),
+    start_date DATE,
+    start_time TIME,
+    end_date DATE,
+    end_time TIME,
+    venue VARCHAR(255),
+    is_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (event_id, occurrence_date),
+    FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_occurrence_overrides;
+
+ALTER TABLE events
+    DROP COLUMN recurrence_rule,
+    DROP COLUMN recurrence_dates,
+    DROP COLUMN recurrence_exdates;
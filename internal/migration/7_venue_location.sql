@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- 会場の構造化された住所と位置情報
+-- location_precision は 'exact'(主催者が指定) または 'prefecture'(都道府県の代表点による近似)
+ALTER TABLE events
+    ADD COLUMN venue_address VARCHAR(255),
+    ADD COLUMN venue_postal_code VARCHAR(8),
+    ADD COLUMN latitude DOUBLE,
+    ADD COLUMN longitude DOUBLE,
+    ADD COLUMN location_precision VARCHAR(16);
+
+-- +goose Down
+ALTER TABLE events
+    DROP COLUMN venue_address,
+    DROP COLUMN venue_postal_code,
+    DROP COLUMN latitude,
+    DROP COLUMN longitude,
+    DROP COLUMN location_precision;
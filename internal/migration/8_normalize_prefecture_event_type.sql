@@ -0,0 +1,135 @@
+-- +goose Up
+
+-- prefecture をJIS X 0401の2桁コードに、event_type を種別IDに正規化する
+-- アプリの internal/pkg/prefecture・eventtype の Lookup と同じ表記(コード・日本語名・略称・英語名)を受け付ける
+-- 対応表が Lookup と一致することは integration/migration_test.go で確かめる
+-- 値を書き換えた行は、元の表記を legacy_prefecture・legacy_event_type に残して Down で戻す
+-- 解釈できずにNULLにした値もここに残るので、あとから手で直せる
+ALTER TABLE events
+    ADD COLUMN legacy_prefecture VARCHAR(255),
+    ADD COLUMN legacy_event_type VARCHAR(255);
+
+CREATE TABLE migration_prefectures (
+    code CHAR(2) PRIMARY KEY,
+    digit CHAR(1),
+    name VARCHAR(8) NOT NULL,
+    short_name VARCHAR(8),
+    name_en VARCHAR(32) NOT NULL
+);
+
+INSERT INTO migration_prefectures (code, digit, name, short_name, name_en) VALUES
+    ('01', '1', '北海道', NULL, 'hokkaido'),
+    ('02', '2', '青森県', '青森', 'aomori'),
+    ('03', '3', '岩手県', '岩手', 'iwate'),
+    ('04', '4', '宮城県', '宮城', 'miyagi'),
+    ('05', '5', '秋田県', '秋田', 'akita'),
+    ('06', '6', '山形県', '山形', 'yamagata'),
+    ('07', '7', '福島県', '福島', 'fukushima'),
+    ('08', '8', '茨城県', '茨城', 'ibaraki'),
+    ('09', '9', '栃木県', '栃木', 'tochigi'),
+    ('10', NULL, '群馬県', '群馬', 'gunma'),
+    ('11', NULL, '埼玉県', '埼玉', 'saitama'),
+    ('12', NULL, '千葉県', '千葉', 'chiba'),
+    ('13', NULL, '東京都', '東京', 'tokyo'),
+    ('14', NULL, '神奈川県', '神奈川', 'kanagawa'),
+    ('15', NULL, '新潟県', '新潟', 'niigata'),
+    ('16', NULL, '富山県', '富山', 'toyama'),
+    ('17', NULL, '石川県', '石川', 'ishikawa'),
+    ('18', NULL, '福井県', '福井', 'fukui'),
+    ('19', NULL, '山梨県', '山梨', 'yamanashi'),
+    ('20', NULL, '長野県', '長野', 'nagano'),
+    ('21', NULL, '岐阜県', '岐阜', 'gifu'),
+    ('22', NULL, '静岡県', '静岡', 'shizuoka'),
+    ('23', NULL, '愛知県', '愛知', 'aichi'),
+    ('24', NULL, '三重県', '三重', 'mie'),
+    ('25', NULL, '滋賀県', '滋賀', 'shiga'),
+    ('26', NULL, '京都府', '京都', 'kyoto'),
+    ('27', NULL, '大阪府', '大阪', 'osaka'),
+    ('28', NULL, '兵庫県', '兵庫', 'hyogo'),
+    ('29', NULL, '奈良県', '奈良', 'nara'),
+    ('30', NULL, '和歌山県', '和歌山', 'wakayama'),
+    ('31', NULL, '鳥取県', '鳥取', 'tottori'),
+    ('32', NULL, '島根県', '島根', 'shimane'),
+    ('33', NULL, '岡山県', '岡山', 'okayama'),
+    ('34', NULL, '広島県', '広島', 'hiroshima'),
+    ('35', NULL, '山口県', '山口', 'yamaguchi'),
+    ('36', NULL, '徳島県', '徳島', 'tokushima'),
+    ('37', NULL, '香川県', '香川', 'kagawa'),
+    ('38', NULL, '愛媛県', '愛媛', 'ehime'),
+    ('39', NULL, '高知県', '高知', 'kochi'),
+    ('40', NULL, '福岡県', '福岡', 'fukuoka'),
+    ('41', NULL, '佐賀県', '佐賀', 'saga'),
+    ('42', NULL, '長崎県', '長崎', 'nagasaki'),
+    ('43', NULL, '熊本県', '熊本', 'kumamoto'),
+    ('44', NULL, '大分県', '大分', 'oita'),
+    ('45', NULL, '宮崎県', '宮崎', 'miyazaki'),
+    ('46', NULL, '鹿児島県', '鹿児島', 'kagoshima'),
+    ('47', NULL, '沖縄県', '沖縄', 'okinawa');
+
+-- 表記のどれかに一致するか、「大阪府（オンラインでも参加可）」のように都道府県名で始まる値をコードにする
+UPDATE events
+SET legacy_prefecture = prefecture,
+    prefecture = COALESCE(
+        (SELECT p.code FROM migration_prefectures p
+         WHERE LOWER(TRIM(events.prefecture)) IN (p.code, p.digit, p.name, p.short_name, p.name_en)),
+        (SELECT p.code FROM migration_prefectures p
+         WHERE events.prefecture LIKE CONCAT(p.name, '%'))
+    )
+WHERE prefecture IS NOT NULL;
+
+-- prefecture が空で、venue が都道府県名で始まる場合はそれを使う
+-- 元がNULLだった行は legacy_prefecture を空文字列にし、Down でNULLに戻す
+UPDATE events
+SET legacy_prefecture = COALESCE(legacy_prefecture, ''),
+    prefecture = (SELECT p.code FROM migration_prefectures p WHERE events.venue LIKE CONCAT(p.name, '%'))
+WHERE prefecture IS NULL
+  AND EXISTS (SELECT 1 FROM migration_prefectures p WHERE events.venue LIKE CONCAT(p.name, '%'));
+
+UPDATE events SET legacy_prefecture = NULL WHERE legacy_prefecture = prefecture;
+
+DROP TABLE migration_prefectures;
+
+ALTER TABLE events MODIFY COLUMN prefecture CHAR(2);
+
+CREATE TABLE migration_event_types (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(32) NOT NULL,
+    name_en VARCHAR(32) NOT NULL
+);
+
+INSERT INTO migration_event_types (id, name, name_en) VALUES
+    ('lecture', '講演会', 'lecture'),
+    ('seminar', 'セミナー', 'seminar'),
+    ('workshop', 'ワークショップ', 'workshop'),
+    ('symposium', 'シンポジウム', 'symposium'),
+    ('exhibition', '展示', 'exhibition'),
+    ('competition', 'コンテスト', 'competition'),
+    ('hands_on', '体験教室', 'hands-on class'),
+    ('other', 'その他', 'other');
+
+-- 既存データの「オンライン」「オフライン」「ハイブリッド」は配信形態で種別ではないのでNULLにする
+-- 元の値は legacy_event_type に残り、9_delivery_mode.sql で開催形態に反映する
+UPDATE events
+SET legacy_event_type = event_type,
+    event_type = (SELECT t.id FROM migration_event_types t
+                  WHERE LOWER(TRIM(events.event_type)) IN (t.id, t.name, t.name_en))
+WHERE event_type IS NOT NULL;
+
+UPDATE events SET legacy_event_type = NULL WHERE legacy_event_type = event_type;
+
+DROP TABLE migration_event_types;
+
+ALTER TABLE events MODIFY COLUMN event_type VARCHAR(32);
+
+-- +goose Down
+-- 正規化する前の表記に戻す。Up のあとに登録・編集された行はコード・種別IDのまま残る
+ALTER TABLE events
+    MODIFY COLUMN prefecture VARCHAR(255),
+    MODIFY COLUMN event_type VARCHAR(255);
+
+UPDATE events SET prefecture = NULLIF(legacy_prefecture, '') WHERE legacy_prefecture IS NOT NULL;
+UPDATE events SET event_type = legacy_event_type WHERE legacy_event_type IS NOT NULL;
+
+ALTER TABLE events
+    DROP COLUMN legacy_prefecture,
+    DROP COLUMN legacy_event_type;
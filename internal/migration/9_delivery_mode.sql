@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- 開催形態を delivery_mode に一本化する
+-- 開催形態は is_online / is_offline のほか、8 で event_type から外した「オンライン」などの表記(legacy_event_type)にも残っている
+-- 食い違う行は、どちらかが示す形態をすべて含める(オンラインとオフラインが揃えばハイブリッド)。参加方法の情報を落とさないため
+-- どれも示さない行は、オンライン講義URLがあればオンライン、なければオフラインとみなす
+-- 表記は internal/pkg/deliverymode の ID・日本語名・英語名を受け付ける
+ALTER TABLE events ADD COLUMN delivery_mode VARCHAR(16);
+
+UPDATE events
+SET delivery_mode = CASE
+        WHEN (is_online OR LOWER(TRIM(legacy_event_type)) IN ('online', 'オンライン', 'hybrid', 'ハイブリッド'))
+         AND (is_offline OR LOWER(TRIM(legacy_event_type)) IN ('offline', 'オフライン', 'in person', 'hybrid', 'ハイブリッド'))
+            THEN 'hybrid'
+        WHEN is_online OR LOWER(TRIM(legacy_event_type)) IN ('online', 'オンライン') THEN 'online'
+        WHEN is_offline OR LOWER(TRIM(legacy_event_type)) IN ('offline', 'オフライン', 'in person') THEN 'offline'
+        WHEN online_lecture_url IS NOT NULL AND online_lecture_url <> '' THEN 'online'
+        ELSE 'offline'
+    END;
+
+-- 互換性のために残す is_online / is_offline を delivery_mode に合わせる
+UPDATE events
+SET is_online = delivery_mode IN ('online', 'hybrid'),
+    is_offline = delivery_mode IN ('offline', 'hybrid');
+
+ALTER TABLE events MODIFY COLUMN delivery_mode VARCHAR(16) NOT NULL;
+
+-- +goose Down
+-- 開催形態は is_online / is_offline にも残っているので、delivery_mode を消すだけでよい
+ALTER TABLE events DROP COLUMN delivery_mode;
@@ -1,26 +1,226 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose/v3"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
 )
 
-//go:embed *.sql
+// MySQLのマイグレーションはこのディレクトリに、SQLiteのものは sqlite/ に同じバージョンで置きます。
+//
+//go:embed *.sql sqlite/*.sql
 var embedMigrations embed.FS
 
-func MigrateTables(db *sql.DB) error {
+var (
+	// ErrSchemaBehind はDBに未適用のマイグレーションがあることを表します。
+	ErrSchemaBehind = errors.New("database schema is behind the application")
+	// ErrSchemaAhead はDBにアプリが知らないマイグレーションが適用されていることを表します。
+	// 新しいバージョンのアプリが適用したあとで古いアプリを起動した場合などに起こります。
+	ErrSchemaAhead = errors.New("database schema is ahead of the application")
+)
+
+const (
+	// lockName は複数のインスタンスが同時にマイグレーションしないためのMySQLのロックの名前です。
+	lockName = "go-backend-template.migration"
+	// lockTimeoutSeconds はロックを待つ秒数です。他のインスタンスのマイグレーションが終わるのを待ちます。
+	lockTimeoutSeconds = 300
+)
+
+// mu は同じプロセス内でのマイグレーションを直列にします。gooseの設定はグローバルなためです。
+var mu sync.Mutex
+
+// setup はgooseに埋め込んだマイグレーションとDBの方言を設定し、マイグレーションのディレクトリを返します。
+func setup(driver string) (string, error) {
 	goose.SetBaseFS(embedMigrations)
 
-	if err := goose.SetDialect("mysql"); err != nil {
-		return fmt.Errorf("set dialect: %w", err)
+	dir := "."
+	if driver == database.SQLite {
+		dir = "sqlite"
 	}
+	if err := goose.SetDialect(driver); err != nil {
+		return "", fmt.Errorf("set dialect: %w", err)
+	}
+	return dir, nil
+}
 
-	if err := goose.Up(db, "."); err != nil {
-		return fmt.Errorf("up migration: %w", err)
+// withLock はMySQLのロック(GET_LOCK)を取ってから、マイグレーションのディレクトリを渡して fn を実行します。
+// ロックは接続ごとに持つため、専用の接続を fn の間保持します。
+// SQLiteは1つのプロセスからしか使わないため、プロセス内のロックだけを取ります。
+func withLock(db *sqlx.DB, fn func(dir string) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := setup(db.DriverName())
+	if err != nil {
+		return err
+	}
+	if db.DriverName() != database.MySQL {
+		return fn(dir)
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeoutSeconds).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("acquire migration lock: timed out after %d seconds", lockTimeoutSeconds)
 	}
+	defer conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+
+	return fn(dir)
+}
+
+// MigrateTables はすべてのマイグレーションを適用します。Up と同じです。
+func MigrateTables(db *sqlx.DB) error {
+	return Up(db)
+}
+
+// Up は未適用のマイグレーションをすべて適用します。
+func Up(db *sqlx.DB) error {
+	return withLock(db, func(dir string) error {
+		if err := goose.Up(db.DB, dir); err != nil {
+			return fmt.Errorf("up migration: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpTo は version までのマイグレーションを適用します。
+func UpTo(db *sqlx.DB, version int64) error {
+	return withLock(db, func(dir string) error {
+		if err := goose.UpTo(db.DB, dir, version); err != nil {
+			return fmt.Errorf("up migration to %d: %w", version, err)
+		}
+		return nil
+	})
+}
+
+// Down は最後に適用したマイグレーションを1つ戻します。
+func Down(db *sqlx.DB) error {
+	return withLock(db, func(dir string) error {
+		if err := goose.Down(db.DB, dir); err != nil {
+			return fmt.Errorf("down migration: %w", err)
+		}
+		return nil
+	})
+}
+
+// DownTo は version より後のマイグレーションをすべて戻します。0 を指定するとすべて戻します。
+func DownTo(db *sqlx.DB, version int64) error {
+	return withLock(db, func(dir string) error {
+		if err := goose.DownTo(db.DB, dir, version); err != nil {
+			return fmt.Errorf("down migration to %d: %w", version, err)
+		}
+		return nil
+	})
+}
+
+// Redo は最後に適用したマイグレーションを戻してから適用し直します。
+func Redo(db *sqlx.DB) error {
+	return withLock(db, func(dir string) error {
+		if err := goose.Redo(db.DB, dir); err != nil {
+			return fmt.Errorf("redo migration: %w", err)
+		}
+		return nil
+	})
+}
+
+// Status は各マイグレーションの適用状況をログに出力します。
+func Status(db *sqlx.DB) error {
+	mu.Lock()
+	defer mu.Unlock()
 
+	dir, err := setup(db.DriverName())
+	if err != nil {
+		return err
+	}
+
+	if err := goose.Status(db.DB, dir); err != nil {
+		return fmt.Errorf("migration status: %w", err)
+	}
+
+	return nil
+}
+
+// Versions は driver (mysql / sqlite) 向けにアプリに含まれるマイグレーションのバージョンを昇順で返します。
+func Versions(driver string) ([]int64, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return versions(driver)
+}
+
+func versions(driver string) ([]int64, error) {
+	dir, err := setup(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("collect migrations: %w", err)
+	}
+
+	vs := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		vs = append(vs, m.Version)
+	}
+	return vs, nil
+}
+
+// Version はDBに適用済みのバージョンと、アプリに含まれる最新のバージョンを返します。
+func Version(db *sqlx.DB) (current, latest int64, err error) {
+	return VersionContext(context.Background(), db)
+}
+
+// VersionContext は Version と同じですが、ctx が終わるとDBへの問い合わせを中断します。
+func VersionContext(ctx context.Context, db *sqlx.DB) (current, latest int64, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	vs, err := versions(db.DriverName())
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(vs) > 0 {
+		latest = vs[len(vs)-1]
+	}
+
+	current, err = goose.GetDBVersionContext(ctx, db.DB)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get database version: %w", err)
+	}
+	return current, latest, nil
+}
+
+// Check はDBのスキーマがアプリと同じバージョンかを確かめます。
+// 未適用のマイグレーションがあれば ErrSchemaBehind、DBの方が新しければ ErrSchemaAhead を返します。
+func Check(db *sqlx.DB) error {
+	current, latest, err := Version(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case current < latest:
+		return fmt.Errorf("%w: database is at version %d, application expects %d", ErrSchemaBehind, current, latest)
+	case current > latest:
+		return fmt.Errorf("%w: database is at version %d, application expects %d", ErrSchemaAhead, current, latest)
+	}
 	return nil
 }
@@ -0,0 +1,51 @@
+package migration_test
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/migration"
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
+)
+
+// TestMigrations_HaveDownSection はすべてのマイグレーションが戻せることを、DBを使わずに確かめます。
+// 実際に up/down/up できるかは integration/migration_test.go で確かめます。
+func TestMigrations_HaveDownSection(t *testing.T) {
+	mysqlFiles, err := fs.Glob(os.DirFS("."), "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, mysqlFiles)
+	sqliteFiles, err := fs.Glob(os.DirFS("."), "sqlite/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, sqliteFiles)
+	files := append(mysqlFiles, sqliteFiles...)
+
+	for _, name := range files {
+		b, err := os.ReadFile(name)
+		require.NoError(t, err)
+		sql := string(b)
+
+		up := strings.Index(sql, "-- +goose Up")
+		down := strings.Index(sql, "-- +goose Down")
+		require.GreaterOrEqual(t, up, 0, "%s has no Up section", name)
+		require.Greater(t, down, up, "%s has no Down section after Up", name)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	versions, err := migration.Versions(database.MySQL)
+	require.NoError(t, err)
+
+	// バージョンは1からの連番にする
+	for i, v := range versions {
+		require.Equal(t, int64(i+1), v)
+	}
+
+	// SQLite用のマイグレーションはMySQL用と同じバージョンで揃える
+	sqliteVersions, err := migration.Versions(database.SQLite)
+	require.NoError(t, err)
+	require.Equal(t, versions, sqliteVersions)
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS event_registrations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    CONSTRAINT uq_event_registrations_event_email UNIQUE (event_id, email),
+    FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS event_registration_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    registration_id INTEGER NOT NULL,
+    token_hash TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    FOREIGN KEY (registration_id) REFERENCES event_registrations (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_registration_tokens;
+DROP TABLE IF EXISTS event_registrations;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN poster_image_key TEXT;
+ALTER TABLE events ADD COLUMN poster_thumbnail_key TEXT;
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN poster_image_key;
+ALTER TABLE events DROP COLUMN poster_thumbnail_key;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS admins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    CONSTRAINT uq_admins_email UNIQUE (email),
+    CONSTRAINT uq_admins_token_hash UNIQUE (token_hash)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS admins;
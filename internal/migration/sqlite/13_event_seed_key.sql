@@ -0,0 +1,8 @@
+-- +goose Up
+-- SQLiteの ADD COLUMN では UNIQUE を付けられないため、インデックスを別に作る
+ALTER TABLE events ADD COLUMN seed_key TEXT;
+CREATE UNIQUE INDEX uq_events_seed_key ON events (seed_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS uq_events_seed_key;
+ALTER TABLE events DROP COLUMN seed_key;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQLiteにサンプルのイベントが入ったことはないが、MySQLと同じバージョンにするために置く
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
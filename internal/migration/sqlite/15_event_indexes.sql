@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE INDEX idx_events_listing ON events (is_authenticated, start_date, start_time);
+CREATE INDEX idx_events_location ON events (is_authenticated, latitude, longitude);
+CREATE UNIQUE INDEX uq_events_auth_code_hash ON events (auth_code_hash);
+CREATE UNIQUE INDEX uq_events_approval_code_hash ON events (approval_code_hash);
+CREATE UNIQUE INDEX uq_event_registration_tokens_hash ON event_registration_tokens (token_hash);
+
+-- +goose Down
+DROP INDEX IF EXISTS uq_event_registration_tokens_hash;
+DROP INDEX IF EXISTS uq_events_approval_code_hash;
+DROP INDEX IF EXISTS uq_events_auth_code_hash;
+DROP INDEX IF EXISTS idx_events_location;
+DROP INDEX IF EXISTS idx_events_listing;
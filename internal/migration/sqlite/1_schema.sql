@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- 日付・時刻・JSONはTEXT、真偽値はINTEGER(0/1)で持つ
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    organizer TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    start_time TEXT NOT NULL,
+    end_date TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    email TEXT NOT NULL,
+    prefecture TEXT,
+    event_type TEXT,
+    is_online INTEGER DEFAULT FALSE,
+    is_offline INTEGER DEFAULT FALSE,
+    official_url TEXT,
+    online_lecture_url TEXT,
+    venue TEXT,
+    target TEXT,
+    capacity TEXT,
+    description TEXT,
+    tags TEXT,
+    speakers TEXT,
+    schedule TEXT,
+    auth_code TEXT,
+    is_authenticated INTEGER DEFAULT FALSE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS events;
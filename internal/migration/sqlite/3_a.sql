@@ -0,0 +1,6 @@
+-- +goose Up
+-- MySQLと同じバージョンにするための空のマイグレーション
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
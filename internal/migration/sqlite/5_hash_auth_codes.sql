@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQLiteにはSHA2がないため、既存の認証コードは移行しない(SQLiteは開発用で、移行すべきデータはない)
+ALTER TABLE events ADD COLUMN auth_code_hash TEXT;
+ALTER TABLE events ADD COLUMN auth_code_expires_at TEXT;
+ALTER TABLE events ADD COLUMN approval_code_hash TEXT;
+ALTER TABLE events ADD COLUMN approval_code_expires_at TEXT;
+ALTER TABLE events DROP COLUMN auth_code;
+
+-- +goose Down
+ALTER TABLE events ADD COLUMN auth_code TEXT;
+ALTER TABLE events DROP COLUMN auth_code_hash;
+ALTER TABLE events DROP COLUMN auth_code_expires_at;
+ALTER TABLE events DROP COLUMN approval_code_hash;
+ALTER TABLE events DROP COLUMN approval_code_expires_at;
@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN recurrence_rule TEXT;
+ALTER TABLE events ADD COLUMN recurrence_dates TEXT;
+ALTER TABLE events ADD COLUMN recurrence_exdates TEXT;
+
+CREATE TABLE IF NOT EXISTS event_occurrence_overrides (
+    event_id INTEGER NOT NULL,
+    occurrence_date TEXT NOT NULL,
+    title TEXT,
+    start_date TEXT,
+    start_time TEXT,
+    end_date TEXT,
+    end_time TEXT,
+    venue TEXT,
+    is_cancelled INTEGER NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (event_id, occurrence_date),
+    FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_occurrence_overrides;
+ALTER TABLE events DROP COLUMN recurrence_rule;
+ALTER TABLE events DROP COLUMN recurrence_dates;
+ALTER TABLE events DROP COLUMN recurrence_exdates;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN venue_address TEXT;
+ALTER TABLE events ADD COLUMN venue_postal_code TEXT;
+ALTER TABLE events ADD COLUMN latitude REAL;
+ALTER TABLE events ADD COLUMN longitude REAL;
+ALTER TABLE events ADD COLUMN location_precision TEXT;
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN venue_address;
+ALTER TABLE events DROP COLUMN venue_postal_code;
+ALTER TABLE events DROP COLUMN latitude;
+ALTER TABLE events DROP COLUMN longitude;
+ALTER TABLE events DROP COLUMN location_precision;
@@ -0,0 +1,114 @@
+-- +goose Up
+-- MySQLと同じ対応表で正規化する。SQLiteは列の型を変えずに済む
+ALTER TABLE events ADD COLUMN legacy_prefecture TEXT;
+ALTER TABLE events ADD COLUMN legacy_event_type TEXT;
+
+CREATE TABLE migration_prefectures (
+    code TEXT PRIMARY KEY,
+    digit TEXT,
+    name TEXT NOT NULL,
+    short_name TEXT,
+    name_en TEXT NOT NULL
+);
+
+INSERT INTO migration_prefectures (code, digit, name, short_name, name_en) VALUES
+    ('01', '1', '北海道', NULL, 'hokkaido'),
+    ('02', '2', '青森県', '青森', 'aomori'),
+    ('03', '3', '岩手県', '岩手', 'iwate'),
+    ('04', '4', '宮城県', '宮城', 'miyagi'),
+    ('05', '5', '秋田県', '秋田', 'akita'),
+    ('06', '6', '山形県', '山形', 'yamagata'),
+    ('07', '7', '福島県', '福島', 'fukushima'),
+    ('08', '8', '茨城県', '茨城', 'ibaraki'),
+    ('09', '9', '栃木県', '栃木', 'tochigi'),
+    ('10', NULL, '群馬県', '群馬', 'gunma'),
+    ('11', NULL, '埼玉県', '埼玉', 'saitama'),
+    ('12', NULL, '千葉県', '千葉', 'chiba'),
+    ('13', NULL, '東京都', '東京', 'tokyo'),
+    ('14', NULL, '神奈川県', '神奈川', 'kanagawa'),
+    ('15', NULL, '新潟県', '新潟', 'niigata'),
+    ('16', NULL, '富山県', '富山', 'toyama'),
+    ('17', NULL, '石川県', '石川', 'ishikawa'),
+    ('18', NULL, '福井県', '福井', 'fukui'),
+    ('19', NULL, '山梨県', '山梨', 'yamanashi'),
+    ('20', NULL, '長野県', '長野', 'nagano'),
+    ('21', NULL, '岐阜県', '岐阜', 'gifu'),
+    ('22', NULL, '静岡県', '静岡', 'shizuoka'),
+    ('23', NULL, '愛知県', '愛知', 'aichi'),
+    ('24', NULL, '三重県', '三重', 'mie'),
+    ('25', NULL, '滋賀県', '滋賀', 'shiga'),
+    ('26', NULL, '京都府', '京都', 'kyoto'),
+    ('27', NULL, '大阪府', '大阪', 'osaka'),
+    ('28', NULL, '兵庫県', '兵庫', 'hyogo'),
+    ('29', NULL, '奈良県', '奈良', 'nara'),
+    ('30', NULL, '和歌山県', '和歌山', 'wakayama'),
+    ('31', NULL, '鳥取県', '鳥取', 'tottori'),
+    ('32', NULL, '島根県', '島根', 'shimane'),
+    ('33', NULL, '岡山県', '岡山', 'okayama'),
+    ('34', NULL, '広島県', '広島', 'hiroshima'),
+    ('35', NULL, '山口県', '山口', 'yamaguchi'),
+    ('36', NULL, '徳島県', '徳島', 'tokushima'),
+    ('37', NULL, '香川県', '香川', 'kagawa'),
+    ('38', NULL, '愛媛県', '愛媛', 'ehime'),
+    ('39', NULL, '高知県', '高知', 'kochi'),
+    ('40', NULL, '福岡県', '福岡', 'fukuoka'),
+    ('41', NULL, '佐賀県', '佐賀', 'saga'),
+    ('42', NULL, '長崎県', '長崎', 'nagasaki'),
+    ('43', NULL, '熊本県', '熊本', 'kumamoto'),
+    ('44', NULL, '大分県', '大分', 'oita'),
+    ('45', NULL, '宮崎県', '宮崎', 'miyazaki'),
+    ('46', NULL, '鹿児島県', '鹿児島', 'kagoshima'),
+    ('47', NULL, '沖縄県', '沖縄', 'okinawa');
+
+UPDATE events
+SET legacy_prefecture = prefecture,
+    prefecture = COALESCE(
+        (SELECT p.code FROM migration_prefectures p
+         WHERE LOWER(TRIM(events.prefecture)) IN (p.code, p.digit, p.name, p.short_name, p.name_en)),
+        (SELECT p.code FROM migration_prefectures p
+         WHERE events.prefecture LIKE p.name || '%')
+    )
+WHERE prefecture IS NOT NULL;
+
+UPDATE events
+SET legacy_prefecture = COALESCE(legacy_prefecture, ''),
+    prefecture = (SELECT p.code FROM migration_prefectures p WHERE events.venue LIKE p.name || '%')
+WHERE prefecture IS NULL
+  AND EXISTS (SELECT 1 FROM migration_prefectures p WHERE events.venue LIKE p.name || '%');
+
+UPDATE events SET legacy_prefecture = NULL WHERE legacy_prefecture = prefecture;
+
+DROP TABLE migration_prefectures;
+
+CREATE TABLE migration_event_types (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    name_en TEXT NOT NULL
+);
+
+INSERT INTO migration_event_types (id, name, name_en) VALUES
+    ('lecture', '講演会', 'lecture'),
+    ('seminar', 'セミナー', 'seminar'),
+    ('workshop', 'ワークショップ', 'workshop'),
+    ('symposium', 'シンポジウム', 'symposium'),
+    ('exhibition', '展示', 'exhibition'),
+    ('competition', 'コンテスト', 'competition'),
+    ('hands_on', '体験教室', 'hands-on class'),
+    ('other', 'その他', 'other');
+
+UPDATE events
+SET legacy_event_type = event_type,
+    event_type = (SELECT t.id FROM migration_event_types t
+                  WHERE LOWER(TRIM(events.event_type)) IN (t.id, t.name, t.name_en))
+WHERE event_type IS NOT NULL;
+
+UPDATE events SET legacy_event_type = NULL WHERE legacy_event_type = event_type;
+
+DROP TABLE migration_event_types;
+
+-- +goose Down
+UPDATE events SET prefecture = NULLIF(legacy_prefecture, '') WHERE legacy_prefecture IS NOT NULL;
+UPDATE events SET event_type = legacy_event_type WHERE legacy_event_type IS NOT NULL;
+
+ALTER TABLE events DROP COLUMN legacy_prefecture;
+ALTER TABLE events DROP COLUMN legacy_event_type;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- MySQLと同じ規則で、is_online / is_offline と legacy_event_type から開催形態を決める
+-- SQLiteでは NOT NULL の列を追加するのに既定値が要る
+ALTER TABLE events ADD COLUMN delivery_mode TEXT NOT NULL DEFAULT 'offline';
+
+UPDATE events
+SET delivery_mode = CASE
+        WHEN (is_online OR LOWER(TRIM(legacy_event_type)) IN ('online', 'オンライン', 'hybrid', 'ハイブリッド'))
+         AND (is_offline OR LOWER(TRIM(legacy_event_type)) IN ('offline', 'オフライン', 'in person', 'hybrid', 'ハイブリッド'))
+            THEN 'hybrid'
+        WHEN is_online OR LOWER(TRIM(legacy_event_type)) IN ('online', 'オンライン') THEN 'online'
+        WHEN is_offline OR LOWER(TRIM(legacy_event_type)) IN ('offline', 'オフライン', 'in person') THEN 'offline'
+        WHEN online_lecture_url IS NOT NULL AND online_lecture_url <> '' THEN 'online'
+        ELSE 'offline'
+    END;
+
+UPDATE events
+SET is_online = delivery_mode IN ('online', 'hybrid'),
+    is_offline = delivery_mode IN ('offline', 'hybrid');
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN delivery_mode;
@@ -0,0 +1,34 @@
+package authcode
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
+
+// Generate は新しい認証コードと、DBに保存するためのハッシュを生成します。
+// 平文のコードは呼び出し元で利用者に渡し、DBにはハッシュのみを保存します。
+func Generate() (code uuid.UUID, hash string) {
+	code = uuid.New()
+
+	return code, Hash(code)
+}
+
+// Hash は認証コードのSHA-256ハッシュを16進文字列で返します。
+// コードはランダムなUUIDで十分なエントロピーを持つため、ソルトやストレッチングは行いません。
+func Hash(code uuid.UUID) string {
+	sum := sha256.Sum256([]byte(code.String()))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal は保存済みのハッシュと認証コードが一致するかを定数時間で比較します。
+func Equal(hash string, code uuid.UUID) bool {
+	if hash == "" || code == uuid.Nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(Hash(code))) == 1
+}
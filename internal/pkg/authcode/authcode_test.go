@@ -0,0 +1,35 @@
+package authcode_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/authcode"
+)
+
+func TestGenerate(t *testing.T) {
+	code, hash := authcode.Generate()
+
+	require.NotEqual(t, uuid.Nil, code)
+	require.Len(t, hash, 64)
+	require.NotContains(t, hash, code.String(), "hash must not contain the plaintext code")
+	require.True(t, authcode.Equal(hash, code))
+}
+
+func TestEqual(t *testing.T) {
+	code, hash := authcode.Generate()
+	other, _ := authcode.Generate()
+
+	require.True(t, authcode.Equal(hash, code))
+	require.False(t, authcode.Equal(hash, other))
+	require.False(t, authcode.Equal("", code))
+	require.False(t, authcode.Equal(hash, uuid.Nil))
+}
+
+func TestHash_MatchesMigrationBackfill(t *testing.T) {
+	// 5_hash_auth_codes.sql の SHA2(auth_code, 256) と同じ値になること
+	code := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	require.Equal(t, "bafde89c041e1756082b933aaf16cad8e65dec48de748479352f657e89dd6da5", authcode.Hash(code))
+}
@@ -0,0 +1,125 @@
+// Package cache はレスポンスなどを一時的に保存するキャッシュを抽象化します。
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache はキーを指定してバイト列を保存・取得します。
+// 複数のサーバーでキャッシュを共有する場合に Redis 互換のサーバーで実装できるよう、
+// 操作は GET / SET (EX) / INCR に対応するものだけにしています。
+type Cache interface {
+	// Get は key の値を返します。値がないか期限切れの場合は ok が false になります。
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set は key に value を保存します。ttl が0以下の場合は期限なしで保存します。
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr は key の整数値を1増やして返します。key がない場合は0から数えます。
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Memory はプロセス内に値を保持する Cache です。
+// 上限を超えた値は使われていない順に捨てますが、Incr のカウンタは捨てません。
+type Memory struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List
+	entries  map[string]*list.Element
+	counters map[string]int64
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+var _ Cache = (*Memory)(nil)
+
+// NewMemory は最大 size 件の値を保持する Memory を作成します。
+func NewMemory(size int) *Memory {
+	return &Memory{
+		size:     size,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		counters: make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n, ok := m.counters[key]; ok {
+		return []byte(strconv.FormatInt(n, 10)), true, nil
+	}
+
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = m.now().Add(ttl)
+	}
+	delete(m.counters, key)
+
+	if el, ok := m.entries[key]; ok {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (m *Memory) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := m.counters[key]
+	if el, ok := m.entries[key]; ok {
+		// Set で保存した数値も Redis と同じように数えられる
+		entry := el.Value.(*memoryEntry)
+		if entry.expiresAt.IsZero() || m.now().Before(entry.expiresAt) {
+			v, err := strconv.ParseInt(string(entry.value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("cache: value of %q is not an integer", key)
+			}
+			n = v
+		}
+		m.order.Remove(el)
+		delete(m.entries, key)
+	}
+
+	n++
+	m.counters[key] = n
+	return n, nil
+}
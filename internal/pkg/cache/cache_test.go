@@ -0,0 +1,83 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/cache"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("有効期限を過ぎた値は返さない", func(t *testing.T) {
+		m := cache.NewMemory(10)
+		now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+		m.SetNowForTest(func() time.Time { return now })
+
+		require.NoError(t, m.Set(ctx, "a", []byte("1"), time.Minute))
+		require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+
+		v, ok, err := m.Get(ctx, "a")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1", string(v))
+
+		now = now.Add(time.Minute)
+		_, ok, err = m.Get(ctx, "a")
+		require.NoError(t, err)
+		require.False(t, ok)
+		_, ok, err = m.Get(ctx, "b")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("上限を超えると使われていない値から捨てる", func(t *testing.T) {
+		m := cache.NewMemory(2)
+		require.NoError(t, m.Set(ctx, "a", []byte("1"), 0))
+		require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+		_, _, _ = m.Get(ctx, "a")
+		require.NoError(t, m.Set(ctx, "c", []byte("3"), 0))
+
+		_, ok, _ := m.Get(ctx, "b")
+		require.False(t, ok)
+		_, ok, _ = m.Get(ctx, "a")
+		require.True(t, ok)
+		_, ok, _ = m.Get(ctx, "c")
+		require.True(t, ok)
+	})
+
+	t.Run("カウンタは捨てずに数え続ける", func(t *testing.T) {
+		m := cache.NewMemory(1)
+		n, err := m.Incr(ctx, "version")
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		require.NoError(t, m.Set(ctx, "a", []byte("1"), 0))
+		require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+
+		v, ok, err := m.Get(ctx, "version")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1", string(v))
+
+		n, err = m.Incr(ctx, "version")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+	})
+
+	t.Run("数値でない値は数えられない", func(t *testing.T) {
+		m := cache.NewMemory(10)
+		require.NoError(t, m.Set(ctx, "a", []byte("x"), 0))
+		_, err := m.Incr(ctx, "a")
+		require.Error(t, err)
+
+		require.NoError(t, m.Set(ctx, "b", []byte("41"), 0))
+		n, err := m.Incr(ctx, "b")
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+	})
+}
@@ -0,0 +1,8 @@
+package cache
+
+import "time"
+
+// SetNowForTest は有効期限の判定に使う現在時刻を差し替えます。
+func (m *Memory) SetNowForTest(now func() time.Time) {
+	m.now = now
+}
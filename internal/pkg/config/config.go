@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
@@ -16,10 +18,54 @@ func getEnv(key, defaultValue string) string {
 	return v
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	n, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+
+	return n
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+
+	return d
+}
+
 func AppAddr() string {
 	return getEnv("APP_ADDR", ":8080")
 }
 
+// ServerConfig はHTTPサーバーのタイムアウトと終了処理の設定です。
+type ServerConfig struct {
+	// ReadTimeout はリクエストをボディまで読み終えるまでの時間です。画像のアップロードも収まるようにします。
+	ReadTimeout time.Duration
+	// WriteTimeout はリクエストを読み始めてからレスポンスを書き終えるまでの時間です。
+	WriteTimeout time.Duration
+	// IdleTimeout はKeep-Aliveの接続を次のリクエストまで待つ時間です。
+	IdleTimeout time.Duration
+	// ShutdownDelay は終了の合図を受けてから、/readyz を503にしたまま新しいリクエストを受け付け続ける時間です。
+	// ロードバランサーが振り分け先から外すまでの間に届いたリクエストを落とさないようにします。
+	ShutdownDelay time.Duration
+	// ShutdownTimeout は処理中のリクエストが終わるのを待つ時間です。過ぎた場合は接続を切って終了します。
+	ShutdownTimeout time.Duration
+}
+
+// Server はHTTPサーバーの設定を返します。
+func Server() ServerConfig {
+	return ServerConfig{
+		ReadTimeout:     getEnvDuration("SERVER_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:    getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:     getEnvDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
+		ShutdownDelay:   getEnvDuration("SERVER_SHUTDOWN_DELAY", 0),
+		ShutdownTimeout: getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+	}
+}
+
 func MySQL() *mysql.Config {
 	c := mysql.NewConfig()
 
@@ -38,6 +84,159 @@ func MySQL() *mysql.Config {
 	return c
 }
 
+// DatabaseConfig は接続するDBの種類と、SQLiteのファイルの場所、MySQLの接続プールの設定です。
+type DatabaseConfig struct {
+	// Driver は mysql または sqlite です。
+	Driver string
+	// SQLitePath はSQLiteのデータベースファイルです。":memory:" でメモリ上に作ります。
+	SQLitePath string
+
+	// 以下はMySQLの接続プールの設定です。SQLiteは常に接続1本で使います。
+	// MaxOpenConns はMySQLの max_connections をインスタンス数で割った値より小さくします。
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Database はDBの設定です。DB_DRIVER=sqlite にすると、MySQLなしで手元で動かせます。
+func Database() DatabaseConfig {
+	return DatabaseConfig{
+		Driver:          getEnv("DB_DRIVER", "mysql"),
+		SQLitePath:      getEnv("SQLITE_PATH", "app.db"),
+		MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		ConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", time.Minute),
+	}
+}
+
+// AppEnv は実行環境(APP_ENV)です。development / test / production のいずれかを想定します。
+// 設定がない場合は、サンプルデータの投入などを誤って行わないよう production として扱います。
+func AppEnv() string {
+	return getEnv("APP_ENV", "production")
+}
+
+// MigrationMode は起動時のマイグレーションの扱い(MIGRATION_MODE)です。
+// check はスキーマがアプリと同じバージョンでなければ起動しません。auto は起動時に未適用のマイグレーションを適用します。
+func MigrationMode() string {
+	return getEnv("MIGRATION_MODE", "check")
+}
+
+// AuthCodeTTL はイベント認証コードの有効期間です。
+func AuthCodeTTL() time.Duration {
+	return getEnvDuration("AUTH_CODE_TTL", 168*time.Hour)
+}
+
+// OnlineURLRevealWindow はオンライン講義URLを誰にでも公開し始める、開始時刻の何分前かです。
+func OnlineURLRevealWindow() time.Duration {
+	return getEnvDuration("ONLINE_URL_REVEAL_WINDOW", 30*time.Minute)
+}
+
+// ResponseCacheTTL は公開しているイベントの一覧・詳細・ICSのレスポンスをキャッシュする時間です。
+// イベントの登録・承認・編集で破棄しますが、CLIからの承認などは別のプロセスのため、この時間が経つまで反映されません。
+func ResponseCacheTTL() time.Duration {
+	return getEnvDuration("RESPONSE_CACHE_TTL", time.Minute)
+}
+
+// ReadinessTimeout は /readyz で依存先を確かめるのにかける時間です。
+// オーケストレーターのreadinessプローブのタイムアウトより短くします。
+func ReadinessTimeout() time.Duration {
+	return getEnvDuration("READINESS_TIMEOUT", 2*time.Second)
+}
+
+// ReadinessCheckNotifier は /readyz でSlackのWebhookに届くかも確かめるかです。
+// 確かめるたびにSlackへリクエストを送るため、既定では確かめません。
+func ReadinessCheckNotifier() bool {
+	v, err := strconv.ParseBool(getEnv("READINESS_CHECK_NOTIFIER", "false"))
+	return err == nil && v
+}
+
+// APIV1Sunset は /api/v1 の提供を終了する予定日(API_V1_SUNSET, YYYY-MM-DD)です。
+// v1のレスポンスの Sunset ヘッダで知らせます。空の場合はゼロ値を返します。
+func APIV1Sunset() time.Time {
+	t, err := time.Parse("2006-01-02", getEnv("API_V1_SUNSET", "2027-03-31"))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// SlackWebhookURL はイベントの申請やお問い合わせを通知するSlackのIncoming WebhookのURLです。
+func SlackWebhookURL() string {
+	return getEnv("SLACK_WEBHOOK_URL", "")
+}
+
+// SMTPConfig はメール送信に使うSMTPサーバーの設定です。
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+// SMTP はメール送信の設定を返します。SMTP_HOSTが未設定の場合はHostが空になります。
+func SMTP() SMTPConfig {
+	return SMTPConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getEnv("SMTP_PORT", "587"),
+		User:     getEnv("SMTP_USER", ""),
+		Password: getEnv("SMTP_PASS", ""),
+		From:     getEnv("SMTP_FROM", "noreply@mathday.example.com"),
+	}
+}
+
+// StorageConfig は画像などのアップロードファイルの保存先の設定です。
+type StorageConfig struct {
+	// Driver は "local" か "s3" です。
+	Driver   string
+	LocalDir string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PublicURL       string
+}
+
+// Storage はアップロードファイルの保存先の設定を返します。
+func Storage() StorageConfig {
+	return StorageConfig{
+		Driver:            getEnv("STORAGE_DRIVER", "local"),
+		LocalDir:          getEnv("LOCAL_STORAGE_DIR", "./uploads"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "ap-northeast-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3PublicURL:       getEnv("S3_PUBLIC_URL", ""),
+	}
+}
+
+// MaxUploadSize はアップロードを受け付ける画像1枚あたりの最大バイト数です。
+func MaxUploadSize() int64 {
+	n, err := strconv.ParseInt(getEnv("MAX_UPLOAD_SIZE", "10485760"), 10, 64)
+	if err != nil || n <= 0 {
+		return 10 << 20
+	}
+
+	return n
+}
+
+// OGPFontPath はOGP画像の描画に使うフォントファイルのパスです。空の場合は同梱のフォントを使います。
+func OGPFontPath() string {
+	return getEnv("OGP_FONT_PATH", "")
+}
+
+// OpenAPIValidateResponses はレスポンスをOpenAPIドキュメントと照合するかです。テストや開発環境で有効にします。
+func OpenAPIValidateResponses() bool {
+	v, err := strconv.ParseBool(getEnv("OPENAPI_VALIDATE_RESPONSES", "false"))
+	return err == nil && v
+}
+
 // CORE_BACKEND_URLを定義
 var CORE_BACKEND_URL = getEnv("CORE_BACKEND_URL", "http://localhost:8080")
 var CORE_FRONTEND_URL = getEnv("CORE_FRONTEND_URL", "http://localhost:3000")
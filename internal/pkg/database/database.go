@@ -0,0 +1,67 @@
+// Package database は設定に応じてMySQLまたはSQLiteに接続します。
+// SQLiteはpure GoのドライバなのでCGOもコンテナも要らず、手元での開発やテストに使えます。
+package database
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite" // SQLiteドライバ
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+)
+
+// ドライバ名です。(*sqlx.DB).DriverName() と比べてSQLの書き方を切り替えます。
+const (
+	MySQL  = "mysql"
+	SQLite = "sqlite"
+)
+
+func init() {
+	sqlx.BindDriver(SQLite, sqlx.QUESTION)
+}
+
+// Open は c.Driver に応じてDBに接続します。MySQLの場合は c の接続プールの設定を適用します。
+func Open(c config.DatabaseConfig) (*sqlx.DB, error) {
+	switch c.Driver {
+	case MySQL:
+		db, err := sqlx.Connect(MySQL, config.MySQL().FormatDSN())
+		if err != nil {
+			return nil, fmt.Errorf("connect to mysql: %w", err)
+		}
+		db.SetMaxOpenConns(c.MaxOpenConns)
+		db.SetMaxIdleConns(c.MaxIdleConns)
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+		return db, nil
+	case SQLite:
+		return OpenSQLite(c.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (mysql / sqlite)", c.Driver)
+	}
+}
+
+// OpenSQLite はSQLiteのデータベースファイルを開きます。path が ":memory:" の場合はメモリ上に作ります。
+//
+// SQLiteは SELECT ... FOR UPDATE を持たないため、接続を1本に絞って書き込みを直列にします。
+// メモリ上のDBは接続ごとに別物になるので、その意味でも1本である必要があります。
+func OpenSQLite(path string) (*sqlx.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	if path != ":memory:" {
+		q.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sqlx.Connect(SQLite, "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	return db, nil
+}
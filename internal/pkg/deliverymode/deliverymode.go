@@ -0,0 +1,70 @@
+// Package deliverymode はイベントの開催形態(オンライン・オフライン・ハイブリッド)を表します。
+package deliverymode
+
+import "fmt"
+
+// Mode は開催形態です。
+type Mode string
+
+const (
+	Online  Mode = "online"
+	Offline Mode = "offline"
+	Hybrid  Mode = "hybrid"
+)
+
+// Option はメタデータとして公開する開催形態の選択肢です。
+type Option struct {
+	ID     Mode   `json:"id"`
+	Name   string `json:"name"`
+	NameEn string `json:"nameEn"`
+}
+
+var all = []Option{
+	{ID: Offline, Name: "オフライン", NameEn: "In person"},
+	{ID: Online, Name: "オンライン", NameEn: "Online"},
+	{ID: Hybrid, Name: "ハイブリッド", NameEn: "Hybrid"},
+}
+
+// All は開催形態の一覧を返します。
+func All() []Option {
+	res := make([]Option, len(all))
+	copy(res, all)
+	return res
+}
+
+// Parse は文字列を開催形態に変換します。
+func Parse(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case Online, Offline, Hybrid:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown delivery mode %q", s)
+}
+
+// FromFlags は is_online / is_offline の組み合わせから開催形態を求めます。どちらも偽の場合は false を返します。
+func FromFlags(isOnline, isOffline bool) (Mode, bool) {
+	switch {
+	case isOnline && isOffline:
+		return Hybrid, true
+	case isOnline:
+		return Online, true
+	case isOffline:
+		return Offline, true
+	}
+	return "", false
+}
+
+// Flags は開催形態を is_online / is_offline の組み合わせに変換します。
+func (m Mode) Flags() (isOnline, isOffline bool) {
+	return m == Online || m == Hybrid, m == Offline || m == Hybrid
+}
+
+// RequiresOnlineURL はオンライン講義URLが必要な開催形態かを返します。
+func (m Mode) RequiresOnlineURL() bool {
+	return m == Online || m == Hybrid
+}
+
+// RequiresVenue は会場が必要な開催形態かを返します。
+func (m Mode) RequiresVenue() bool {
+	return m == Offline || m == Hybrid
+}
@@ -0,0 +1,32 @@
+package deliverymode_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+)
+
+func TestFlagsRoundTrip(t *testing.T) {
+	for _, opt := range deliverymode.All() {
+		online, offline := opt.ID.Flags()
+		m, ok := deliverymode.FromFlags(online, offline)
+		require.True(t, ok)
+		require.Equal(t, opt.ID, m)
+	}
+
+	_, ok := deliverymode.FromFlags(false, false)
+	require.False(t, ok)
+}
+
+func TestParse(t *testing.T) {
+	m, err := deliverymode.Parse("hybrid")
+	require.NoError(t, err)
+	require.Equal(t, deliverymode.Hybrid, m)
+	require.True(t, m.RequiresOnlineURL())
+	require.True(t, m.RequiresVenue())
+
+	_, err = deliverymode.Parse("ハイブリッド")
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Package eventtype はイベント種別の正規の一覧を提供します。
+package eventtype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EventType はイベント種別です。ID はDBとAPIで使う識別子です。
+type EventType struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	NameEn string `json:"nameEn"`
+}
+
+var all = []EventType{
+	{ID: "lecture", Name: "講演会", NameEn: "Lecture"},
+	{ID: "seminar", Name: "セミナー", NameEn: "Seminar"},
+	{ID: "workshop", Name: "ワークショップ", NameEn: "Workshop"},
+	{ID: "symposium", Name: "シンポジウム", NameEn: "Symposium"},
+	{ID: "exhibition", Name: "展示", NameEn: "Exhibition"},
+	{ID: "competition", Name: "コンテスト", NameEn: "Competition"},
+	{ID: "hands_on", Name: "体験教室", NameEn: "Hands-on class"},
+	{ID: "other", Name: "その他", NameEn: "Other"},
+}
+
+var index = func() map[string]EventType {
+	m := make(map[string]EventType, len(all)*3)
+	for _, t := range all {
+		m[t.ID] = t
+		m[t.Name] = t
+		m[strings.ToLower(t.NameEn)] = t
+	}
+	return m
+}()
+
+// All はイベント種別の一覧を返します。
+func All() []EventType {
+	res := make([]EventType, len(all))
+	copy(res, all)
+	return res
+}
+
+// Lookup はID("workshop")、日本語名("ワークショップ")、英語名("Workshop")のいずれかから種別を探します。
+func Lookup(s string) (EventType, bool) {
+	s = strings.TrimSpace(s)
+	t, ok := index[s]
+	if !ok {
+		t, ok = index[strings.ToLower(s)]
+	}
+	return t, ok
+}
+
+// Validate は ozzo-validation のルールとして、値がイベント種別として解釈できるかを検証します。
+// 空文字列や空白だけの値は未指定として扱い、保存するときに nil にします。
+func Validate(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		if p, isPtr := value.(*string); isPtr && p != nil {
+			s, ok = *p, true
+		}
+	}
+	if !ok || strings.TrimSpace(s) == "" {
+		return nil
+	}
+	if _, found := Lookup(s); !found {
+		return fmt.Errorf("must be a valid event type")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package eventtype_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/eventtype"
+)
+
+func TestLookup(t *testing.T) {
+	for _, in := range []string{"workshop", "ワークショップ", "Workshop", " WORKSHOP "} {
+		et, ok := eventtype.Lookup(in)
+		require.True(t, ok, in)
+		require.Equal(t, "workshop", et.ID, in)
+	}
+
+	// 配信形態は種別ではない
+	for _, in := range []string{"", "オンライン", "ハイブリッド"} {
+		_, ok := eventtype.Lookup(in)
+		require.False(t, ok, in)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := "講演会"
+	invalid := "オフライン"
+
+	require.NoError(t, eventtype.Validate(&valid))
+	require.NoError(t, eventtype.Validate((*string)(nil)))
+	for _, blank := range []string{"", "  "} {
+		require.NoError(t, eventtype.Validate(&blank))
+	}
+	require.Error(t, eventtype.Validate(&invalid))
+}
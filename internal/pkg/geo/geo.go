@@ -0,0 +1,92 @@
+// Package geo は会場の位置情報と距離計算を扱います。
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// earthRadiusKm は地球の平均半径(km)です。
+const earthRadiusKm = 6371.0
+
+// ErrNotFound は住所から位置を特定できなかったことを表します。
+var ErrNotFound = errors.New("geo: location not found")
+
+// Point は緯度・経度(度)です。
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Valid は緯度・経度が範囲内かを返します。
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// DistanceKm は2点間の大円距離(km)をハバーサイン公式で求めます。
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bounds は緯度・経度の矩形範囲です。
+type Bounds struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// BoundsAround は center から radiusKm 以内の点を全て含む矩形を返します。
+// DBでの絞り込みに使い、正確な距離の判定は DistanceKm で行います。
+func BoundsAround(center Point, radiusKm float64) Bounds {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	b := Bounds{
+		MinLat: math.Max(-90, center.Lat-dLat),
+		MaxLat: math.Min(90, center.Lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// 極に近い場合は経度方向の範囲を絞れない
+	if cosLat := math.Cos(radians(center.Lat)); cosLat > 1e-6 {
+		dLng := dLat / cosLat
+		if dLng < 180 {
+			b.MinLng = math.Max(-180, center.Lng-dLng)
+			b.MaxLng = math.Min(180, center.Lng+dLng)
+		}
+	}
+
+	return b
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Address はジオコーディングの入力です。
+type Address struct {
+	Prefecture string
+	Address    string
+	PostalCode string
+}
+
+// Precision は位置情報の精度です。
+type Precision string
+
+const (
+	// PrecisionExact は主催者が指定した座標です。
+	PrecisionExact Precision = "exact"
+	// PrecisionPrefecture は都道府県の代表点による近似です。
+	PrecisionPrefecture Precision = "prefecture"
+)
+
+// Geocoder は住所から位置を求めます。位置を特定できない場合は ErrNotFound を返します。
+type Geocoder interface {
+	Geocode(ctx context.Context, addr Address) (Point, Precision, error)
+}
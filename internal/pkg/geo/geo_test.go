@@ -0,0 +1,58 @@
+package geo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tokyo := geo.Point{Lat: 35.681, Lng: 139.767}
+	osaka := geo.Point{Lat: 34.702, Lng: 135.496}
+
+	require.InDelta(t, 403, geo.DistanceKm(tokyo, osaka), 5)
+	require.InDelta(t, 0, geo.DistanceKm(tokyo, tokyo), 1e-9)
+	require.InDelta(t, geo.DistanceKm(tokyo, osaka), geo.DistanceKm(osaka, tokyo), 1e-9)
+}
+
+func TestBoundsAround(t *testing.T) {
+	center := geo.Point{Lat: 35.690, Lng: 139.692}
+	b := geo.BoundsAround(center, 50)
+
+	for _, p := range []geo.Point{
+		{Lat: center.Lat + 0.44, Lng: center.Lng},
+		{Lat: center.Lat, Lng: center.Lng - 0.54},
+	} {
+		require.LessOrEqual(t, geo.DistanceKm(center, p), 50.0)
+		require.True(t, p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lng >= b.MinLng && p.Lng <= b.MaxLng, "%v should be in %v", p, b)
+	}
+
+	polar := geo.BoundsAround(geo.Point{Lat: 89.9, Lng: 0}, 100)
+	require.Equal(t, -180.0, polar.MinLng)
+	require.Equal(t, 180.0, polar.MaxLng)
+}
+
+func TestPrefectureGeocoder(t *testing.T) {
+	g, err := geo.NewPrefectureGeocoder()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	p, precision, err := g.Geocode(ctx, geo.Address{Prefecture: "大阪府"})
+	require.NoError(t, err)
+	require.Equal(t, geo.PrecisionPrefecture, precision)
+	require.InDelta(t, 34.686, p.Lat, 1e-9)
+
+	byCode, _, err := g.Geocode(ctx, geo.Address{Prefecture: "27"})
+	require.NoError(t, err)
+	require.Equal(t, p, byCode)
+
+	fromAddress, _, err := g.Geocode(ctx, geo.Address{Address: "北海道札幌市中央区北1条西6丁目"})
+	require.NoError(t, err)
+	require.InDelta(t, 43.064, fromAddress.Lat, 1e-9)
+
+	_, _, err = g.Geocode(ctx, geo.Address{Prefecture: "オンライン"})
+	require.ErrorIs(t, err, geo.ErrNotFound)
+}
@@ -0,0 +1,65 @@
+package geo
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefectureCentroidsCSV は都道府県庁所在地の座標です。
+//
+//go:embed prefecture_centroids.csv
+var prefectureCentroidsCSV string
+
+// PrefectureGeocoder は外部サービスを使わず、都道府県の代表点(県庁所在地)で位置を近似するジオコーダーです。
+type PrefectureGeocoder struct {
+	points map[string]Point
+}
+
+var _ Geocoder = (*PrefectureGeocoder)(nil)
+
+// NewPrefectureGeocoder はバイナリに埋め込んだ座標表からジオコーダーを作成します。
+func NewPrefectureGeocoder() (*PrefectureGeocoder, error) {
+	records, err := csv.NewReader(strings.NewReader(prefectureCentroidsCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read prefecture centroids: %w", err)
+	}
+
+	g := &PrefectureGeocoder{points: make(map[string]Point, len(records))}
+	for _, rec := range records[1:] {
+		lat, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse latitude of %s: %w", rec[1], err)
+		}
+		lng, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse longitude of %s: %w", rec[1], err)
+		}
+		g.points[rec[0]] = Point{Lat: lat, Lng: lng}
+		g.points[rec[1]] = Point{Lat: lat, Lng: lng}
+	}
+
+	return g, nil
+}
+
+// Geocode は都道府県(名前またはJISコード)から代表点を返します。
+// 都道府県が空の場合は住所の先頭に含まれる都道府県名を使います。
+func (g *PrefectureGeocoder) Geocode(_ context.Context, addr Address) (Point, Precision, error) {
+	if p, ok := g.points[strings.TrimSpace(addr.Prefecture)]; ok {
+		return p, PrecisionPrefecture, nil
+	}
+
+	for name, p := range g.points {
+		if _, err := strconv.Atoi(name); err == nil {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(addr.Address), name) {
+			return p, PrecisionPrefecture, nil
+		}
+	}
+
+	return Point{}, "", ErrNotFound
+}
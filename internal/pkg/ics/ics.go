@@ -0,0 +1,158 @@
+// Package ics はiCalendar(RFC 5545)形式のカレンダーを書き出します。
+//
+// 日時はすべて日本標準時(Asia/Tokyo)のローカル時刻として出力します。
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TZID は出力する日時のタイムゾーンです。
+const TZID = "Asia/Tokyo"
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	// maxLineOctets は折り返し前の1行の最大オクテット数です(RFC 5545 3.1)。
+	maxLineOctets = 75
+)
+
+// Event はVEVENT1件分です。Start/End はタイムゾーンを持たない日本時間として扱います。
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// RRule は "RRULE:" を除いた値部分です。
+	RRule   string
+	RDates  []time.Time
+	ExDates []time.Time
+	// RecurrenceID が設定されている場合、このVEVENTはシリーズ内の1回分の上書きを表します。
+	RecurrenceID *time.Time
+	Cancelled    bool
+}
+
+// Calendar はVCALENDARです。
+type Calendar struct {
+	Name   string
+	Events []Event
+	// Stamp はDTSTAMPに使う時刻です。ゼロ値の場合は現在時刻を使います。
+	Stamp time.Time
+}
+
+// Encode はカレンダーをiCalendar形式で w に書き出します。
+func (c Calendar) Encode(w io.Writer) error {
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Math Day//Events//JA")
+	line("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	line("X-WR-TIMEZONE", TZID)
+
+	line("BEGIN", "VTIMEZONE")
+	line("TZID", TZID)
+	line("BEGIN", "STANDARD")
+	line("DTSTART", "19700101T000000")
+	line("TZOFFSETFROM", "+0900")
+	line("TZOFFSETTO", "+0900")
+	line("TZNAME", "JST")
+	line("END", "STANDARD")
+	line("END", "VTIMEZONE")
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp.UTC().Format(dateTimeLayout)+"Z")
+		if e.RecurrenceID != nil {
+			line("RECURRENCE-ID;TZID="+TZID, e.RecurrenceID.Format(dateTimeLayout))
+		}
+		line("DTSTART;TZID="+TZID, e.Start.Format(dateTimeLayout))
+		if !e.End.IsZero() {
+			line("DTEND;TZID="+TZID, e.End.Format(dateTimeLayout))
+		}
+		if e.RRule != "" {
+			line("RRULE", e.RRule)
+		}
+		if len(e.RDates) > 0 {
+			line("RDATE;TZID="+TZID, joinTimes(e.RDates, dateTimeLayout))
+		}
+		if len(e.ExDates) > 0 {
+			line("EXDATE;TZID="+TZID, joinTimes(e.ExDates, dateTimeLayout))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Cancelled {
+			line("STATUS", "CANCELLED")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+func joinTimes(ts []time.Time, layout string) string {
+	values := make([]string, len(ts))
+	for i, t := range ts {
+		values[i] = t.Format(layout)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// escapeText はTEXT型の値をエスケープします(RFC 5545 3.3.11)。
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeFolded は1行を75オクテットごとに折り返して書き出します。マルチバイト文字の途中では折り返しません。
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// 継続行は先頭の空白1オクテットを含めて75オクテット
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,72 @@
+package ics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/ics"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	start := time.Date(2025, 1, 11, 13, 0, 0, 0, time.UTC)
+	override := time.Date(2025, 2, 8, 13, 0, 0, 0, time.UTC)
+	cal := ics.Calendar{
+		Name:  "Math Day",
+		Stamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Events: []ics.Event{
+			{
+				UID:     "event-1@mathday",
+				Summary: "月例セミナー; 代数, 幾何",
+				Start:   start,
+				End:     start.Add(2 * time.Hour),
+				RRule:   "FREQ=MONTHLY;BYDAY=2SA",
+				ExDates: []time.Time{time.Date(2025, 3, 8, 13, 0, 0, 0, time.UTC)},
+			},
+			{
+				UID:          "event-1@mathday",
+				Summary:      "月例セミナー(会場変更)",
+				Start:        override.Add(time.Hour),
+				End:          override.Add(3 * time.Hour),
+				RecurrenceID: &override,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+	out := buf.String()
+
+	require.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	require.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	require.Contains(t, out, "DTSTART;TZID=Asia/Tokyo:20250111T130000\r\n")
+	require.Contains(t, out, "RRULE:FREQ=MONTHLY;BYDAY=2SA\r\n")
+	require.Contains(t, out, "EXDATE;TZID=Asia/Tokyo:20250308T130000\r\n")
+	require.Contains(t, out, "RECURRENCE-ID;TZID=Asia/Tokyo:20250208T130000\r\n")
+	require.Contains(t, out, `SUMMARY:月例セミナー\; 代数\, 幾何`)
+	require.Contains(t, out, "DTSTAMP:20250101T000000Z\r\n")
+}
+
+func TestCalendar_EncodeFoldsLongLines(t *testing.T) {
+	cal := ics.Calendar{
+		Events: []ics.Event{{
+			UID:         "event-2@mathday",
+			Summary:     "long",
+			Description: strings.Repeat("数学", 100),
+			Start:       time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+
+	for _, l := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(l), 75, "line %q", l)
+		require.True(t, strings.ToValidUTF8(l, "") == l, "line must not split a multi-byte character")
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	require.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("数学", 100)+"\r\n")
+}
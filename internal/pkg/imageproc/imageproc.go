@@ -0,0 +1,151 @@
+// Package imageproc はアップロードされた画像の検証と縮小を行います。
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // GIFのデコーダを登録
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebPのデコーダを登録
+)
+
+const (
+	// MaxPixels はデコードを許可する画像の最大画素数です(展開後のメモリ消費を抑えるため)。
+	MaxPixels = 40_000_000
+	// MainSize は表示用画像の長辺の最大ピクセル数です。
+	MainSize = 1600
+	// ThumbnailSize はサムネイルの長辺の最大ピクセル数です。
+	ThumbnailSize = 400
+)
+
+var (
+	ErrTooLarge        = errors.New("image is too large")
+	ErrUnsupportedType = errors.New("unsupported image type")
+)
+
+// allowedTypes はアップロードを許可する画像形式です。
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Image はエンコード済みの画像です。
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Ext はContent-Typeに対応する拡張子を返します。
+func (img Image) Ext() string {
+	if img.ContentType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// Result は Process の結果です。
+type Result struct {
+	Main      Image
+	Thumbnail Image
+}
+
+// Process は r から最大 maxBytes バイトを読み込み、中身から形式を判定してデコードし、
+// 表示用画像とサムネイルを作成します。PNGはPNGのまま、それ以外はJPEGで出力します。
+// 再エンコードするので、EXIFなどのメタデータは取り除かれます。
+func Process(r io.Reader, maxBytes int64) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	outType := "image/jpeg"
+	if contentType == "image/png" {
+		outType = "image/png"
+	}
+
+	main, err := encode(resize(src, MainSize), outType)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := encode(resize(src, ThumbnailSize), outType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Main: main, Thumbnail: thumb}, nil
+}
+
+// resize は長辺が maxSize 以下になるよう縦横比を保って縮小します。拡大はしません。
+func resize(src image.Image, maxSize int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return src
+	}
+
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+func encode(img image.Image, contentType string) (Image, error) {
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/png":
+		if err := png.Encode(&buf, img); err != nil {
+			return Image{}, fmt.Errorf("encode png: %w", err)
+		}
+	default:
+		// JPEGは透過を持てないので白背景に合成する
+		b := img.Bounds()
+		rgba := image.NewRGBA(b)
+		draw.Draw(rgba, b, image.White, image.Point{}, draw.Src)
+		draw.Draw(rgba, b, img, b.Min, draw.Over)
+		if err := jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: 85}); err != nil {
+			return Image{}, fmt.Errorf("encode jpeg: %w", err)
+		}
+	}
+
+	b := img.Bounds()
+	return Image{Data: buf.Bytes(), ContentType: contentType, Width: b.Dx(), Height: b.Dy()}, nil
+}
@@ -0,0 +1,80 @@
+package imageproc_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/imageproc"
+)
+
+func newImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestProcess_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newImage(2000, 1000)))
+
+	res, err := imageproc.Process(&buf, 10<<20)
+	require.NoError(t, err)
+
+	require.Equal(t, "image/png", res.Main.ContentType)
+	require.Equal(t, ".png", res.Main.Ext())
+	require.Equal(t, imageproc.MainSize, res.Main.Width)
+	require.Equal(t, 800, res.Main.Height)
+	require.Equal(t, imageproc.ThumbnailSize, res.Thumbnail.Width)
+	require.Equal(t, 200, res.Thumbnail.Height)
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(res.Thumbnail.Data))
+	require.NoError(t, err)
+	require.Equal(t, 400, cfg.Width)
+}
+
+func TestProcess_ConvertsToJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, newImage(300, 600), nil))
+
+	res, err := imageproc.Process(&buf, 10<<20)
+	require.NoError(t, err)
+
+	// 小さい画像は拡大しない
+	require.Equal(t, "image/jpeg", res.Main.ContentType)
+	require.Equal(t, ".jpg", res.Main.Ext())
+	require.Equal(t, 300, res.Main.Width)
+	require.Equal(t, 600, res.Main.Height)
+	require.Equal(t, 200, res.Thumbnail.Width)
+	require.Equal(t, 400, res.Thumbnail.Height)
+
+	_, err = jpeg.DecodeConfig(bytes.NewReader(res.Main.Data))
+	require.NoError(t, err)
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	_, err := imageproc.Process(strings.NewReader("<svg xmlns='http://www.w3.org/2000/svg'></svg>"), 10<<20)
+	require.ErrorIs(t, err, imageproc.ErrUnsupportedType)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newImage(100, 100)))
+	_, err = imageproc.Process(&buf, 100)
+	require.ErrorIs(t, err, imageproc.ErrTooLarge)
+
+	// ヘッダー上の画素数が大きすぎる画像はデコードしない
+	buf.Reset()
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 10000, 5000))))
+	_, err = imageproc.Process(&buf, 10<<20)
+	require.ErrorIs(t, err, imageproc.ErrTooLarge)
+}
@@ -0,0 +1,53 @@
+// Package mail はSMTPでのメール送信を扱います。
+package mail
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+)
+
+// SMTP はSMTPサーバー経由でテキストメールを送信します。
+type SMTP struct {
+	config config.SMTPConfig
+}
+
+// NewSMTP は c のサーバーを使う SMTP を作成します。c.Host が空の場合、Send は常にエラーを返します。
+func NewSMTP(c config.SMTPConfig) *SMTP {
+	return &SMTP{config: c}
+}
+
+// Send は to にテキストメールを送信します。
+func (s *SMTP) Send(to, subject, body string) error {
+	c := s.config
+	if c.Host == "" {
+		return fmt.Errorf("SMTP_HOSTが設定されていません")
+	}
+
+	var auth smtp.Auth
+	if c.User != "" {
+		auth = smtp.PlainAuth("", c.User, c.Password, c.Host)
+	}
+
+	msg := buildMessage(c.From, to, subject, body)
+	if err := smtp.SendMail(net.JoinHostPort(c.Host, c.Port), auth, c.From, []string{to}, msg); err != nil {
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+
+	return nil
+}
+
+func buildMessage(from, to, subject, body string) []byte {
+	return []byte(strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n"))
+}
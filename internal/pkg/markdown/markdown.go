@@ -0,0 +1,58 @@
+// Package markdown はイベント説明文のMarkdownを安全なHTMLに変換します。
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, mathExtension{}),
+	goldmark.WithRendererOptions(
+		// 従来のプレーンテキストの説明文と同じく、改行をそのまま改行として表示する
+		html.WithHardWraps(),
+	),
+)
+
+// policy は出力を許可するHTMLの要素・属性の一覧です。
+// goldmark は生のHTMLを出力しない設定ですが、リンクのURLなども含めて最終的にここで検査します。
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math math-(inline|display)$`)).OnElements("span")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// ToHTML は src をMarkdown(GFM)として解釈し、サニタイズ済みのHTMLを返します。
+// $...$ と $$...$$ で囲んだ数式は変換せず、class="math math-inline" / "math math-display" の
+// span に元の記法のまま出力するので、フロントエンドでKaTeXなどを使って描画してください。
+func ToHTML(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", fmt.Errorf("convert markdown: %w", err)
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+type mathExtension struct{}
+
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(&mathParser{}, 150),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&mathRenderer{}, 150),
+	))
+}
@@ -0,0 +1,83 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/markdown"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "markdown",
+			src:  "## 概要\n**素数**について話します。\n次の行",
+			want: "<h2>概要</h2>\n<p><strong>素数</strong>について話します。<br>\n次の行</p>\n",
+		},
+		{
+			name: "inline math is passed through",
+			src:  "オイラーの等式 $e^{i\\pi} + 1 = 0$ と $a_1 * b_2 < c$",
+			want: `<p>オイラーの等式 <span class="math math-inline">$e^{i\pi} + 1 = 0$</span> と <span class="math math-inline">$a_1 * b_2 &lt; c$</span></p>` + "\n",
+		},
+		{
+			name: "display math",
+			src:  "$$\\sum_{n=1}^\\infty \\frac{1}{n^2} = \\frac{\\pi^2}{6}$$",
+			want: `<p><span class="math math-display">$$\sum_{n=1}^\infty \frac{1}{n^2} = \frac{\pi^2}{6}$$</span></p>` + "\n",
+		},
+		{
+			name: "prices are not math",
+			src:  "参加費は $5 と $10 です",
+			want: "<p>参加費は $5 と $10 です</p>\n",
+		},
+		{
+			name: "raw html is removed",
+			src:  "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			want: "\n\n",
+		},
+		{
+			name: "javascript links are removed",
+			src:  "[click](javascript:alert(1))",
+			want: "<p>click</p>\n",
+		},
+		{
+			name: "external links",
+			src:  "https://example.com",
+			want: `<p><a href="https://example.com" rel="nofollow noopener" target="_blank">https://example.com</a></p>` + "\n",
+		},
+		{
+			name: "an unclosed dollar does not hide math in the next paragraph",
+			src:  "$5 の会費\n\n$x$ と $$y$$",
+			want: `<p>$5 の会費</p>` + "\n" + `<p><span class="math math-inline">$x$</span> と <span class="math math-display">$$y$$</span></p>` + "\n",
+		},
+		{
+			name: "math cannot inject html",
+			src:  `$<img src=x onerror=alert(1)>$`,
+			want: `<p><span class="math math-inline">$&lt;img src=x onerror=alert(1)&gt;$</span></p>` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := markdown.ToHTML(tt.src)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestToHTML_UnclosedDollars は、閉じない $ が多い説明文でも変換に時間がかからないことを確かめます。
+func TestToHTML_UnclosedDollars(t *testing.T) {
+	for _, dollar := range []string{"$a ", "$$a "} {
+		src := strings.Repeat(dollar, 40000)
+		start := time.Now()
+		_, err := markdown.ToHTML(src)
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), time.Second, "%q", dollar)
+	}
+}
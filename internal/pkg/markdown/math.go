@@ -0,0 +1,131 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// mathKind は数式のノードの種類です。
+var mathKind = ast.NewNodeKind("Math")
+
+// mathNode は $...$ (Display=false) または $$...$$ (Display=true) で囲まれた数式です。
+// Value には区切り記号を除いたTeXのソースがそのまま入ります。
+type mathNode struct {
+	ast.BaseInline
+	Display bool
+	Value   []byte
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return mathKind
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.Value)}, nil)
+}
+
+// mathParser は数式を、強調やエスケープなどのMarkdownの解釈をせずに取り出します。
+// 「$5 と $10」のような金額を数式と誤認しないよう、Pandocと同じく
+// 開始の $ の直後と終了の $ の直前が空白でなく、終了の $ の直後が数字でないものだけを数式とします。
+type mathParser struct{}
+
+// unclosedKeys は、終了の区切り記号が見つからなかった範囲を区切り記号の長さ(1か2)ごとに覚えておくキーです。
+// その範囲の中の $ からブロックの最後まで探し直さないようにし、閉じない $ が多い文書でも線形時間で解析します。
+var unclosedKeys = [3]parser.ContextKey{1: parser.NewContextKey(), 2: parser.NewContextKey()}
+
+// unclosedRange はソース上の [start, stop) に、終了の区切り記号がないことを表します。
+type unclosedRange struct {
+	start, stop int
+}
+
+func (p *mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = 2
+	}
+	if len(line) <= delim || util.IsSpace(line[delim]) {
+		return nil
+	}
+	// 前に探した $ が閉じなかった範囲では、後ろの $ も閉じない
+	_, segment := block.PeekLine()
+	if r, ok := pc.Get(unclosedKeys[delim]).(unclosedRange); ok && r.start <= segment.Start && segment.Start < r.stop {
+		return nil
+	}
+
+	l, pos := block.Position()
+	block.Advance(delim)
+
+	var value bytes.Buffer
+	var stop int
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			block.SetPosition(l, pos)
+			_, start := block.PeekLine()
+			pc.Set(unclosedKeys[delim], unclosedRange{start: start.Start, stop: stop})
+			return nil
+		}
+		stop = segment.Stop
+
+		for i := 0; i < len(line); i++ {
+			switch {
+			case line[i] == '\\':
+				i++
+			case line[i] == '$' && closes(line, i, delim):
+				value.Write(line[:i])
+				if value.Len() == 0 {
+					block.SetPosition(l, pos)
+					return nil
+				}
+				block.Advance(i + delim)
+				return &mathNode{Display: delim == 2, Value: value.Bytes()}
+			}
+		}
+
+		value.Write(line)
+		block.AdvanceLine()
+	}
+}
+
+func closes(line []byte, i, delim int) bool {
+	if delim == 2 {
+		return i+1 < len(line) && line[i+1] == '$'
+	}
+	if i == 0 || util.IsSpace(line[i-1]) {
+		return false
+	}
+	return i+1 >= len(line) || !('0' <= line[i+1] && line[i+1] <= '9')
+}
+
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(mathKind, r.render)
+}
+
+func (r *mathRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*mathNode)
+	delim, class := "$", "math math-inline"
+	if n.Display {
+		delim, class = "$$", "math math-display"
+	}
+
+	_, _ = w.WriteString(`<span class="` + class + `">` + delim)
+	_, _ = w.Write(util.EscapeHTML(n.Value))
+	_, _ = w.WriteString(delim + `</span>`)
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,77 @@
+package ogp
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache はイベントごとに最後に生成したOGP画像を保持します。
+// 画像は Card.Hash と一緒に保存し、ハッシュが変わった(イベントが編集された)場合は作り直させます。
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[int]*list.Element
+}
+
+type cacheEntry struct {
+	id   int
+	hash string
+	png  []byte
+}
+
+// NewCache は最大 size 件を保持する Cache を作成します。
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[int]*list.Element),
+	}
+}
+
+// Get は id の画像が hash の内容で生成されたものであれば返します。
+func (c *Cache) Get(id int, hash string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if entry.hash != hash {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.png, true
+}
+
+// Put は id の画像を保存します。古い画像は置き換えられ、上限を超えた分は使われていない順に捨てます。
+func (c *Cache) Put(id int, hash string, png []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		el.Value = &cacheEntry{id: id, hash: hash, png: png}
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(&cacheEntry{id: id, hash: hash, png: png})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).id)
+	}
+}
+
+// Delete は id の画像を捨てます。
+func (c *Cache) Delete(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		c.order.Remove(el)
+		delete(c.entries, id)
+	}
+}
@@ -0,0 +1,237 @@
+// Package ogp はイベントをSNSで共有したときに表示されるOGP画像(1200x630のPNG)を生成します。
+package ogp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	Width  = 1200
+	Height = 630
+
+	// layoutVersion はレイアウトを変えたときに上げ、キャッシュ済みの画像を作り直させます。
+	layoutVersion = "1"
+
+	padding      = 72
+	titleSize    = 64
+	titleLines   = 3
+	detailSize   = 36
+	siteNameSize = 32
+)
+
+//go:embed fonts/mplus-1p-regular.ttf
+var defaultFont []byte
+
+var (
+	backgroundColor = color.RGBA{R: 0xFA, G: 0xFA, B: 0xF7, A: 0xFF}
+	accentColor     = color.RGBA{R: 0x1F, G: 0x4E, B: 0x8C, A: 0xFF}
+	textColor       = color.RGBA{R: 0x1A, G: 0x1A, B: 0x1A, A: 0xFF}
+	subTextColor    = color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xFF}
+)
+
+// Card はOGP画像に載せる内容です。
+type Card struct {
+	SiteName   string
+	Title      string
+	Date       string
+	Organizer  string
+	Prefecture string
+}
+
+// Hash は Card の内容とレイアウトから決まるハッシュです。内容が変われば値も変わるので、
+// キャッシュのキーやETagに使えます。
+func (c Card) Hash() string {
+	h := sha256.New()
+	for _, s := range []string{layoutVersion, c.SiteName, c.Title, c.Date, c.Organizer, c.Prefecture} {
+		fmt.Fprintf(h, "%d:%s;", len(s), s)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Renderer はフォントを読み込んだ状態でOGP画像を描画します。並行して使用できます。
+type Renderer struct {
+	font *opentype.Font
+}
+
+// NewRenderer は fontData (TrueType/OpenType) を使う Renderer を作成します。
+// fontData が空の場合は同梱の日本語フォント(M+ 1p)を使います。
+func NewRenderer(fontData []byte) (*Renderer, error) {
+	if len(fontData) == 0 {
+		fontData = defaultFont
+	}
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+	return &Renderer{font: f}, nil
+}
+
+func (r *Renderer) face(size float64) (font.Face, error) {
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create font face: %w", err)
+	}
+	return face, nil
+}
+
+// Render は card を描画したPNGを返します。
+func (r *Renderer) Render(card Card) ([]byte, error) {
+	titleFace, err := r.face(titleSize)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	detailFace, err := r.face(detailSize)
+	if err != nil {
+		return nil, err
+	}
+	defer detailFace.Close()
+	siteFace, err := r.face(siteNameSize)
+	if err != nil {
+		return nil, err
+	}
+	defer siteFace.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, 16), image.NewUniform(accentColor), image.Point{}, draw.Src)
+
+	maxWidth := fixed.I(Width - padding*2)
+
+	// タイトル(太字の代わりに1px ずらして重ね描きする)
+	y := padding + titleSize
+	for _, line := range wrap(titleFace, card.Title, maxWidth, titleLines) {
+		drawText(img, titleFace, textColor, padding, y, line)
+		drawText(img, titleFace, textColor, padding+1, y, line)
+		y += titleSize * 5 / 4
+	}
+
+	// 開催日時・主催者・開催地は下から積み上げる
+	y = Height - padding - siteNameSize - 40
+	details := []string{}
+	if card.Prefecture != "" {
+		details = append(details, card.Prefecture)
+	}
+	if card.Organizer != "" {
+		details = append(details, "主催: "+card.Organizer)
+	}
+	if card.Date != "" {
+		details = append(details, card.Date)
+	}
+	for _, line := range details {
+		drawText(img, detailFace, subTextColor, padding, y, truncate(detailFace, line, maxWidth))
+		y -= detailSize * 3 / 2
+	}
+
+	if card.SiteName != "" {
+		draw.Draw(img, image.Rect(padding, Height-padding-siteNameSize-12, Width-padding, Height-padding-siteNameSize-10),
+			image.NewUniform(accentColor), image.Point{}, draw.Src)
+		drawText(img, siteFace, accentColor, padding, Height-padding, card.SiteName)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// noLineStart は行頭に置かない文字(禁則文字)です。
+const noLineStart = "、。，．,.)]）」』】〕ー・ぁぃぅぇぉっゃゅょァィゥェォッャュョ!?！？"
+
+// wrap は s を幅 maxWidth で折り返し、最大 maxLines 行に収めます。
+// 日本語は単語の区切りがないので1文字ずつ折り返し、収まらない場合は末尾を「…」にします。
+func wrap(face font.Face, s string, maxWidth fixed.Int26_6, maxLines int) []string {
+	s = strings.Join(strings.Fields(s), " ")
+	var lines []string
+	for s != "" {
+		if len(lines) == maxLines-1 {
+			return append(lines, truncate(face, s, maxWidth))
+		}
+
+		n := fit(face, s, maxWidth)
+		// 禁則文字が次の行頭に来る場合は前の行に含める
+		for n < len(s) {
+			r, size := utf8.DecodeRuneInString(s[n:])
+			if !strings.ContainsRune(noLineStart, r) {
+				break
+			}
+			n += size
+		}
+		// ASCIIの単語の途中では折り返さない
+		if n < len(s) && isWordByte(s[n]) {
+			if i := strings.LastIndexFunc(s[:n], func(r rune) bool { return r == ' ' || r >= utf8.RuneSelf }); i > 0 {
+				_, size := utf8.DecodeRuneInString(s[i:])
+				if s[i] == ' ' {
+					n = i
+				} else {
+					n = i + size
+				}
+			}
+		}
+
+		lines = append(lines, strings.TrimRight(s[:n], " "))
+		s = strings.TrimLeft(s[n:], " ")
+	}
+	return lines
+}
+
+func isWordByte(b byte) bool {
+	return b < utf8.RuneSelf && b != ' '
+}
+
+// fit は s の先頭から幅 maxWidth に収まるバイト数を返します。最低1文字は含めます。
+func fit(face font.Face, s string, maxWidth fixed.Int26_6) int {
+	var width fixed.Int26_6
+	prev := rune(-1)
+	for i, r := range s {
+		if prev >= 0 {
+			width += face.Kern(prev, r)
+		}
+		adv, _ := face.GlyphAdvance(r)
+		width += adv
+		if width > maxWidth && i > 0 {
+			return i
+		}
+		prev = r
+	}
+	return len(s)
+}
+
+// truncate は s が幅 maxWidth に収まらない場合に末尾を「…」にします。
+func truncate(face font.Face, s string, maxWidth fixed.Int26_6) string {
+	if font.MeasureString(face, s) <= maxWidth {
+		return s
+	}
+	ellipsis := "…"
+	n := fit(face, s, maxWidth-font.MeasureString(face, ellipsis))
+	return strings.TrimRight(s[:n], " ") + ellipsis
+}
@@ -0,0 +1,70 @@
+package ogp_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/ogp"
+)
+
+func TestRender(t *testing.T) {
+	r, err := ogp.NewRenderer(nil)
+	require.NoError(t, err)
+
+	b, err := r.Render(ogp.Card{
+		SiteName:   "Math Day",
+		Title:      "数学の日記念講演会 〜 素数の不思議とリーマン予想、そして未解決問題への招待 〜 長いタイトルは省略されます",
+		Date:       "2025年3月14日(金) 13:00〜17:00",
+		Organizer:  "日本数学協会",
+		Prefecture: "東京都",
+	})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Equal(t, ogp.Width, img.Bounds().Dx())
+	require.Equal(t, ogp.Height, img.Bounds().Dy())
+}
+
+func TestCard_Hash(t *testing.T) {
+	card := ogp.Card{Title: "講演会", Date: "2025年3月14日", Organizer: "主催者"}
+	require.Equal(t, card.Hash(), card.Hash())
+
+	edited := card
+	edited.Title = "講演会(改)"
+	require.NotEqual(t, card.Hash(), edited.Hash())
+
+	// フィールドの境界が変わっただけでも別のハッシュになる
+	require.NotEqual(t,
+		ogp.Card{Title: "ab", Date: "c"}.Hash(),
+		ogp.Card{Title: "a", Date: "bc"}.Hash())
+}
+
+func TestCache(t *testing.T) {
+	c := ogp.NewCache(2)
+
+	c.Put(1, "h1", []byte("one"))
+	got, ok := c.Get(1, "h1")
+	require.True(t, ok)
+	require.Equal(t, []byte("one"), got)
+
+	// イベントが編集されてハッシュが変わった場合は使わない
+	_, ok = c.Get(1, "h1-edited")
+	require.False(t, ok)
+
+	c.Put(2, "h2", []byte("two"))
+	c.Get(1, "h1")
+	c.Put(3, "h3", []byte("three"))
+
+	_, ok = c.Get(2, "h2")
+	require.False(t, ok, "least recently used entry should be evicted")
+	_, ok = c.Get(1, "h1")
+	require.True(t, ok)
+
+	c.Delete(1)
+	_, ok = c.Get(1, "h1")
+	require.False(t, ok)
+}
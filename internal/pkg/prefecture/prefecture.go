@@ -0,0 +1,125 @@
+// Package prefecture は都道府県の正規の一覧(JIS X 0401 コード)を提供します。
+package prefecture
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefecture は都道府県です。Code はJIS X 0401の2桁のコードです。
+type Prefecture struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	NameEn string `json:"nameEn"`
+}
+
+var all = []Prefecture{
+	{Code: "01", Name: "北海道", NameEn: "Hokkaido"},
+	{Code: "02", Name: "青森県", NameEn: "Aomori"},
+	{Code: "03", Name: "岩手県", NameEn: "Iwate"},
+	{Code: "04", Name: "宮城県", NameEn: "Miyagi"},
+	{Code: "05", Name: "秋田県", NameEn: "Akita"},
+	{Code: "06", Name: "山形県", NameEn: "Yamagata"},
+	{Code: "07", Name: "福島県", NameEn: "Fukushima"},
+	{Code: "08", Name: "茨城県", NameEn: "Ibaraki"},
+	{Code: "09", Name: "栃木県", NameEn: "Tochigi"},
+	{Code: "10", Name: "群馬県", NameEn: "Gunma"},
+	{Code: "11", Name: "埼玉県", NameEn: "Saitama"},
+	{Code: "12", Name: "千葉県", NameEn: "Chiba"},
+	{Code: "13", Name: "東京都", NameEn: "Tokyo"},
+	{Code: "14", Name: "神奈川県", NameEn: "Kanagawa"},
+	{Code: "15", Name: "新潟県", NameEn: "Niigata"},
+	{Code: "16", Name: "富山県", NameEn: "Toyama"},
+	{Code: "17", Name: "石川県", NameEn: "Ishikawa"},
+	{Code: "18", Name: "福井県", NameEn: "Fukui"},
+	{Code: "19", Name: "山梨県", NameEn: "Yamanashi"},
+	{Code: "20", Name: "長野県", NameEn: "Nagano"},
+	{Code: "21", Name: "岐阜県", NameEn: "Gifu"},
+	{Code: "22", Name: "静岡県", NameEn: "Shizuoka"},
+	{Code: "23", Name: "愛知県", NameEn: "Aichi"},
+	{Code: "24", Name: "三重県", NameEn: "Mie"},
+	{Code: "25", Name: "滋賀県", NameEn: "Shiga"},
+	{Code: "26", Name: "京都府", NameEn: "Kyoto"},
+	{Code: "27", Name: "大阪府", NameEn: "Osaka"},
+	{Code: "28", Name: "兵庫県", NameEn: "Hyogo"},
+	{Code: "29", Name: "奈良県", NameEn: "Nara"},
+	{Code: "30", Name: "和歌山県", NameEn: "Wakayama"},
+	{Code: "31", Name: "鳥取県", NameEn: "Tottori"},
+	{Code: "32", Name: "島根県", NameEn: "Shimane"},
+	{Code: "33", Name: "岡山県", NameEn: "Okayama"},
+	{Code: "34", Name: "広島県", NameEn: "Hiroshima"},
+	{Code: "35", Name: "山口県", NameEn: "Yamaguchi"},
+	{Code: "36", Name: "徳島県", NameEn: "Tokushima"},
+	{Code: "37", Name: "香川県", NameEn: "Kagawa"},
+	{Code: "38", Name: "愛媛県", NameEn: "Ehime"},
+	{Code: "39", Name: "高知県", NameEn: "Kochi"},
+	{Code: "40", Name: "福岡県", NameEn: "Fukuoka"},
+	{Code: "41", Name: "佐賀県", NameEn: "Saga"},
+	{Code: "42", Name: "長崎県", NameEn: "Nagasaki"},
+	{Code: "43", Name: "熊本県", NameEn: "Kumamoto"},
+	{Code: "44", Name: "大分県", NameEn: "Oita"},
+	{Code: "45", Name: "宮崎県", NameEn: "Miyazaki"},
+	{Code: "46", Name: "鹿児島県", NameEn: "Kagoshima"},
+	{Code: "47", Name: "沖縄県", NameEn: "Okinawa"},
+}
+
+var index = func() map[string]Prefecture {
+	m := make(map[string]Prefecture, len(all)*4)
+	for _, p := range all {
+		m[p.Code] = p
+		m[p.Name] = p
+		m[strings.ToLower(p.NameEn)] = p
+		if short := shortName(p.Name); short != p.Name {
+			m[short] = p
+		}
+	}
+	return m
+}()
+
+// shortName は「都・府・県」を除いた呼び名(東京、大阪など)を返します。北海道はそのままです。
+func shortName(name string) string {
+	for _, suffix := range []string{"都", "府", "県"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
+
+// All はJISコード順の都道府県一覧を返します。
+func All() []Prefecture {
+	res := make([]Prefecture, len(all))
+	copy(res, all)
+	return res
+}
+
+// Lookup はJISコード("13"、"1")、日本語名("東京都"、"東京")、英語名("Tokyo")のいずれかから都道府県を探します。
+func Lookup(s string) (Prefecture, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) == 1 && s[0] >= '1' && s[0] <= '9' {
+		s = "0" + s
+	}
+	p, ok := index[s]
+	if !ok {
+		p, ok = index[strings.ToLower(s)]
+	}
+	return p, ok
+}
+
+// Validate は ozzo-validation のルールとして、値が都道府県として解釈できるかを検証します。
+// 空文字列や空白だけの値は未指定として扱い、保存するときに nil にします。
+func Validate(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		if p, isPtr := value.(*string); isPtr && p != nil {
+			s, ok = *p, true
+		}
+	}
+	if !ok || strings.TrimSpace(s) == "" {
+		return nil
+	}
+	if _, found := Lookup(s); !found {
+		return fmt.Errorf("must be a valid prefecture")
+	}
+	return nil
+}
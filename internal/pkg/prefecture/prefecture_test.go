@@ -0,0 +1,49 @@
+package prefecture_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/prefecture"
+)
+
+func TestAll(t *testing.T) {
+	all := prefecture.All()
+	require.Len(t, all, 47)
+	require.Equal(t, prefecture.Prefecture{Code: "01", Name: "北海道", NameEn: "Hokkaido"}, all[0])
+	require.Equal(t, prefecture.Prefecture{Code: "47", Name: "沖縄県", NameEn: "Okinawa"}, all[46])
+}
+
+func TestLookup(t *testing.T) {
+	for _, in := range []string{"13", "東京都", "東京", "Tokyo", "tokyo", " 東京都 "} {
+		p, ok := prefecture.Lookup(in)
+		require.True(t, ok, in)
+		require.Equal(t, "13", p.Code, in)
+	}
+
+	kyoto, ok := prefecture.Lookup("京都")
+	require.True(t, ok)
+	require.Equal(t, "26", kyoto.Code)
+
+	hokkaido, ok := prefecture.Lookup("1")
+	require.True(t, ok)
+	require.Equal(t, "北海道", hokkaido.Name)
+
+	for _, in := range []string{"", "オンライン", "大阪府（オンラインでも参加可）", "48"} {
+		_, ok := prefecture.Lookup(in)
+		require.False(t, ok, in)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := "大阪府"
+	invalid := "大阪市"
+
+	require.NoError(t, prefecture.Validate(&valid))
+	require.NoError(t, prefecture.Validate((*string)(nil)))
+	for _, blank := range []string{"", "  "} {
+		require.NoError(t, prefecture.Validate(&blank))
+	}
+	require.Error(t, prefecture.Validate(&invalid))
+}
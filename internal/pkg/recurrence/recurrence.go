@@ -0,0 +1,347 @@
+// Package recurrence はRFC 5545のRRULEのうち、イベントシリーズで使うサブセットを扱います。
+//
+// 対応しているのは FREQ=WEEKLY / MONTHLY と INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY です。
+// 日付リスト(RDATE)と除外日(EXDATE)は Set で扱います。
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency は繰り返しの単位です。
+type Frequency string
+
+const (
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxIterations は不正なルールで無限ループしないための上限です。
+const maxIterations = 10000
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// WeekdayNum はBYDAYの1要素です。Nは月内の第N週(負数は末尾から)で、0なら全ての週を表します。
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule はRRULEを表します。
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse はRRULE文字列("FREQ=WEEKLY;BYDAY=MO,WE" や "RRULE:FREQ=MONTHLY;BYDAY=2SA")を解析します。
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+			if r.Freq != Weekly && r.Freq != Monthly {
+				return Rule{}, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid UNTIL %q: %w", value, err)
+			}
+			r.Until = t
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(v)
+				if err != nil {
+					return Rule{}, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return Rule{}, fmt.Errorf("invalid BYMONTHDAY %q", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return Rule{}, fmt.Errorf("unsupported WKST %q", value)
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL must not both be set")
+	}
+	if r.Freq == Weekly {
+		if len(r.ByMonthDay) > 0 {
+			return Rule{}, fmt.Errorf("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+		}
+		for _, wd := range r.ByDay {
+			if wd.N != 0 {
+				return Rule{}, fmt.Errorf("numbered BYDAY is not allowed with FREQ=WEEKLY")
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func parseUntil(v string) (time.Time, error) {
+	v = strings.TrimSuffix(v, "Z")
+	if len(v) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		// 日付のみのUNTILはその日の終わりまでを含む
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+
+	return time.Parse(dateTimeLayout, v)
+}
+
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	day, ok := weekdayCodes[v[len(v)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	n := 0
+	if prefix := v[:len(v)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+		}
+	}
+
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+// String はRRULEの値部分("RRULE:"を除く)を返します。
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format(dateTimeLayout))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = weekdayNames[wd.Day]
+			if wd.N != 0 {
+				days[i] = strconv.Itoa(wd.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Set は開始日時・RRULE・追加日(RDATE)・除外日(EXDATE)からなる繰り返しの集合です。
+// 時刻はタイムゾーンを持たない浮動時刻として扱い、除外日は日付単位で比較します。
+type Set struct {
+	DTStart time.Time
+	Rule    *Rule
+	RDates  []time.Time
+	ExDates []time.Time
+}
+
+// Between は from 以上 to 以下に開始する繰り返しを昇順で返します。
+// DTSTART はルールに一致しなくても常に最初の繰り返しとして扱います。
+func (s Set) Between(from, to time.Time) []time.Time {
+	excluded := make(map[string]bool, len(s.ExDates))
+	for _, d := range s.ExDates {
+		excluded[d.Format(dateLayout)] = true
+	}
+
+	seen := map[time.Time]bool{}
+	var result []time.Time
+	add := func(t time.Time) {
+		if seen[t] || excluded[t.Format(dateLayout)] || t.Before(from) || t.After(to) {
+			return
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+
+	add(s.DTStart)
+	for _, t := range s.RDates {
+		add(t)
+	}
+	if s.Rule != nil {
+		s.Rule.each(s.DTStart, to, func(t time.Time) { add(t) })
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+
+	return result
+}
+
+// each は dtstart 以降のルールの繰り返しを、COUNT・UNTIL・limit のいずれかに達するまで順に渡します。
+func (r Rule) each(dtstart, limit time.Time, yield func(time.Time)) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	count := 0
+	for i := 0; i < maxIterations; i++ {
+		candidates := r.period(dtstart, i*interval)
+		if len(candidates) == 0 {
+			continue
+		}
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if t.After(limit) {
+				return
+			}
+			yield(t)
+			count++
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// period は dtstart から offset 単位(週または月)後の期間に含まれる候補日時を昇順で返します。
+func (r Rule) period(dtstart time.Time, offset int) []time.Time {
+	clock := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Weekly:
+		// 週の始まりは月曜日(WKST=MO)
+		weekStart := dtstart.AddDate(0, 0, -((int(dtstart.Weekday())+6)%7)+7*offset)
+		if len(r.ByDay) == 0 {
+			return []time.Time{dtstart.AddDate(0, 0, 7*offset)}
+		}
+		for _, wd := range r.ByDay {
+			d := weekStart.AddDate(0, 0, (int(wd.Day)+6)%7)
+			days = append(days, clock(d.Year(), d.Month(), d.Day()))
+		}
+	case Monthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(offset), 1, 0, 0, 0, 0, dtstart.Location())
+		y, m := first.Year(), first.Month()
+		lastDay := first.AddDate(0, 1, -1).Day()
+
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, md := range r.ByMonthDay {
+				d := md
+				if md < 0 {
+					d = lastDay + md + 1
+				}
+				if d >= 1 && d <= lastDay {
+					days = append(days, clock(y, m, d))
+				}
+			}
+		case len(r.ByDay) > 0:
+			for _, wd := range r.ByDay {
+				var matches []int
+				for d := 1; d <= lastDay; d++ {
+					if time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.Day {
+						matches = append(matches, d)
+					}
+				}
+				switch {
+				case wd.N == 0:
+					for _, d := range matches {
+						days = append(days, clock(y, m, d))
+					}
+				case wd.N > 0 && wd.N <= len(matches):
+					days = append(days, clock(y, m, matches[wd.N-1]))
+				case wd.N < 0 && -wd.N <= len(matches):
+					days = append(days, clock(y, m, matches[len(matches)+wd.N]))
+				}
+			}
+		default:
+			// 該当日がない月(31日など)はRFC 5545に従いスキップする
+			if dtstart.Day() <= lastDay {
+				days = append(days, clock(y, m, dtstart.Day()))
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	return days
+}
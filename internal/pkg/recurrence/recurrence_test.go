@@ -0,0 +1,146 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/recurrence"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func formatAll(ts []time.Time) []string {
+	res := make([]string, len(ts))
+	for i, t := range ts {
+		res[i] = t.Format("2006-01-02 15:04")
+	}
+
+	return res
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "FREQ=WEEKLY", want: "FREQ=WEEKLY"},
+		{in: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{in: "FREQ=MONTHLY;BYDAY=2SA;COUNT=6", want: "FREQ=MONTHLY;COUNT=6;BYDAY=2SA"},
+		{in: "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20251231", want: "FREQ=MONTHLY;UNTIL=20251231T235959;BYMONTHDAY=-1"},
+		{in: "FREQ=DAILY", wantErr: true},
+		{in: "BYDAY=MO", wantErr: true},
+		{in: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{in: "FREQ=MONTHLY;COUNT=2;UNTIL=20250101", wantErr: true},
+		{in: "FREQ=MONTHLY;BYDAY=XX", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := recurrence.Parse(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, r.String())
+		})
+	}
+}
+
+func TestSet_Between(t *testing.T) {
+	mustParse := func(s string) *recurrence.Rule {
+		r, err := recurrence.Parse(s)
+		require.NoError(t, err)
+
+		return &r
+	}
+
+	tests := []struct {
+		name string
+		set  recurrence.Set
+		from string
+		to   string
+		want []string
+	}{
+		{
+			name: "weekly with count",
+			set:  recurrence.Set{DTStart: date("2025-01-06 19:00"), Rule: mustParse("FREQ=WEEKLY;COUNT=3")},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-01-06 19:00", "2025-01-13 19:00", "2025-01-20 19:00"},
+		},
+		{
+			name: "biweekly on two days within range",
+			set:  recurrence.Set{DTStart: date("2025-01-06 10:00"), Rule: mustParse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH")},
+			from: "2025-01-10 00:00",
+			to:   "2025-01-31 23:59",
+			want: []string{"2025-01-20 10:00", "2025-01-23 10:00"},
+		},
+		{
+			name: "second saturday monthly",
+			set:  recurrence.Set{DTStart: date("2025-01-11 13:00"), Rule: mustParse("FREQ=MONTHLY;BYDAY=2SA;COUNT=4")},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-01-11 13:00", "2025-02-08 13:00", "2025-03-08 13:00", "2025-04-12 13:00"},
+		},
+		{
+			name: "last day of month until",
+			set:  recurrence.Set{DTStart: date("2025-01-31 18:00"), Rule: mustParse("FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20250430")},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-01-31 18:00", "2025-02-28 18:00", "2025-03-31 18:00", "2025-04-30 18:00"},
+		},
+		{
+			name: "monthly on the 31st skips short months",
+			set:  recurrence.Set{DTStart: date("2025-01-31 18:00"), Rule: mustParse("FREQ=MONTHLY;COUNT=3")},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-01-31 18:00", "2025-03-31 18:00", "2025-05-31 18:00"},
+		},
+		{
+			name: "exdates and rdates",
+			set: recurrence.Set{
+				DTStart: date("2025-01-06 19:00"),
+				Rule:    mustParse("FREQ=WEEKLY;COUNT=3"),
+				RDates:  []time.Time{date("2025-02-01 19:00")},
+				ExDates: []time.Time{date("2025-01-13 00:00")},
+			},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-01-06 19:00", "2025-01-20 19:00", "2025-02-01 19:00"},
+		},
+		{
+			name: "date list only",
+			set: recurrence.Set{
+				DTStart: date("2025-03-14 10:00"),
+				RDates:  []time.Time{date("2025-06-28 10:00"), date("2025-03-14 10:00")},
+			},
+			from: "2025-01-01 00:00",
+			to:   "2025-12-31 23:59",
+			want: []string{"2025-03-14 10:00", "2025-06-28 10:00"},
+		},
+		{
+			name: "open ended rule stops at range end",
+			set:  recurrence.Set{DTStart: date("2025-01-06 19:00"), Rule: mustParse("FREQ=WEEKLY")},
+			from: "2030-01-01 00:00",
+			to:   "2030-01-14 23:59",
+			want: []string{"2030-01-07 19:00", "2030-01-14 19:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.set.Between(date(tt.from), date(tt.to))
+			require.Equal(t, tt.want, formatAll(got))
+		})
+	}
+}
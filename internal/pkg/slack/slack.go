@@ -0,0 +1,74 @@
+// Package slack はIncoming Webhookを使ったSlackへの通知を扱います。
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Webhook はIncoming WebhookのURLにメッセージを送信します。
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook は url に送信する Webhook を作成します。url が空の場合、Post は常にエラーを返します。
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: http.DefaultClient}
+}
+
+// Post はテキストのメッセージを送信します。
+func (w *Webhook) Post(ctx context.Context, text string) error {
+	if w.url == "" {
+		return fmt.Errorf("slackWebhookURLが設定されていません")
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("payloadのJSON変換に失敗: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Slackへのリクエスト作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slackへのリクエスト送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slackからの応答が異常です: %s", resp.Status)
+	}
+	return nil
+}
+
+// Ping はメッセージを送らずに、Webhookに届くかを確かめます。
+// 本文なしのHEADリクエストを送り、Webhookが削除されている(404・410)かサーバーエラーの場合はエラーを返します。
+func (w *Webhook) Ping(ctx context.Context) error {
+	if w.url == "" {
+		return fmt.Errorf("slackWebhookURLが設定されていません")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, w.url, nil)
+	if err != nil {
+		return fmt.Errorf("Slackへのリクエスト作成に失敗しました: %w", err)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slackへのリクエスト送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone, resp.StatusCode >= 500:
+		return fmt.Errorf("Slackからの応答が異常です: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/slack"
+)
+
+func TestWebhook_Post(t *testing.T) {
+	var got map[string]string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	require.NoError(t, slack.NewWebhook(srv.URL).Post(ctx, "こんにちは"))
+	require.Equal(t, map[string]string{"text": "こんにちは"}, got)
+
+	status = http.StatusNotFound
+	require.Error(t, slack.NewWebhook(srv.URL).Post(ctx, "hi"))
+
+	require.Error(t, slack.NewWebhook("").Post(ctx, "hi"))
+}
+
+func TestWebhook_Ping(t *testing.T) {
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	// メッセージを送らないリクエストは400などで拒否されるが、届いてはいる
+	ctx := context.Background()
+	require.NoError(t, slack.NewWebhook(srv.URL).Ping(ctx))
+
+	status = http.StatusNotFound
+	require.Error(t, slack.NewWebhook(srv.URL).Ping(ctx))
+	status = http.StatusServiceUnavailable
+	require.Error(t, slack.NewWebhook(srv.URL).Ping(ctx))
+
+	require.Error(t, slack.NewWebhook("").Ping(ctx))
+}
@@ -0,0 +1,12 @@
+package storage
+
+import (
+	"net/http"
+	"time"
+)
+
+// SignForTest は固定時刻で req に署名します。
+func (s *S3) SignForTest(req *http.Request, body []byte, now time.Time) {
+	s.now = func() time.Time { return now }
+	s.sign(req, body)
+}
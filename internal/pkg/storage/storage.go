@@ -0,0 +1,31 @@
+// Package storage はアップロードされたファイルの保存先を抽象化します。
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound は指定したキーのオブジェクトが存在しないことを表します。
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage はキーを指定してオブジェクトを保存・取得します。
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get はオブジェクトの内容とContent-Typeを返します。呼び出し元で Close してください。
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+	Delete(ctx context.Context, key string) error
+	// URL はオブジェクトを公開するURLを返します。
+	URL(key string) string
+}
+
+// ValidKey はキーが相対パスとして安全か(".."や絶対パスを含まないか)を返します。
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	return path.Clean(key) == key && !strings.HasPrefix(key, "../") && key != ".."
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/authcode"
+)
+
+// Admin は運営者です。トークンのハッシュは含めません。
+type Admin struct {
+	ID    int    `db:"id"`
+	Email string `db:"email"`
+	Name  string `db:"name"`
+}
+
+// CreateAdminParams は運営者の作成に必要なパラメータです。
+type CreateAdminParams struct {
+	Email string
+	Name  string
+}
+
+// CreateAdmin は運営者を作成し、運営者用のトークンを返します。
+// トークンはハッシュのみを保存するため、再表示はできません。
+func (r *Repository) CreateAdmin(ctx context.Context, params CreateAdminParams) (string, error) {
+	token, tokenHash := authcode.Generate()
+
+	query := `
+		INSERT INTO admins (email, name, token_hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		strings.ToLower(strings.TrimSpace(params.Email)),
+		params.Name,
+		tokenHash,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("運営者の作成に失敗: %w", err)
+	}
+
+	return token.String(), nil
+}
+
+// adminByTokenQuery はトークンのハッシュで運営者を引きます。uq_admins_token_hash を使います。
+const adminByTokenQuery = `SELECT id, email, name FROM admins WHERE token_hash = ?`
+
+// GetAdminByToken はトークンに一致する運営者を返します。一致しない場合は nil を返します。
+func (r *Repository) GetAdminByToken(ctx context.Context, token uuid.UUID) (*Admin, error) {
+	if token == uuid.Nil {
+		return nil, nil
+	}
+
+	var admin Admin
+	err := r.db.GetContext(ctx, &admin, adminByTokenQuery, authcode.Hash(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("運営者の取得に失敗: %w", err)
+	}
+
+	return &admin, nil
+}
@@ -0,0 +1,495 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// eventRepository は Repository と Memory が同じように振る舞うべき操作です。
+type eventRepository interface {
+	BeginTx(ctx context.Context) (repository.Tx, error)
+	CreateEventTx(ctx context.Context, tx repository.Tx, params repository.CreateEventParams) (int, string, error)
+	UpsertSeedEventTx(ctx context.Context, tx repository.Tx, seedKey string, params repository.CreateEventParams) (int, error)
+	GetEvents(ctx context.Context, params repository.GetEventsParams) ([]*repository.Event, error)
+	GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*repository.Event, error)
+	GetEventForReview(ctx context.Context, id int) (*repository.Event, error)
+	VerifyAuthCode(ctx context.Context, id int, authCode uuid.UUID) (bool, error)
+	AuthenticateEvent(ctx context.Context, id int, authCode uuid.UUID) error
+	RotateAuthCode(ctx context.Context, id int, email string, expiresAt time.Time) (string, error)
+	SetEventApproved(ctx context.Context, id int) error
+	ResetApprovalTx(ctx context.Context, tx repository.Tx, id int, authCode uuid.UUID, expiresAt time.Time) (string, error)
+	SetEventLocation(ctx context.Context, id int, lat, lng float64, precision string) error
+	SetPosterImage(ctx context.Context, id int, imageKey, thumbnailKey string) ([]string, error)
+	SetSpeakerPhoto(ctx context.Context, id, index int, photoKey, thumbnailKey string) ([]string, error)
+	UpdateEvent(ctx context.Context, id int, params repository.CreateEventParams) ([]string, error)
+	DeleteEvent(ctx context.Context, id int) ([]string, error)
+	UpsertOccurrenceOverride(ctx context.Context, o repository.OccurrenceOverride) error
+	CreateRegistration(ctx context.Context, params repository.CreateRegistrationParams) (string, error)
+	VerifyRegistration(ctx context.Context, eventID int, token uuid.UUID) (bool, error)
+	CreateAdmin(ctx context.Context, params repository.CreateAdminParams) (string, error)
+	GetAdminByToken(ctx context.Context, token uuid.UUID) (*repository.Admin, error)
+}
+
+// TestConformance は Repository と Memory に同じテストを実行し、Memory がテストで Repository の代わりになることを確かめます。
+func TestConformance(t *testing.T) {
+	impls := []struct {
+		name    string
+		newRepo func(t *testing.T) eventRepository
+	}{
+		{name: "sql", newRepo: func(t *testing.T) eventRepository {
+			return repository.New(setupMigratedDB(t, "repository_conformance_test"))
+		}},
+		{name: "memory", newRepo: func(t *testing.T) eventRepository {
+			return repository.NewMemory()
+		}},
+	}
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			testConformance(t, impl.newRepo)
+		})
+	}
+}
+
+func testConformance(t *testing.T, newRepo func(t *testing.T) eventRepository) {
+	ctx := context.Background()
+
+	t.Run("pending events are visible only with the auth code", func(t *testing.T) {
+		repo := newRepo(t)
+		id, code := createEvent(t, repo, conformanceEvent("集合論入門", "2025-01-10"))
+
+		got, err := repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Nil(t, got)
+		got, err = repo.GetEvent(ctx, id, code)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		require.Equal(t, "集合論入門", got.Title)
+		require.Equal(t, repository.Tags{"数学"}, got.Tags)
+		require.Equal(t, "山田", got.Speakers[0].Name)
+		require.False(t, got.IsAuthenticated)
+
+		listed, err := repo.GetEvents(ctx, repository.GetEventsParams{})
+		require.NoError(t, err)
+		require.Empty(t, listed)
+		listed, err = repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusPending})
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+
+		require.Error(t, repo.AuthenticateEvent(ctx, id, uuid.New()))
+		require.NoError(t, repo.AuthenticateEvent(ctx, id, code))
+		require.Error(t, repo.AuthenticateEvent(ctx, id, code), "approved events are not updated again")
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.True(t, got.IsAuthenticated)
+	})
+
+	t.Run("rolled back events are not saved", func(t *testing.T) {
+		repo := newRepo(t)
+		tx, err := repo.BeginTx(ctx)
+		require.NoError(t, err)
+		id, _, err := repo.CreateEventTx(ctx, tx, conformanceEvent("取り消し", "2025-01-10"))
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		require.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+
+		got, err := repo.GetEventForReview(ctx, id)
+		require.NoError(t, err)
+		require.Nil(t, got)
+		listed, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+		require.NoError(t, err)
+		require.Empty(t, listed)
+	})
+
+	t.Run("auth codes expire and rotate", func(t *testing.T) {
+		repo := newRepo(t)
+		expired := conformanceEvent("期限切れ", "2025-01-10")
+		expired.ApprovalCodeExpiresAt = time.Now().Add(-time.Hour)
+		expiredID, expiredCode := createEvent(t, repo, expired)
+		ok, err := repo.VerifyAuthCode(ctx, expiredID, expiredCode)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Error(t, repo.AuthenticateEvent(ctx, expiredID, expiredCode))
+
+		id, code := createEvent(t, repo, conformanceEvent("集合論入門", "2025-01-10"))
+		ok, err = repo.VerifyAuthCode(ctx, id+100, code)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		rotated, err := repo.RotateAuthCode(ctx, id, "other@example.com", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, rotated)
+		first, err := repo.RotateAuthCode(ctx, id, " Owner@Example.com ", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.NotEmpty(t, first)
+		rotated, err = repo.RotateAuthCode(ctx, id, "owner@example.com", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.NotEmpty(t, rotated)
+
+		ok, err = repo.VerifyAuthCode(ctx, id, uuid.MustParse(first))
+		require.NoError(t, err)
+		require.False(t, ok, "the previous organizer code must be revoked")
+		ok, err = repo.VerifyAuthCode(ctx, id, uuid.MustParse(rotated))
+		require.NoError(t, err)
+		require.True(t, ok)
+		// 発行し直したコードは主催者に送るため、編集には使えても承認には使えない
+		require.Error(t, repo.AuthenticateEvent(ctx, id, uuid.MustParse(rotated)), "a rotated code must not approve")
+		got, err := repo.GetEvent(ctx, id, uuid.MustParse(rotated))
+		require.NoError(t, err)
+		require.False(t, got.IsAuthenticated)
+
+		// 運営者に通知した承認用のコードは、主催者のコードを発行し直しても使える
+		ok, err = repo.VerifyAuthCode(ctx, id, code)
+		require.NoError(t, err)
+		require.True(t, ok, "rotation must not revoke the approval code")
+		require.NoError(t, repo.AuthenticateEvent(ctx, id, code))
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.True(t, got.IsAuthenticated)
+	})
+
+	t.Run("approval resets with a new approval code", func(t *testing.T) {
+		repo := newRepo(t)
+		resetApproval := func(id int, authCode uuid.UUID) string {
+			t.Helper()
+			tx, err := repo.BeginTx(ctx)
+			require.NoError(t, err)
+			approvalCode, err := repo.ResetApprovalTx(ctx, tx, id, authCode, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit())
+			return approvalCode
+		}
+
+		pendingID, pendingCode := createEvent(t, repo, conformanceEvent("未承認", "2025-01-10"))
+		require.Empty(t, resetApproval(pendingID, uuid.Nil), "a pending event has nothing to reset")
+		require.Empty(t, resetApproval(pendingID+100, uuid.Nil))
+
+		id, code := createEvent(t, repo, conformanceEvent("集合論入門", "2025-01-10"))
+		require.NoError(t, repo.AuthenticateEvent(ctx, id, code))
+		// 運営者の承認用のコードで編集した場合は承認を残す
+		require.Empty(t, resetApproval(id, code))
+		got, err := repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+
+		// ROLLBACKすると承認済みのまま
+		tx, err := repo.BeginTx(ctx)
+		require.NoError(t, err)
+		_, err = repo.ResetApprovalTx(ctx, tx, id, uuid.Nil, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+
+		approvalCode := resetApproval(id, uuid.Nil)
+		require.NotEmpty(t, approvalCode)
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Nil(t, got, "the event must be hidden until approved again")
+		require.Error(t, repo.AuthenticateEvent(ctx, id, code), "the previous approval code must be revoked")
+		require.NoError(t, repo.AuthenticateEvent(ctx, id, uuid.MustParse(approvalCode)))
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.True(t, got.IsAuthenticated)
+
+		ok, err := repo.VerifyAuthCode(ctx, pendingID, pendingCode)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("description html is rendered on write", func(t *testing.T) {
+		repo := newRepo(t)
+		params := conformanceEvent("説明文", "2025-01-10")
+		params.Description = ptr("**素数**の話")
+		id := approveEvent(t, repo, params)
+		got, err := repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Equal(t, "<p><strong>素数</strong>の話</p>\n", *got.DescriptionHTML)
+
+		params.Description = ptr("$x$")
+		_, err = repo.UpdateEvent(ctx, id, params)
+		require.NoError(t, err)
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Equal(t, `<p><span class="math math-inline">$x$</span></p>`+"\n", *got.DescriptionHTML)
+
+		params.Description = nil
+		_, err = repo.UpdateEvent(ctx, id, params)
+		require.NoError(t, err)
+		got, err = repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Nil(t, got.DescriptionHTML)
+	})
+
+	t.Run("locations are filled in only where missing", func(t *testing.T) {
+		repo := newRepo(t)
+		id := approveEvent(t, repo, conformanceEvent("位置情報なし", "2025-01-10"))
+		exact := conformanceEvent("位置情報あり", "2025-01-10")
+		exact.Latitude, exact.Longitude, exact.LocationPrecision = ptr(34.69), ptr(135.50), ptr("exact")
+		exactID := approveEvent(t, repo, exact)
+
+		tokyo := geo.Bounds{MinLat: 35, MaxLat: 36, MinLng: 139, MaxLng: 140}
+		listed, err := repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+		require.NoError(t, err)
+		require.Empty(t, listed)
+
+		require.NoError(t, repo.SetEventLocation(ctx, id, 35.68, 139.76, "prefecture"))
+		listed, err = repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+		require.NoError(t, err)
+		require.Equal(t, []string{"位置情報なし"}, titles(listed))
+		require.Equal(t, "prefecture", *listed[0].LocationPrecision)
+
+		// 主催者が指定した座標は上書きしない
+		require.ErrorIs(t, repo.SetEventLocation(ctx, exactID, 35.68, 139.76, "prefecture"), sql.ErrNoRows)
+		got, err := repo.GetEvent(ctx, exactID, uuid.Nil)
+		require.NoError(t, err)
+		require.Equal(t, 34.69, *got.Latitude)
+		require.ErrorIs(t, repo.SetEventLocation(ctx, id+100, 35.68, 139.76, "prefecture"), sql.ErrNoRows)
+	})
+
+	t.Run("events are listed in date order and filtered", func(t *testing.T) {
+		repo := newRepo(t)
+		tokyo := conformanceEvent("東京", "2025-03-01")
+		tokyo.Latitude, tokyo.Longitude = ptr(35.68), ptr(139.76)
+		osaka := conformanceEvent("大阪", "2025-01-01")
+		osaka.Latitude, osaka.Longitude = ptr(34.69), ptr(135.50)
+		morning := conformanceEvent("朝", "2025-02-01")
+		morning.StartTime = "08:00:00"
+		evening := conformanceEvent("夕方", "2025-02-01")
+		evening.StartTime = "18:00:00"
+		series := conformanceEvent("毎週", "2024-01-01")
+		series.RecurrenceRule = ptr("FREQ=WEEKLY")
+		for _, params := range []repository.CreateEventParams{tokyo, evening, osaka, morning, series} {
+			approveEvent(t, repo, params)
+		}
+
+		listed, err := repo.GetEvents(ctx, repository.GetEventsParams{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"毎週", "大阪", "朝", "夕方", "東京"}, titles(listed))
+
+		// シリーズは期間に関係なく含める
+		listed, err = repo.GetEvents(ctx, repository.GetEventsParams{From: "2025-02-01", To: "2025-02-28"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"毎週", "朝", "夕方"}, titles(listed))
+
+		listed, err = repo.GetEvents(ctx, repository.GetEventsParams{
+			Bounds: &geo.Bounds{MinLat: 35, MaxLat: 36, MinLng: 139, MaxLng: 140},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"東京"}, titles(listed))
+	})
+
+	t.Run("admin approval", func(t *testing.T) {
+		repo := newRepo(t)
+		id, _ := createEvent(t, repo, conformanceEvent("集合論入門", "2025-01-10"))
+
+		require.NoError(t, repo.SetEventApproved(ctx, id))
+		got, err := repo.GetEventForReview(ctx, id)
+		require.NoError(t, err)
+		require.True(t, got.IsAuthenticated)
+		require.ErrorIs(t, repo.SetEventApproved(ctx, id+100), sql.ErrNoRows)
+	})
+
+	t.Run("admins are found by token", func(t *testing.T) {
+		repo := newRepo(t)
+		token, err := repo.CreateAdmin(ctx, repository.CreateAdminParams{Email: " Admin@Example.com ", Name: "運営"})
+		require.NoError(t, err)
+		_, err = repo.CreateAdmin(ctx, repository.CreateAdminParams{Email: "admin@example.com", Name: "重複"})
+		require.Error(t, err, "email must be unique")
+
+		admin, err := repo.GetAdminByToken(ctx, uuid.MustParse(token))
+		require.NoError(t, err)
+		require.NotNil(t, admin)
+		require.Equal(t, "admin@example.com", admin.Email)
+		require.Equal(t, "運営", admin.Name)
+
+		for _, other := range []uuid.UUID{uuid.New(), uuid.Nil} {
+			admin, err := repo.GetAdminByToken(ctx, other)
+			require.NoError(t, err)
+			require.Nil(t, admin)
+		}
+	})
+
+	t.Run("images are replaced, carried over and returned on delete", func(t *testing.T) {
+		repo := newRepo(t)
+		params := conformanceEvent("集合論入門", "2025-01-10")
+		params.Speakers = []repository.Speaker{{Name: "山田"}, {Name: "佐藤"}}
+		id, _ := createEvent(t, repo, params)
+
+		old, err := repo.SetPosterImage(ctx, id, "poster1.jpg", "poster1-thumb.jpg")
+		require.NoError(t, err)
+		require.Empty(t, old)
+		old, err = repo.SetPosterImage(ctx, id, "poster2.jpg", "poster2-thumb.jpg")
+		require.NoError(t, err)
+		require.Equal(t, []string{"poster1.jpg", "poster1-thumb.jpg"}, old)
+		_, err = repo.SetPosterImage(ctx, id+100, "x.jpg", "x-thumb.jpg")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = repo.SetSpeakerPhoto(ctx, id, 0, "yamada.jpg", "yamada-thumb.jpg")
+		require.NoError(t, err)
+		_, err = repo.SetSpeakerPhoto(ctx, id, 1, "sato.jpg", "sato-thumb.jpg")
+		require.NoError(t, err)
+		_, err = repo.SetSpeakerPhoto(ctx, id, 2, "x.jpg", "x-thumb.jpg")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		// 佐藤さんが外れ、山田さんの写真は引き継がれる
+		params.Title = "集合論入門 (改訂)"
+		params.Speakers = []repository.Speaker{{Name: "鈴木"}, {Name: "山田"}}
+		orphaned, err := repo.UpdateEvent(ctx, id, params)
+		require.NoError(t, err)
+		require.Equal(t, []string{"sato.jpg", "sato-thumb.jpg"}, orphaned)
+		got, err := repo.GetEventForReview(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "集合論入門 (改訂)", got.Title)
+		require.Empty(t, got.Speakers[0].PhotoKey)
+		require.Equal(t, "yamada.jpg", got.Speakers[1].PhotoKey)
+		_, err = repo.UpdateEvent(ctx, id+100, params)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		keys, err := repo.DeleteEvent(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, []string{"poster2.jpg", "poster2-thumb.jpg", "yamada.jpg", "yamada-thumb.jpg"}, keys)
+		got, err = repo.GetEventForReview(ctx, id)
+		require.NoError(t, err)
+		require.Nil(t, got)
+		_, err = repo.DeleteEvent(ctx, id)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("occurrence overrides are upserted", func(t *testing.T) {
+		repo := newRepo(t)
+		params := conformanceEvent("毎週", "2025-01-06")
+		params.RecurrenceRule = ptr("FREQ=WEEKLY")
+		id := approveEvent(t, repo, params)
+
+		for _, title := range []string{"休講", "特別回"} {
+			require.NoError(t, repo.UpsertOccurrenceOverride(ctx, repository.OccurrenceOverride{
+				EventID:        id,
+				OccurrenceDate: "2025-01-13",
+				Title:          ptr(title),
+			}))
+		}
+		require.NoError(t, repo.UpsertOccurrenceOverride(ctx, repository.OccurrenceOverride{
+			EventID:        id,
+			OccurrenceDate: "2025-01-20",
+			IsCancelled:    true,
+		}))
+		require.Error(t, repo.UpsertOccurrenceOverride(ctx, repository.OccurrenceOverride{
+			EventID:        id + 100,
+			OccurrenceDate: "2025-01-13",
+		}))
+
+		got, err := repo.GetEvent(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.Len(t, got.Overrides, 2)
+		require.Equal(t, "特別回", *got.Overrides[0].Title)
+		require.True(t, got.Overrides[1].IsCancelled)
+	})
+
+	t.Run("re-registration adds a token and keeps the old one", func(t *testing.T) {
+		repo := newRepo(t)
+		id := approveEvent(t, repo, conformanceEvent("集合論入門", "2025-01-10"))
+
+		first, err := repo.CreateRegistration(ctx, repository.CreateRegistrationParams{EventID: id, Name: "山田", Email: "yamada@example.com"})
+		require.NoError(t, err)
+		// 本人確認のない申し込み直しで、以前のトークンを無効にしない
+		second, err := repo.CreateRegistration(ctx, repository.CreateRegistrationParams{EventID: id, Name: "なりすまし", Email: "Yamada@Example.com"})
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+
+		ok, err := repo.VerifyRegistration(ctx, id, uuid.MustParse(first))
+		require.NoError(t, err)
+		require.True(t, ok, "the old token must stay valid")
+		ok, err = repo.VerifyRegistration(ctx, id, uuid.MustParse(second))
+		require.NoError(t, err)
+		require.True(t, ok)
+		other := approveEvent(t, repo, conformanceEvent("位相空間論", "2025-01-11"))
+		ok, err = repo.VerifyRegistration(ctx, other, uuid.MustParse(first))
+		require.NoError(t, err)
+		require.False(t, ok, "a token is only valid for its event")
+		ok, err = repo.VerifyRegistration(ctx, id, uuid.Nil)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		_, err = repo.CreateRegistration(ctx, repository.CreateRegistrationParams{EventID: id + 100, Name: "山田", Email: "yamada@example.com"})
+		require.Error(t, err)
+	})
+
+	t.Run("seed events are upserted by key", func(t *testing.T) {
+		repo := newRepo(t)
+		var ids []int
+		for _, title := range []string{"シード", "シード (更新)"} {
+			tx, err := repo.BeginTx(ctx)
+			require.NoError(t, err)
+			id, err := repo.UpsertSeedEventTx(ctx, tx, "sample", conformanceEvent(title, "2025-01-10"))
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit())
+			ids = append(ids, id)
+		}
+		require.Equal(t, ids[0], ids[1])
+
+		listed, err := repo.GetEvents(ctx, repository.GetEventsParams{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"シード (更新)"}, titles(listed))
+	})
+}
+
+func conformanceEvent(title, date string) repository.CreateEventParams {
+	return repository.CreateEventParams{
+		Title:                 title,
+		Organizer:             "数学の会",
+		StartDate:             date,
+		StartTime:             "10:00:00",
+		EndDate:               date,
+		EndTime:               "12:00:00",
+		Email:                 "owner@example.com",
+		DeliveryMode:          "offline",
+		IsOffline:             true,
+		Tags:                  []string{"数学"},
+		Speakers:              []repository.Speaker{{Name: "山田"}},
+		ApprovalCodeExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+// createEvent は未承認のイベントを登録してコミットし、IDと認証コードを返します。
+func createEvent(t *testing.T, repo eventRepository, params repository.CreateEventParams) (int, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	id, code, err := repo.CreateEventTx(ctx, tx, params)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	return id, uuid.MustParse(code)
+}
+
+// approveEvent はイベントを登録して承認し、IDを返します。
+func approveEvent(t *testing.T, repo eventRepository, params repository.CreateEventParams) int {
+	t.Helper()
+
+	id, _ := createEvent(t, repo, params)
+	require.NoError(t, repo.SetEventApproved(context.Background(), id))
+	return id
+}
+
+func titles(events []*repository.Event) []string {
+	res := make([]string, len(events))
+	for i, e := range events {
+		res[i] = e.Title
+	}
+	return res
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
+)
+
+// MySQLとSQLiteで書き方が異なるSQLの断片を、接続しているDBに合わせて返します。
+
+// forUpdate は行ロックの句です。SQLiteは接続を1本に絞って書き込みを直列にしているため不要です。
+func (r *Repository) forUpdate() string {
+	if r.db.DriverName() == database.SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// onConflictUpdate は、一意キー(conflict)が重複したときに columns を挿入しようとした値で上書きする句です。
+// MySQLは一意キーを自動で判断するため conflict を使いません。
+func (r *Repository) onConflictUpdate(conflict []string, columns ...string) string {
+	sets := make([]string, len(columns))
+	if r.db.DriverName() == database.SQLite {
+		for i, c := range columns {
+			sets[i] = fmt.Sprintf("%s = excluded.%s", c, c)
+		}
+		return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflict, ", "), strings.Join(sets, ", "))
+	}
+
+	for i, c := range columns {
+		sets[i] = fmt.Sprintf("%s = VALUES(%s)", c, c)
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// onConflictDoNothing は、一意キー(conflict)が重複したときに挿入をやめる句です。
+// MySQLの INSERT IGNORE は外部キーの違反なども無視してしまうため、何も変えない更新で代わりにします。
+func (r *Repository) onConflictDoNothing(conflict []string) string {
+	if r.db.DriverName() == database.SQLite {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(conflict, ", "))
+	}
+	return " ON DUPLICATE KEY UPDATE id = id"
+}
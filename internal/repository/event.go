@@ -2,38 +2,113 @@ package repository
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/authcode"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/markdown"
 )
 
 // Event はeventsテーブル1行分の構造体を表します。
 type Event struct {
-	ID               int        `db:"id"`
-	Title            string     `db:"title"`
-	Organizer        string     `db:"organizer"`
-	StartDate        string     `db:"start_date"`
-	StartTime        string     `db:"start_time"`
-	EndDate          string     `db:"end_date"`
-	EndTime          string     `db:"end_time"`
-	Email            string     `db:"email"`
-	Prefecture       *string    `db:"prefecture"`
-	EventType        *string    `db:"event_type"`
-	IsOnline         bool       `db:"is_online"`
-	IsOffline        bool       `db:"is_offline"`
-	OfficialURL      *string    `db:"official_url"`
-	OnlineLectureURL *string    `db:"online_lecture_url"`
-	Venue            *string    `db:"venue"`
-	Target           *string    `db:"target"`
-	Capacity         *string    `db:"capacity"`
-	Description      *string    `db:"description"`
-	Tags             []string   `db:"tags"`
-	Speakers         []Speaker  `db:"speakers"`
-	Schedule         []Schedule `db:"schedule"`
-	AuthCode         string     `db:"auth_code"`
-	IsAuthenticated  bool       `db:"is_authenticated"`
+	ID               int     `db:"id"`
+	Title            string  `db:"title"`
+	Organizer        string  `db:"organizer"`
+	StartDate        string  `db:"start_date"`
+	StartTime        string  `db:"start_time"`
+	EndDate          string  `db:"end_date"`
+	EndTime          string  `db:"end_time"`
+	Email            string  `db:"email"`
+	Prefecture       *string `db:"prefecture"`
+	EventType        *string `db:"event_type"`
+	DeliveryMode     string  `db:"delivery_mode"`
+	IsOnline         bool    `db:"is_online"`
+	IsOffline        bool    `db:"is_offline"`
+	OfficialURL      *string `db:"official_url"`
+	OnlineLectureURL *string `db:"online_lecture_url"`
+	Venue            *string `db:"venue"`
+	Target           *string `db:"target"`
+	Capacity         *string `db:"capacity"`
+	Description      *string `db:"description"`
+	// DescriptionHTML は Description を書き込むときに変換したHTMLです。
+	DescriptionHTML *string   `db:"description_html"`
+	Tags            Tags      `db:"tags"`
+	Speakers        Speakers  `db:"speakers"`
+	Schedule        Schedules `db:"schedule"`
+	AuthCodeHash    string    `db:"auth_code_hash"`
+	IsAuthenticated bool      `db:"is_authenticated"`
+	// RecurrenceRule が設定されている場合、イベントはシリーズとして繰り返されます。
+	RecurrenceRule    *string              `db:"recurrence_rule"`
+	RecurrenceDates   Dates                `db:"recurrence_dates"`
+	RecurrenceExDates Dates                `db:"recurrence_exdates"`
+	Overrides         []OccurrenceOverride `db:"-"`
+	// 会場の住所と位置情報
+	VenueAddress      *string  `db:"venue_address"`
+	VenuePostalCode   *string  `db:"venue_postal_code"`
+	Latitude          *float64 `db:"latitude"`
+	Longitude         *float64 `db:"longitude"`
+	LocationPrecision *string  `db:"location_precision"`
+	// ポスター画像のストレージ上のキー
+	PosterImageKey     *string `db:"poster_image_key"`
+	PosterThumbnailKey *string `db:"poster_thumbnail_key"`
+}
+
+// IsSeries はイベントが繰り返しを持つかを返します。
+func (e *Event) IsSeries() bool {
+	return e.RecurrenceRule != nil || len(e.RecurrenceDates) > 0
+}
+
+// OccurrenceOverride はシリーズ内の1回分(OccurrenceDate)に対する上書きを表します。
+// nilのフィールドはシリーズの値をそのまま使います。
+type OccurrenceOverride struct {
+	EventID        int     `db:"event_id"`
+	OccurrenceDate string  `db:"occurrence_date"`
+	Title          *string `db:"title"`
+	StartDate      *string `db:"start_date"`
+	StartTime      *string `db:"start_time"`
+	EndDate        *string `db:"end_date"`
+	EndTime        *string `db:"end_time"`
+	Venue          *string `db:"venue"`
+	IsCancelled    bool    `db:"is_cancelled"`
+}
+
+// GetEventsParams はイベント一覧取得の条件です。
+// From/To (YYYY-MM-DD) を指定すると、期間と重なる単発イベントと全てのシリーズを返します。
+type GetEventsParams struct {
+	From string
+	To   string
+	// Bounds を指定すると、位置情報がその範囲内にあるイベントのみを返します。
+	Bounds *geo.Bounds
+	// Status は承認状態での絞り込みです。空の場合は承認済みのみを返します。
+	// 未承認のイベントは公開できないため、StatusPending・StatusAll は管理用のコマンドでのみ使ってください。
+	Status EventStatus
+}
+
+// EventStatus はイベントの承認状態です。
+type EventStatus string
+
+const (
+	StatusApproved EventStatus = "approved"
+	StatusPending  EventStatus = "pending"
+	StatusAll      EventStatus = "all"
+)
+
+// ParseEventStatus は文字列を EventStatus に変換します。
+func ParseEventStatus(s string) (EventStatus, error) {
+	switch status := EventStatus(s); status {
+	case StatusApproved, StatusPending, StatusAll:
+		return status, nil
+	default:
+		return "", fmt.Errorf("不明な承認状態です: %q", s)
+	}
 }
 
 // Speaker はスピーカー情報を表します。
@@ -41,72 +116,93 @@ type Speaker struct {
 	Name         string `json:"name"`
 	Title        string `json:"title"`
 	Organization string `json:"organization"`
+	// 顔写真のストレージ上のキー
+	PhotoKey          string `json:"photoKey,omitempty"`
+	PhotoThumbnailKey string `json:"photoThumbnailKey,omitempty"`
 }
 
 // Schedule はスケジュール情報を表します。
+// Date/StartTime/EndTime で複数日にわたるイベントの各日の枠を表し、Speakers は Speaker.Name を参照します。
 type Schedule struct {
-	Time    string `json:"time"`
-	Title   string `json:"title"`
-	Speaker string `json:"speaker"`
+	Date      string   `json:"date,omitempty"`
+	StartTime string   `json:"startTime,omitempty"`
+	EndTime   string   `json:"endTime,omitempty"`
+	Title     string   `json:"title"`
+	Room      string   `json:"room,omitempty"`
+	Track     string   `json:"track,omitempty"`
+	Speakers  []string `json:"speakers,omitempty"`
+
+	// Time と Speaker は日付を持たない旧形式の項目です。読み込み時に Date/StartTime/Speakers へ変換されます。
+	Time    string `json:"time,omitempty"`
+	Speaker string `json:"speaker,omitempty"`
 }
 
 // CreateEventParams はイベント作成時に必要なパラメータです。
 type CreateEventParams struct {
-	Title            string
-	Organizer        string
-	StartDate        string
-	StartTime        string
-	EndDate          string
-	EndTime          string
-	Email            string
-	Prefecture       *string
-	EventType        *string
-	IsOnline         bool
-	IsOffline        bool
-	OfficialURL      *string
-	OnlineLectureURL *string
-	Venue            *string
-	Target           *string
-	Capacity         *string
-	Description      *string
-	Tags             []string
-	Speakers         []Speaker
-	Schedule         []Schedule
+	Title             string
+	Organizer         string
+	StartDate         string
+	StartTime         string
+	EndDate           string
+	EndTime           string
+	Email             string
+	Prefecture        *string
+	EventType         *string
+	DeliveryMode      string
+	IsOnline          bool
+	IsOffline         bool
+	OfficialURL       *string
+	OnlineLectureURL  *string
+	Venue             *string
+	Target            *string
+	Capacity          *string
+	Description       *string
+	Tags              []string
+	Speakers          []Speaker
+	Schedule          []Schedule
+	RecurrenceRule    *string
+	RecurrenceDates   []string
+	RecurrenceExDates []string
+	VenueAddress      *string
+	VenuePostalCode   *string
+	Latitude          *float64
+	Longitude         *float64
+	LocationPrecision *string
+	// ApprovalCodeExpiresAt は運営者に通知する承認用のコードの有効期限です。
+	ApprovalCodeExpiresAt time.Time
 }
 
-// BeginTx は新たにトランザクションを開始します。
-func (r *Repository) BeginTx(ctx context.Context) (*sql.Tx, error) {
-	return r.db.BeginTx(ctx, nil)
+// Tx は CreateEventTx などに渡すトランザクションです。*sql.Tx が実装します。
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	Commit() error
+	Rollback() error
 }
 
-// CreateEventTx はトランザクション内でイベントをINSERTし、生成されたIDと認証コードを返します。
-func (r *Repository) CreateEventTx(ctx context.Context, tx *sql.Tx, params CreateEventParams) (int, string, error) {
-	authCode := uuid.New().String()
-
-	tagsJSON, err := json.Marshal(params.Tags)
-	if err != nil {
-		return 0, "", fmt.Errorf("タグのシリアライズに失敗: %w", err)
-	}
-	speakersJSON, err := json.Marshal(params.Speakers)
-	if err != nil {
-		return 0, "", fmt.Errorf("スピーカーのシリアライズに失敗: %w", err)
-	}
-	scheduleJSON, err := json.Marshal(params.Schedule)
+// BeginTx は新たにトランザクションを開始します。
+func (r *Repository) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return 0, "", fmt.Errorf("スケジュールのシリアライズに失敗: %w", err)
+		return nil, fmt.Errorf("トランザクション開始に失敗: %w", err)
 	}
+	return tx, nil
+}
 
-	query := `
-		INSERT INTO events (
+// eventColumns は CreateEventParams から書き込むイベントの内容の列です。eventValues と同じ順に並べます。
+const eventColumns = `
 			title, organizer, start_date, start_time, end_date, end_time, email,
-			prefecture, event_type, is_online, is_offline, official_url,
-			online_lecture_url, venue, target, capacity, description, tags,
-			speakers, schedule, auth_code, is_authenticated
-		) VALUES (
-			?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,FALSE
-		)
-	`
-	result, err := tx.ExecContext(ctx, query,
+			prefecture, event_type, delivery_mode, is_online, is_offline, official_url,
+			online_lecture_url, venue, target, capacity, description, description_html, tags,
+			speakers, schedule, recurrence_rule, recurrence_dates, recurrence_exdates,
+			venue_address, venue_postal_code, latitude, longitude, location_precision`
+
+// eventColumnNames は eventColumns の列名の一覧です。
+var eventColumnNames = strings.Fields(strings.ReplaceAll(eventColumns, ",", " "))
+
+// eventValues は eventColumns に書き込む値を返します。JSONの列は Tags などの型がシリアライズします。
+func eventValues(params CreateEventParams) []any {
+	return []any{
 		params.Title,
 		params.Organizer,
 		params.StartDate,
@@ -116,6 +212,7 @@ func (r *Repository) CreateEventTx(ctx context.Context, tx *sql.Tx, params Creat
 		params.Email,
 		params.Prefecture,
 		params.EventType,
+		params.DeliveryMode,
 		params.IsOnline,
 		params.IsOffline,
 		params.OfficialURL,
@@ -124,11 +221,58 @@ func (r *Repository) CreateEventTx(ctx context.Context, tx *sql.Tx, params Creat
 		params.Target,
 		params.Capacity,
 		params.Description,
-		tagsJSON,
-		speakersJSON,
-		scheduleJSON,
-		authCode,
-	)
+		descriptionHTML(params.Description),
+		Tags(params.Tags),
+		Speakers(params.Speakers),
+		Schedules(params.Schedule),
+		params.RecurrenceRule,
+		Dates(params.RecurrenceDates),
+		Dates(params.RecurrenceExDates),
+		params.VenueAddress,
+		params.VenuePostalCode,
+		params.Latitude,
+		params.Longitude,
+		params.LocationPrecision,
+	}
+}
+
+// descriptionHTML は説明文のMarkdownをサニタイズ済みのHTMLに変換します。
+// 読み出すたびに変換しないよう、イベントを書き込むときに一度だけ呼びます。
+func descriptionHTML(description *string) *string {
+	if description == nil {
+		return nil
+	}
+	html, err := markdown.ToHTML(*description)
+	if err != nil {
+		return nil
+	}
+	return &html
+}
+
+// eventSelectColumns は Event に読み込む列です。
+const eventSelectColumns = `
+		id, title, organizer, start_date, start_time, end_date, end_time, email,
+		prefecture, event_type, delivery_mode, is_online, is_offline, official_url,
+		online_lecture_url, venue, target, capacity, description, description_html, tags,
+		speakers, schedule, is_authenticated,
+		recurrence_rule, recurrence_dates, recurrence_exdates,
+		venue_address, venue_postal_code, latitude, longitude, location_precision,
+		poster_image_key, poster_thumbnail_key`
+
+// CreateEventTx はトランザクション内でイベントをINSERTし、生成されたIDと承認用のコードを返します。
+// DBにはコードのハッシュのみを保存するため、平文のコードはこの戻り値でしか得られません。
+// このコードは運営者に通知するもので、イベントの閲覧・編集と承認に使えます。
+func (r *Repository) CreateEventTx(ctx context.Context, tx Tx, params CreateEventParams) (int, string, error) {
+	approvalCode, approvalCodeHash := authcode.Generate()
+
+	query := `
+		INSERT INTO events (` + eventColumns + `,
+			approval_code_hash, approval_code_expires_at, is_authenticated
+		) VALUES (
+			?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,FALSE
+		)
+	`
+	result, err := tx.ExecContext(ctx, query, append(eventValues(params), approvalCodeHash, params.ApprovalCodeExpiresAt.UTC())...)
 	if err != nil {
 		return 0, "", fmt.Errorf("イベントの挿入に失敗: %w", err)
 	}
@@ -138,160 +282,508 @@ func (r *Repository) CreateEventTx(ctx context.Context, tx *sql.Tx, params Creat
 		return 0, "", fmt.Errorf("最後の挿入IDの取得に失敗: %w", err)
 	}
 
-	return int(eventID), authCode, nil
+	return int(eventID), approvalCode.String(), nil
 }
 
-// GetEvents は認証済みのイベント一覧を取得します。
-func (r *Repository) GetEvents(ctx context.Context) ([]*Event, error) {
+// UpsertSeedEventTx はシードのイベントを seedKey で識別して承認済みとして登録し、IDを返します。
+// 同じ seedKey のイベントがあれば内容を上書きするため、何度実行しても重複しません。
+func (r *Repository) UpsertSeedEventTx(ctx context.Context, tx Tx, seedKey string, params CreateEventParams) (int, error) {
+	// 承認済みとして登録するため、承認用のコードは発行しない
 	query := `
-		SELECT id, title, organizer, start_date, start_time, end_date, end_time, email,
-		       prefecture, event_type, is_online, is_offline, official_url,
-		       online_lecture_url, venue, target, capacity, description, tags,
-		       speakers, schedule, is_authenticated
+		INSERT INTO events (` + eventColumns + `,
+			is_authenticated, seed_key
+		) VALUES (
+			?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,TRUE,?
+		)
+	` + r.onConflictUpdate([]string{"seed_key"}, append(eventColumnNames, "is_authenticated")...)
+	args := append(eventValues(params), seedKey)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("シードのイベントの登録に失敗: %w", err)
+	}
+
+	// 更新した場合は LastInsertId が既存の行を指さないため、seed_key で引き直す
+	var eventID int
+	if err := tx.GetContext(ctx, &eventID, `SELECT id FROM events WHERE seed_key = ?`, seedKey); err != nil {
+		return 0, fmt.Errorf("シードのイベントの取得に失敗: %w", err)
+	}
+
+	return eventID, nil
+}
+
+// GetEvents は認証済みのイベント一覧を取得します。params.Status で未承認のイベントも取得できます。
+func (r *Repository) GetEvents(ctx context.Context, params GetEventsParams) ([]*Event, error) {
+	query, args := eventsQuery(params)
+	var events []*Event
+	if err := r.db.SelectContext(ctx, &events, query, args...); err != nil {
+		return nil, fmt.Errorf("イベントの取得に失敗: %w", err)
+	}
+
+	if err := r.attachOccurrenceOverrides(ctx, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// eventsQuery は GetEvents のクエリを組み立てます。
+// 公開する一覧は migration の idx_events_listing・idx_events_location を使い、全件を走査しないようにしています。
+func eventsQuery(params GetEventsParams) (string, []any) {
+	query := `SELECT` + eventSelectColumns + `
 		FROM events
-		WHERE is_authenticated = TRUE
-		ORDER BY start_date, start_time
+		WHERE TRUE
 	`
-	rows, err := r.db.QueryContext(ctx, query)
+	var args []interface{}
+	switch params.Status {
+	case "", StatusApproved:
+		query += ` AND is_authenticated = TRUE`
+	case StatusPending:
+		query += ` AND is_authenticated = FALSE`
+	}
+	if params.From != "" && params.To != "" {
+		query += `
+		  AND (recurrence_rule IS NOT NULL OR recurrence_dates IS NOT NULL
+		       OR (end_date >= ? AND start_date <= ?))
+		`
+		args = append(args, params.From, params.To)
+	}
+	if params.Bounds != nil {
+		query += `
+		  AND latitude BETWEEN ? AND ?
+		  AND longitude BETWEEN ? AND ?
+		`
+		args = append(args, params.Bounds.MinLat, params.Bounds.MaxLat, params.Bounds.MinLng, params.Bounds.MaxLng)
+	}
+	query += ` ORDER BY start_date, start_time`
+
+	return query, args
+}
+
+var errAuthCodeMismatch = errors.New("指定されたIDと認証コードに一致する有効なイベントが見つかりません")
+
+const verifyAuthCodeQuery = `
+	SELECT COALESCE(auth_code_hash, ''),
+	       (auth_code_expires_at IS NOT NULL AND auth_code_expires_at > ?),
+	       COALESCE(approval_code_hash, ''),
+	       (approval_code_expires_at IS NOT NULL AND approval_code_expires_at > ?)
+	FROM events
+	WHERE id = ?
+`
+
+// VerifyAuthCode は id のイベントに対して authCode が有効かを確認します。
+// 主催者に発行した認証コードと、運営者に通知した承認用のコードのどちらでも閲覧・編集できます。
+// ハッシュの比較は定数時間で行い、有効期限切れのコードは無効として扱います。
+func (r *Repository) VerifyAuthCode(ctx context.Context, id int, authCode uuid.UUID) (bool, error) {
+	var authHash, approvalHash string
+	var authUnexpired, approvalUnexpired bool
+	now := time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, verifyAuthCodeQuery, now, now, id).Scan(&authHash, &authUnexpired, &approvalHash, &approvalUnexpired)
 	if err != nil {
-		return nil, fmt.Errorf("イベントの取得に失敗: %w", err)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("認証コードの取得に失敗: %w", err)
 	}
-	defer rows.Close()
 
-	var events []*Event
-	for rows.Next() {
-		var event Event
-		var tagsJSON, speakersJSON, scheduleJSON []byte
-
-		if err := rows.Scan(
-			&event.ID,
-			&event.Title,
-			&event.Organizer,
-			&event.StartDate,
-			&event.StartTime,
-			&event.EndDate,
-			&event.EndTime,
-			&event.Email,
-			&event.Prefecture,
-			&event.EventType,
-			&event.IsOnline,
-			&event.IsOffline,
-			&event.OfficialURL,
-			&event.OnlineLectureURL,
-			&event.Venue,
-			&event.Target,
-			&event.Capacity,
-			&event.Description,
-			&tagsJSON,
-			&speakersJSON,
-			&scheduleJSON,
-			&event.IsAuthenticated,
-		); err != nil {
-			return nil, fmt.Errorf("イベントのスキャンに失敗: %w", err)
+	authOK := authUnexpired && authcode.Equal(authHash, authCode)
+	approvalOK := approvalUnexpired && authcode.Equal(approvalHash, authCode)
+	return authOK || approvalOK, nil
+}
+
+// AuthenticateEvent は id と承認用のコードが一致するイベントを認証済みに更新します。
+// コードの確認は UPDATE の条件で行い、確認と更新の間に承認済みになったイベントを二重に承認しないようにします。
+// RotateAuthCode で主催者に発行したコードと、承認済みのイベントは更新しないため、エラーになります。
+func (r *Repository) AuthenticateEvent(ctx context.Context, id int, authCode uuid.UUID) error {
+	if authCode == uuid.Nil {
+		return errAuthCodeMismatch
+	}
+
+	query := `
+		UPDATE events
+		SET is_authenticated = TRUE
+		WHERE id = ?
+		  AND approval_code_hash = ?
+		  AND approval_code_expires_at > ?
+		  AND is_authenticated = FALSE
+	`
+	result, err := r.db.ExecContext(ctx, query, id, authcode.Hash(authCode), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("イベント認証の更新に失敗: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("イベント認証の更新に失敗: %w", err)
+	}
+	if n == 0 {
+		return errAuthCodeMismatch
+	}
+	return nil
+}
+
+// RotateAuthCode は email がイベントの連絡先と一致する場合に主催者の認証コードを発行し直し、以前の認証コードを無効化します。
+// 新しいコードはイベントの閲覧・編集にだけ使え、承認には使えません。運営者に通知した承認用のコードは変更しません。
+// イベントが存在しない、またはメールアドレスが一致しない場合は空文字列を返します。
+func (r *Repository) RotateAuthCode(ctx context.Context, id int, email string, expiresAt time.Time) (string, error) {
+	var registered string
+	err := r.db.QueryRowContext(ctx, `SELECT email FROM events WHERE id = ?`, id).Scan(&registered)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
 		}
+		return "", fmt.Errorf("イベントの取得に失敗: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare(
+		[]byte(strings.ToLower(strings.TrimSpace(registered))),
+		[]byte(strings.ToLower(strings.TrimSpace(email))),
+	) != 1 {
+		return "", nil
+	}
 
-		// JSONフィールドのデシリアライズ
-		if err := json.Unmarshal(tagsJSON, &event.Tags); err != nil {
-			return nil, fmt.Errorf("タグのデシリアライズに失敗: %w", err)
+	authCode, authCodeHash := authcode.Generate()
+	query := `
+		UPDATE events
+		SET auth_code_hash = ?, auth_code_expires_at = ?
+		WHERE id = ?
+	`
+	if _, err := r.db.ExecContext(ctx, query, authCodeHash, expiresAt.UTC(), id); err != nil {
+		return "", fmt.Errorf("認証コードの更新に失敗: %w", err)
+	}
+
+	return authCode.String(), nil
+}
+
+// GetEvent は指定されたIDとオプションのauthCodeに基づいてイベントを1件取得します。
+// 未認証のイベントは、有効期限内の正しいauthCodeが指定された場合のみ返します。
+func (r *Repository) GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*Event, error) {
+	event, err := r.GetEventForReview(ctx, id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	if !event.IsAuthenticated {
+		ok, err := r.VerifyAuthCode(ctx, id, authCode)
+		if err != nil {
+			return nil, err
 		}
-		if err := json.Unmarshal(speakersJSON, &event.Speakers); err != nil {
-			return nil, fmt.Errorf("スピーカーのデシリアライズに失敗: %w", err)
+		if !ok {
+			return nil, nil
 		}
-		if err := json.Unmarshal(scheduleJSON, &event.Schedule); err != nil {
-			return nil, fmt.Errorf("スケジュールのデシリアライズに失敗: %w", err)
+	}
+	return event, nil
+}
+
+// GetEventForReview は承認状態や認証コードに関係なくイベントを1件取得します。
+// 未承認のイベントも返すため、管理用のコマンドでのみ使ってください。
+func (r *Repository) GetEventForReview(ctx context.Context, id int) (*Event, error) {
+	var event Event
+	err := r.db.GetContext(ctx, &event, `SELECT`+eventSelectColumns+` FROM events WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("イベントの取得に失敗: %w", err)
+	}
+
+	if err := r.attachOccurrenceOverrides(ctx, []*Event{&event}); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
 
-		events = append(events, &event)
+// SetEventApproved は認証コードを確かめずにイベントを承認済みにします。管理用のコマンドでのみ使ってください。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (r *Repository) SetEventApproved(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE events SET is_authenticated = TRUE WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("イベント認証の更新に失敗: %w", err)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("イベント行の処理中にエラー発生: %w", err)
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("更新件数の取得に失敗: %w", err)
 	}
-	return events, nil
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
-// AuthenticateEvent は id と auth_code が一致するイベントを認証済みに更新します。
-func (r *Repository) AuthenticateEvent(ctx context.Context, id int, authCode uuid.UUID) error {
+// ResetApprovalTx はトランザクション内で承認済みのイベントを未承認に戻し、運営者に通知する承認用のコードを発行し直して返します。
+// 主催者が承認後に内容を書き換えたときに、運営者に確認し直してもらうために使います。
+// authCode が有効な承認用のコードなら運営者による編集として承認を残し、未承認のイベントと同じく空文字列を返します。
+func (r *Repository) ResetApprovalTx(ctx context.Context, tx Tx, id int, authCode uuid.UUID, expiresAt time.Time) (string, error) {
+	approvalCode, approvalCodeHash := authcode.Generate()
 	query := `
 		UPDATE events
-		SET is_authenticated = TRUE
-		WHERE id = ? AND auth_code = ?
+		SET is_authenticated = FALSE, approval_code_hash = ?, approval_code_expires_at = ?
+		WHERE id = ?
+		  AND is_authenticated = TRUE
+		  AND (approval_code_hash IS NULL OR approval_code_hash <> ? OR approval_code_expires_at <= ?)
 	`
-	result, err := r.db.ExecContext(ctx, query, id, authCode)
+	result, err := tx.ExecContext(ctx, query, approvalCodeHash, expiresAt.UTC(), id, authcode.Hash(authCode), time.Now().UTC())
 	if err != nil {
-		return fmt.Errorf("イベント認証の更新に失敗: %w", err)
+		return "", fmt.Errorf("イベント承認の取り消しに失敗: %w", err)
 	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("更新件数の取得に失敗: %w", err)
+	}
+	if n == 0 {
+		return "", nil
+	}
+	return approvalCode.String(), nil
+}
 
-	rowsAffected, err := result.RowsAffected()
+// SetEventLocation は座標がまだないイベントに、ジオコーダーで求めた座標を設定します。
+// 途中で主催者が座標を指定した場合に上書きしないよう、座標があるイベントは更新しません。
+// イベントが存在しないか、すでに座標がある場合は sql.ErrNoRows を返します。
+func (r *Repository) SetEventLocation(ctx context.Context, id int, lat, lng float64, precision string) error {
+	query := `
+		UPDATE events
+		SET latitude = ?, longitude = ?, location_precision = ?
+		WHERE id = ? AND (latitude IS NULL OR longitude IS NULL)
+	`
+	result, err := r.db.ExecContext(ctx, query, lat, lng, precision, id)
 	if err != nil {
-		return fmt.Errorf("更新された行数の取得に失敗: %w", err)
+		return fmt.Errorf("イベントの位置情報の更新に失敗: %w", err)
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("指定されたIDと認証コードに一致するイベントが見つかりません")
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("更新件数の取得に失敗: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
 	}
 	return nil
 }
 
-// GetEvent は指定されたIDとオプションのauthCodeに基づいてイベントを1件取得します。
-func (r *Repository) GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*Event, error) {
-	query := `
-		SELECT id, title, organizer, start_date, start_time, end_date, end_time, email,
-		       prefecture, event_type, is_online, is_offline, official_url,
-		       online_lecture_url, venue, target, capacity, description, tags,
-		       speakers, schedule, is_authenticated
+// SetPosterImage はイベントのポスター画像を設定し、置き換えられる前の画像のキーを返します。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (r *Repository) SetPosterImage(ctx context.Context, id int, imageKey, thumbnailKey string) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldImage, oldThumbnail sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT poster_image_key, poster_thumbnail_key
 		FROM events
-		WHERE id = ? AND (is_authenticated = TRUE OR auth_code = ?)
+		WHERE id = ?
+	`+r.forUpdate(), id).Scan(&oldImage, &oldThumbnail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ポスター画像の取得に失敗: %w", err)
+	}
+
+	query := `
+		UPDATE events
+		SET poster_image_key = ?, poster_thumbnail_key = ?
+		WHERE id = ?
 	`
-	var event Event
-	var tagsJSON, speakersJSON, scheduleJSON []byte
-
-	var code interface{}
-	if authCode != uuid.Nil {
-		code = authCode
-	} else {
-		code = sql.NullString{}
-	}
-
-	err := r.db.QueryRowContext(ctx, query, id, code).Scan(
-		&event.ID,
-		&event.Title,
-		&event.Organizer,
-		&event.StartDate,
-		&event.StartTime,
-		&event.EndDate,
-		&event.EndTime,
-		&event.Email,
-		&event.Prefecture,
-		&event.EventType,
-		&event.IsOnline,
-		&event.IsOffline,
-		&event.OfficialURL,
-		&event.OnlineLectureURL,
-		&event.Venue,
-		&event.Target,
-		&event.Capacity,
-		&event.Description,
-		&tagsJSON,
-		&speakersJSON,
-		&scheduleJSON,
-		&event.IsAuthenticated,
-	)
+	if _, err := tx.ExecContext(ctx, query, imageKey, thumbnailKey, id); err != nil {
+		return nil, fmt.Errorf("ポスター画像の更新に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	return nonEmptyKeys(oldImage.String, oldThumbnail.String), nil
+}
+
+// SetSpeakerPhoto は index 番目のスピーカーの顔写真を設定し、置き換えられる前の写真のキーを返します。
+// イベントまたはスピーカーが存在しない場合は sql.ErrNoRows を返します。
+func (r *Repository) SetSpeakerPhoto(ctx context.Context, id, index int, photoKey, thumbnailKey string) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer tx.Rollback()
+
+	var speakers Speakers
+	err = tx.QueryRowContext(ctx, `SELECT speakers FROM events WHERE id = ?`+r.forUpdate(), id).Scan(&speakers)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, err
+		}
+		return nil, fmt.Errorf("スピーカーの取得に失敗: %w", err)
+	}
+	if index < 0 || index >= len(speakers) {
+		return nil, sql.ErrNoRows
+	}
+
+	old := nonEmptyKeys(speakers[index].PhotoKey, speakers[index].PhotoThumbnailKey)
+	speakers[index].PhotoKey = photoKey
+	speakers[index].PhotoThumbnailKey = thumbnailKey
+
+	if _, err := tx.ExecContext(ctx, `UPDATE events SET speakers = ? WHERE id = ?`, speakers, id); err != nil {
+		return nil, fmt.Errorf("スピーカーの更新に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	return old, nil
+}
+
+// UpdateEvent はイベントの内容を params で置き換え、不要になった画像のキーを返します。
+// スピーカーの顔写真は名前が一致するスピーカーに引き継ぎ、いなくなったスピーカーの写真のキーを返します。
+// 認証コードと承認状態は変更しないため、params.ApprovalCodeExpiresAt は使いません。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (r *Repository) UpdateEvent(ctx context.Context, id int, params CreateEventParams) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldSpeakers Speakers
+	err = tx.QueryRowContext(ctx, `SELECT speakers FROM events WHERE id = ?`+r.forUpdate(), id).Scan(&oldSpeakers)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("スピーカーの取得に失敗: %w", err)
+	}
+
+	var orphaned []string
+	params.Speakers, orphaned = carrySpeakerPhotos(oldSpeakers, params.Speakers)
+
+	query := `UPDATE events SET ` + strings.Join(eventColumnNames, " = ?, ") + ` = ? WHERE id = ?`
+	_, err = tx.ExecContext(ctx, query, append(eventValues(params), id)...)
+	if err != nil {
+		return nil, fmt.Errorf("イベントの更新に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	return orphaned, nil
+}
+
+// DeleteEvent はイベントを削除し、イベントが持っていた画像のキーを返します。
+// 各回の上書きと参加登録は外部キーにより一緒に削除されます。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (r *Repository) DeleteEvent(ctx context.Context, id int) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer tx.Rollback()
+
+	var posterImage, posterThumbnail sql.NullString
+	var speakers Speakers
+	err = tx.QueryRowContext(ctx, `
+		SELECT poster_image_key, poster_thumbnail_key, speakers
+		FROM events
+		WHERE id = ?
+	`+r.forUpdate(), id).Scan(&posterImage, &posterThumbnail, &speakers)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
 		}
 		return nil, fmt.Errorf("イベントの取得に失敗: %w", err)
 	}
 
-	// JSONフィールドのデシリアライズ
-	if err := json.Unmarshal(tagsJSON, &event.Tags); err != nil {
-		return nil, fmt.Errorf("タグのデシリアライズに失敗: %w", err)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id = ?`, id); err != nil {
+		return nil, fmt.Errorf("イベントの削除に失敗: %w", err)
 	}
-	if err := json.Unmarshal(speakersJSON, &event.Speakers); err != nil {
-		return nil, fmt.Errorf("スピーカーのデシリアライズに失敗: %w", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
 	}
-	if err := json.Unmarshal(scheduleJSON, &event.Schedule); err != nil {
-		return nil, fmt.Errorf("スケジュールのデシリアライズに失敗: %w", err)
+
+	keys := nonEmptyKeys(posterImage.String, posterThumbnail.String)
+	for _, s := range speakers {
+		keys = append(keys, nonEmptyKeys(s.PhotoKey, s.PhotoThumbnailKey)...)
 	}
+	return keys, nil
+}
 
-	return &event, nil
+// carrySpeakerPhotos は更新前のスピーカーの顔写真を、名前が一致する更新後のスピーカーに引き継ぎます。
+// 引き継ぐ先のない写真のキーを orphaned として返します。
+func carrySpeakerPhotos(oldSpeakers, newSpeakers []Speaker) (speakers []Speaker, orphaned []string) {
+	speakers = make([]Speaker, len(newSpeakers))
+	copy(speakers, newSpeakers)
+	photos := make(map[string]Speaker, len(oldSpeakers))
+	for _, s := range oldSpeakers {
+		if _, ok := photos[s.Name]; !ok && s.PhotoKey != "" {
+			photos[s.Name] = s
+		}
+	}
+	for i, s := range speakers {
+		if old, ok := photos[s.Name]; ok {
+			speakers[i].PhotoKey = old.PhotoKey
+			speakers[i].PhotoThumbnailKey = old.PhotoThumbnailKey
+			delete(photos, s.Name)
+		}
+	}
+	for _, s := range photos {
+		orphaned = append(orphaned, nonEmptyKeys(s.PhotoKey, s.PhotoThumbnailKey)...)
+	}
+	return speakers, orphaned
+}
+
+func nonEmptyKeys(keys ...string) []string {
+	var res []string
+	for _, k := range keys {
+		if k != "" {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+// UpsertOccurrenceOverride はシリーズ内の1回分の上書きを登録・更新します。
+func (r *Repository) UpsertOccurrenceOverride(ctx context.Context, o OccurrenceOverride) error {
+	query := `
+		INSERT INTO event_occurrence_overrides (
+			event_id, occurrence_date, title, start_date, start_time, end_date, end_time,
+			venue, is_cancelled
+		) VALUES (
+			:event_id, :occurrence_date, :title, :start_date, :start_time, :end_date, :end_time,
+			:venue, :is_cancelled
+		)
+	` + r.onConflictUpdate([]string{"event_id", "occurrence_date"},
+		"title", "start_date", "start_time", "end_date", "end_time", "venue", "is_cancelled")
+	if _, err := r.db.NamedExecContext(ctx, query, o); err != nil {
+		return fmt.Errorf("繰り返しの上書きの保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// attachOccurrenceOverrides はシリーズのイベントに上書きを読み込みます。
+func (r *Repository) attachOccurrenceOverrides(ctx context.Context, events []*Event) error {
+	byID := make(map[int]*Event)
+	var ids []int
+	for _, e := range events {
+		if e.IsSeries() {
+			byID[e.ID] = e
+			ids = append(ids, e.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT event_id, occurrence_date, title, start_date, start_time, end_date, end_time,
+		       venue, is_cancelled
+		FROM event_occurrence_overrides
+		WHERE event_id IN (?)
+		ORDER BY event_id, occurrence_date
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("繰り返しの上書きのクエリ作成に失敗: %w", err)
+	}
+
+	var overrides []OccurrenceOverride
+	if err := r.db.SelectContext(ctx, &overrides, r.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("繰り返しの上書きの取得に失敗: %w", err)
+	}
+	for _, o := range overrides {
+		e := byID[o.EventID]
+		e.Overrides = append(e.Overrides, o)
+	}
+	return nil
 }
@@ -7,22 +7,30 @@ import (
 	"os"
 	"testing"
 
-	_ "github.com/go-sql-driver/mysql" // MySQL/MariaDBドライバ
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ras0q/go-backend-template/internal/migration"
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
 	"github.com/ras0q/go-backend-template/internal/repository"
 )
 
-// テスト用のDBへの接続文字列（環境変数などから取得する想定）
+// テスト用のMySQLへの接続文字列です。空の場合はSQLiteで実行します。
 var dsn = os.Getenv("TEST_DB_DSN")
 
-func setupTestDB(t *testing.T) *sql.DB {
+// setupTestDB はテスト用のDBを用意します。TEST_DB_DSN があればそのMySQLを、
+// なければメモリ上のSQLiteにマイグレーションを適用して使います。
+func setupTestDB(t *testing.T) *sqlx.DB {
 	if dsn == "" {
-		// 必要に応じて DSN が無い場合はスキップ or fail
-		t.Fatal("TEST_DB_DSN is not set")
+		db, err := database.OpenSQLite(":memory:")
+		require.NoError(t, err, "failed to open sqlite")
+		require.NoError(t, migration.Up(db), "failed to migrate")
+		return db
 	}
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := sqlx.Open(database.MySQL, dsn)
 	require.NoError(t, err, "failed to open db")
 
 	// テーブル作成など準備
@@ -39,6 +47,7 @@ func setupTestDB(t *testing.T) *sql.DB {
         email VARCHAR(255) NOT NULL,
         prefecture VARCHAR(255),
         event_type VARCHAR(255),
+        delivery_mode VARCHAR(16) NOT NULL DEFAULT 'offline',
         is_online BOOLEAN DEFAULT FALSE,
         is_offline BOOLEAN DEFAULT FALSE,
         official_url VARCHAR(255),
@@ -50,8 +59,20 @@ func setupTestDB(t *testing.T) *sql.DB {
         tags JSON,
         speakers JSON,
         schedule JSON,
-        auth_code VARCHAR(36),
-        is_authenticated BOOLEAN DEFAULT FALSE
+        auth_code_hash CHAR(64),
+        auth_code_expires_at DATETIME,
+        is_authenticated BOOLEAN DEFAULT FALSE,
+        recurrence_rule VARCHAR(255),
+        recurrence_dates JSON,
+        recurrence_exdates JSON,
+        venue_address VARCHAR(255),
+        venue_postal_code VARCHAR(8),
+        latitude DOUBLE,
+        longitude DOUBLE,
+        location_precision VARCHAR(16),
+        poster_image_key VARCHAR(255),
+        poster_thumbnail_key VARCHAR(255),
+        seed_key VARCHAR(64) UNIQUE
     );
   `)
 	require.NoError(t, err, "failed to create table")
@@ -63,6 +84,42 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// setupMigratedDB はマイグレーションをすべて適用したテスト用のDBを用意します。
+// TEST_DB_DSN があればそのサーバーに name の使い捨てのデータベースを作り、なければメモリ上のSQLiteを使います。
+// setupTestDB の簡易なテーブルと違い、本番と同じ制約とインデックスを持ちます。
+func setupMigratedDB(t *testing.T, name string) *sqlx.DB {
+	t.Helper()
+	if dsn == "" {
+		db, err := database.OpenSQLite(":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, migration.Up(db))
+		return db
+	}
+
+	root, err := sqlx.Open(database.MySQL, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { root.Close() })
+
+	_, err = root.Exec("DROP DATABASE IF EXISTS " + name)
+	require.NoError(t, err)
+	_, err = root.Exec("CREATE DATABASE " + name)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = root.Exec("DROP DATABASE IF EXISTS " + name) })
+
+	c, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	c.DBName = name
+	// EXPLAIN にプレースホルダの値を埋め込んで渡せるようにする
+	c.InterpolateParams = true
+	db, err := sqlx.Open(database.MySQL, c.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, migration.Up(db))
+	return db
+}
+
 func TestRepository_CreateEventTx(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -76,13 +133,15 @@ func TestRepository_CreateEventTx(t *testing.T) {
 
 	// パラメータ準備
 	params := repository.CreateEventParams{
-		Title:     "Test Event",
-		Organizer: "Test Organizer",
-		StartDate: "2025-01-01",
-		StartTime: "09:00:00",
-		EndDate:   "2025-01-01",
-		EndTime:   "10:00:00",
-		Email:     "test@example.com",
+		Title:        "Test Event",
+		Organizer:    "Test Organizer",
+		StartDate:    "2025-01-01",
+		StartTime:    "09:00:00",
+		EndDate:      "2025-01-01",
+		EndTime:      "10:00:00",
+		Email:        "test@example.com",
+		DeliveryMode: "offline",
+		IsOffline:    true,
 		// ほかのフィールドは省略
 	}
 
@@ -121,10 +180,115 @@ func TestRepository_GetEvents(t *testing.T) {
   `)
 	require.NoError(t, err)
 
-	events, err := repo.GetEvents(ctx)
+	events, err := repo.GetEvents(ctx, repository.GetEventsParams{})
 	require.NoError(t, err)
 
 	// is_authenticated=TRUE のレコードのみ返るはず => 1件だけ
 	require.Len(t, events, 1)
 	require.Equal(t, "Event1", events[0].Title)
+
+	pending, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusPending})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "Event2", pending[0].Title)
+
+	all, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	// 管理用のコマンドからは認証コードなしで承認できる
+	require.NoError(t, repo.SetEventApproved(ctx, pending[0].ID))
+	event, err := repo.GetEventForReview(ctx, pending[0].ID)
+	require.NoError(t, err)
+	require.True(t, event.IsAuthenticated)
+	require.ErrorIs(t, repo.SetEventApproved(ctx, 0), sql.ErrNoRows)
+}
+
+func TestRepository_UpsertSeedEventTx(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.New(db)
+	ctx := context.Background()
+
+	params := repository.CreateEventParams{
+		Title:        "Seed Event",
+		Organizer:    "Seed Organizer",
+		StartDate:    "2025-01-01",
+		StartTime:    "09:00:00",
+		EndDate:      "2025-01-01",
+		EndTime:      "10:00:00",
+		Email:        "seed@example.com",
+		DeliveryMode: "offline",
+		IsOffline:    true,
+	}
+	upsert := func(params repository.CreateEventParams) int {
+		tx, err := repo.BeginTx(ctx)
+		require.NoError(t, err)
+		id, err := repo.UpsertSeedEventTx(ctx, tx, "seed-event", params)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		return id
+	}
+
+	id := upsert(params)
+	params.Title = "Seed Event (updated)"
+	// 同じ seed_key なら同じ行を更新する
+	require.Equal(t, id, upsert(params))
+
+	events, err := repo.GetEvents(ctx, repository.GetEventsParams{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "Seed Event (updated)", events[0].Title)
+}
+
+func TestRepository_UpdateEvent(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.New(db)
+	ctx := context.Background()
+
+	// 顔写真付きのスピーカーを2人持つイベントを用意する
+	result, err := db.Exec(`
+    INSERT INTO events (title, organizer, start_date, start_time, end_date, end_time,
+                        email, speakers, poster_image_key, is_authenticated)
+    VALUES ('Event1','Org1','2025-01-01','09:00:00','2025-01-01','10:00:00',
+            'test1@example.com',
+            '[{"name":"A","photoKey":"a.jpg","photoThumbnailKey":"a-thumb.jpg"},{"name":"B","photoKey":"b.jpg"}]',
+            'poster.jpg', TRUE)
+  `)
+	require.NoError(t, err)
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	orphaned, err := repo.UpdateEvent(ctx, int(id), repository.CreateEventParams{
+		Title:        "Updated",
+		Organizer:    "Org1",
+		StartDate:    "2025-01-02",
+		StartTime:    "09:00:00",
+		EndDate:      "2025-01-02",
+		EndTime:      "10:00:00",
+		Email:        "test1@example.com",
+		DeliveryMode: "offline",
+		IsOffline:    true,
+		Speakers:     []repository.Speaker{{Name: "A"}, {Name: "C"}},
+	})
+	require.NoError(t, err)
+	// いなくなったBの写真だけが不要になる
+	require.Equal(t, []string{"b.jpg"}, orphaned)
+
+	event, err := repo.GetEvent(ctx, int(id), uuid.Nil)
+	require.NoError(t, err)
+	require.Equal(t, "Updated", event.Title)
+	require.True(t, event.IsAuthenticated, "approval must be kept")
+	require.Equal(t, "a.jpg", event.Speakers[0].PhotoKey)
+	require.Empty(t, event.Speakers[1].PhotoKey)
+
+	keys, err := repo.DeleteEvent(ctx, int(id))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"poster.jpg", "a.jpg", "a-thumb.jpg"}, keys)
+
+	_, err = repo.DeleteEvent(ctx, int(id))
+	require.ErrorIs(t, err, sql.ErrNoRows)
 }
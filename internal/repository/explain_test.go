@@ -0,0 +1,143 @@
+package repository_test
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// setupExplainDB は本番と同じインデックスを持つMySQLのデータベースを用意します。
+func setupExplainDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	if dsn == "" {
+		// 実行計画の形式とインデックスの選び方はMySQLに固有
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	return setupMigratedDB(t, "repository_explain_test")
+}
+
+// explain はクエリの実行計画を、EXPLAIN の列名から値への対応として返します。
+func explain(t *testing.T, db *sqlx.DB, query string, args ...any) []map[string]string {
+	t.Helper()
+
+	rows, err := db.Query("EXPLAIN "+query, args...)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+
+	var plan []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		require.NoError(t, rows.Scan(dest...))
+
+		row := make(map[string]string, len(columns))
+		for i, col := range columns {
+			row[strings.ToLower(col)] = values[i].String
+		}
+		plan = append(plan, row)
+	}
+	require.NoError(t, rows.Err())
+	return plan
+}
+
+func TestQueryPlans(t *testing.T) {
+	db := setupExplainDB(t)
+
+	// 未承認の申請がたまった状態を再現する。公開する一覧は一部の行だけを読めばよい
+	var values []string
+	for i := 0; i < 500; i++ {
+		approved := i%20 == 0
+		values = append(values, fmt.Sprintf(
+			"('Event %d','Org','2025-%02d-%02d','09:00:00','2025-%02d-%02d','10:00:00','e%d@example.com','offline',%t,%f,%f,SHA2('%d',256))",
+			i, i%12+1, i%28+1, i%12+1, i%28+1, i, approved, 30+float64(i%10), 130+float64(i%10), i,
+		))
+	}
+	_, err := db.Exec(`
+		INSERT INTO events (title, organizer, start_date, start_time, end_date, end_time, email,
+		                    delivery_mode, is_authenticated, latitude, longitude, approval_code_hash)
+		VALUES ` + strings.Join(values, ","))
+	require.NoError(t, err)
+	_, err = db.Exec(`ANALYZE TABLE events`)
+	require.NoError(t, err)
+
+	t.Run("public listings use an index", func(t *testing.T) {
+		cases := map[string]repository.GetEventsParams{
+			"all approved": {},
+			"date range":   {From: "2025-03-01", To: "2025-03-31"},
+			"nearby":       {Bounds: &geo.Bounds{MinLat: 33, MaxLat: 35, MinLng: 133, MaxLng: 135}},
+		}
+		for name, params := range cases {
+			query, args := repository.EventsQuery(params)
+			for _, row := range explain(t, db, query, args...) {
+				require.NotEqual(t, "ALL", row["type"], "%s: full table scan: %v", name, row)
+				require.NotEmpty(t, row["key"], "%s: no index used: %v", name, row)
+			}
+		}
+
+		// 並べ替えもインデックスの順で済む
+		query, args := repository.EventsQuery(repository.GetEventsParams{})
+		for _, row := range explain(t, db, query, args...) {
+			require.NotContains(t, row["extra"], "filesort", "%v", row)
+		}
+	})
+
+	t.Run("auth code lookups use the primary key", func(t *testing.T) {
+		for _, row := range explain(t, db, repository.VerifyAuthCodeQuery, "2025-01-01 00:00:00", "2025-01-01 00:00:00", 1) {
+			require.Equal(t, "const", row["type"], "%v", row)
+			require.Equal(t, "PRIMARY", row["key"], "%v", row)
+		}
+	})
+
+	t.Run("token lookups use a unique key", func(t *testing.T) {
+		// 参加用リンクのトークンと運営者のトークンは、ハッシュだけで1行を引く
+		_, err := db.Exec(`
+			INSERT INTO event_registrations (event_id, name, email, created_at)
+			SELECT id, 'Attendee', email, '2025-01-01 00:00:00' FROM events`)
+		require.NoError(t, err)
+		_, err = db.Exec(`
+			INSERT INTO event_registration_tokens (registration_id, token_hash, created_at)
+			SELECT id, SHA2(CONCAT('registration', id), 256), '2025-01-01 00:00:00' FROM event_registrations`)
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			_, err = db.Exec(`INSERT INTO admins (email, name, token_hash, created_at) VALUES (?, 'Admin', SHA2(?, 256), '2025-01-01 00:00:00')`,
+				fmt.Sprintf("admin%d@example.com", i), fmt.Sprintf("admin%d", i))
+			require.NoError(t, err)
+		}
+		_, err = db.Exec(`ANALYZE TABLE event_registrations, event_registration_tokens, admins`)
+		require.NoError(t, err)
+
+		var tokenHash string
+		var eventID int
+		require.NoError(t, db.QueryRow(`
+			SELECT t.token_hash, r.event_id
+			FROM event_registration_tokens t
+			JOIN event_registrations r ON r.id = t.registration_id
+			LIMIT 1`).Scan(&tokenHash, &eventID))
+		plan := explain(t, db, repository.VerifyRegistrationQuery, tokenHash, eventID)
+		for _, row := range plan {
+			require.NotEqual(t, "ALL", row["type"], "full table scan: %v", row)
+		}
+		require.Equal(t, "t", plan[0]["table"], "%v", plan)
+		require.Equal(t, "const", plan[0]["type"], "%v", plan[0])
+		require.Equal(t, "uq_event_registration_tokens_hash", plan[0]["key"], "%v", plan[0])
+
+		for _, row := range explain(t, db, repository.AdminByTokenQuery, fmt.Sprintf("%x", sha256.Sum256([]byte("admin0")))) {
+			require.Equal(t, "const", row["type"], "%v", row)
+			require.Equal(t, "uq_admins_token_hash", row["key"], "%v", row)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package repository
+
+// テストからクエリの実行計画を確かめるために公開します。
+var EventsQuery = eventsQuery
+
+const (
+	VerifyAuthCodeQuery     = verifyAuthCodeQuery
+	VerifyRegistrationQuery = verifyRegistrationQuery
+	AdminByTokenQuery       = adminByTokenQuery
+)
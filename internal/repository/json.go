@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONで保存する列の型です。sql.Scanner と driver.Valuer を実装し、
+// クエリの引数やスキャン先にそのまま渡せます。NULLの列は空(nil)として読み込みます。
+
+// Tags はイベントのタグの一覧です。
+type Tags []string
+
+// Speakers はイベントのスピーカーの一覧です。
+type Speakers []Speaker
+
+// Schedules はイベントのスケジュールの一覧です。
+type Schedules []Schedule
+
+// Dates は日付(YYYY-MM-DD)の一覧です。空の場合はNULLとして保存します。
+type Dates []string
+
+func (t Tags) Value() (driver.Value, error) {
+	return jsonValue(t)
+}
+
+func (t *Tags) Scan(src any) error {
+	return scanJSON(src, t)
+}
+
+func (s Speakers) Value() (driver.Value, error) {
+	return jsonValue(s)
+}
+
+func (s *Speakers) Scan(src any) error {
+	return scanJSON(src, s)
+}
+
+func (s Schedules) Value() (driver.Value, error) {
+	return jsonValue(s)
+}
+
+func (s *Schedules) Scan(src any) error {
+	return scanJSON(src, s)
+}
+
+func (d Dates) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return jsonValue(d)
+}
+
+func (d *Dates) Scan(src any) error {
+	return scanJSON(src, d)
+}
+
+func jsonValue(v any) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("JSONのシリアライズに失敗: %w", err)
+	}
+	return b, nil
+}
+
+// scanJSON はJSONの列を dest に読み込みます。NULLや空の値は dest をゼロ値にします。
+func scanJSON[T any](src any, dest *T) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("JSONの列として読み込めない型です: %T", src)
+	}
+
+	var v T
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("JSONのデシリアライズに失敗: %w", err)
+		}
+	}
+	*dest = v
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+func TestJSONColumns_Scan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want repository.Tags
+	}{
+		{name: "NULL", src: nil, want: nil},
+		{name: "empty", src: []byte{}, want: nil},
+		{name: "JSON null", src: []byte("null"), want: nil},
+		{name: "bytes", src: []byte(`["数学","物理"]`), want: repository.Tags{"数学", "物理"}},
+		{name: "string", src: `["数学"]`, want: repository.Tags{"数学"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 前の行の値が残らないこと
+			tags := repository.Tags{"残り"}
+			require.NoError(t, tags.Scan(tt.src))
+			require.Equal(t, tt.want, tags)
+		})
+	}
+
+	var speakers repository.Speakers
+	require.NoError(t, speakers.Scan([]byte(`[{"name":"山田","photoKey":"a.jpg"}]`)))
+	require.Equal(t, repository.Speakers{{Name: "山田", PhotoKey: "a.jpg"}}, speakers)
+
+	var schedules repository.Schedules
+	require.Error(t, schedules.Scan([]byte(`{`)))
+	require.Error(t, schedules.Scan(42))
+}
+
+func TestJSONColumns_Value(t *testing.T) {
+	v, err := repository.Tags{"数学"}.Value()
+	require.NoError(t, err)
+	require.Equal(t, []byte(`["数学"]`), v)
+
+	// 繰り返し日は空ならNULLにする
+	v, err = repository.Dates(nil).Value()
+	require.NoError(t, err)
+	require.Nil(t, v)
+	v, err = repository.Dates{"2025-01-01"}.Value()
+	require.NoError(t, err)
+	require.Equal(t, []byte(`["2025-01-01"]`), v)
+}
@@ -0,0 +1,653 @@
+package repository
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/authcode"
+)
+
+// Memory はDBを使わずにメモリ上で動く Repository の実装です。
+// 認証コードによる公開範囲や並び順、トランザクションのロールバックを Repository と同じように扱うため、
+// テストでモックの代わりに使えます。振る舞いが Repository と一致することは conformance_test.go で確かめています。
+type Memory struct {
+	mu            sync.Mutex
+	lastEventID   int
+	events        map[int]*memoryEvent
+	overrides     map[int]map[string]OccurrenceOverride
+	registrations map[int]map[string][]string
+	admins        []memoryAdmin
+	users         []*User
+}
+
+// memoryAdmin はadminsテーブルの1行です。
+type memoryAdmin struct {
+	admin     Admin
+	tokenHash string
+}
+
+// memoryEvent はeventsテーブルの1行です。Event に含めない列も持ちます。
+type memoryEvent struct {
+	event Event
+	// authCodeHash は主催者に発行した閲覧・編集用のコードです。RotateAuthCode で発行し直します。
+	authCodeHash      string
+	authCodeExpiresAt time.Time
+	// approvalCodeHash は申請時に運営者へ通知した承認用のコードです。
+	approvalCodeHash      string
+	approvalCodeExpiresAt time.Time
+	seedKey               string
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		events:        make(map[int]*memoryEvent),
+		overrides:     make(map[int]map[string]OccurrenceOverride),
+		registrations: make(map[int]map[string][]string),
+	}
+}
+
+// errMemoryTxSQL は Memory のトランザクションでSQLを実行しようとしたときのエラーです。
+var errMemoryTxSQL = errors.New("Memory のトランザクションではSQLを実行できません")
+
+// memoryTx は Memory のトランザクションです。書き込みを ops に溜めておき、Commit でまとめて反映します。
+type memoryTx struct {
+	m        *Memory
+	ops      []func()
+	seedKeys map[string]int
+	done     bool
+}
+
+func (tx *memoryTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errMemoryTxSQL
+}
+
+func (tx *memoryTx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return errMemoryTxSQL
+}
+
+func (tx *memoryTx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	tx.m.mu.Lock()
+	defer tx.m.mu.Unlock()
+	for _, op := range tx.ops {
+		op()
+	}
+	return nil
+}
+
+func (tx *memoryTx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return nil
+}
+
+// BeginTx は新たにトランザクションを開始します。
+func (m *Memory) BeginTx(ctx context.Context) (Tx, error) {
+	return &memoryTx{m: m, seedKeys: make(map[string]int)}, nil
+}
+
+// memoryTxOf は tx が m のトランザクションであることを確かめます。
+func (m *Memory) memoryTxOf(tx Tx) (*memoryTx, error) {
+	mtx, ok := tx.(*memoryTx)
+	if !ok || mtx.m != m {
+		return nil, fmt.Errorf("Memory 以外のトランザクションです: %T", tx)
+	}
+	if mtx.done {
+		return nil, sql.ErrTxDone
+	}
+	return mtx, nil
+}
+
+// nextEventID はイベントのIDを採番します。MySQLのAUTO_INCREMENTと同じく、ロールバックしても番号は戻しません。
+func (m *Memory) nextEventID() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastEventID++
+	return m.lastEventID
+}
+
+// setEventParams は eventColumns に当たる内容を params で置き換えます。
+func setEventParams(e *Event, params CreateEventParams) {
+	e.Title = params.Title
+	e.Organizer = params.Organizer
+	e.StartDate = params.StartDate
+	e.StartTime = params.StartTime
+	e.EndDate = params.EndDate
+	e.EndTime = params.EndTime
+	e.Email = params.Email
+	e.Prefecture = params.Prefecture
+	e.EventType = params.EventType
+	e.DeliveryMode = params.DeliveryMode
+	e.IsOnline = params.IsOnline
+	e.IsOffline = params.IsOffline
+	e.OfficialURL = params.OfficialURL
+	e.OnlineLectureURL = params.OnlineLectureURL
+	e.Venue = params.Venue
+	e.Target = params.Target
+	e.Capacity = params.Capacity
+	e.Description = params.Description
+	e.DescriptionHTML = descriptionHTML(params.Description)
+	e.Tags = params.Tags
+	e.Speakers = params.Speakers
+	e.Schedule = params.Schedule
+	e.RecurrenceRule = params.RecurrenceRule
+	e.RecurrenceDates = params.RecurrenceDates
+	e.RecurrenceExDates = params.RecurrenceExDates
+	e.VenueAddress = params.VenueAddress
+	e.VenuePostalCode = params.VenuePostalCode
+	e.Latitude = params.Latitude
+	e.Longitude = params.Longitude
+	e.LocationPrecision = params.LocationPrecision
+
+	// 空の繰り返し日はNULLとして保存される
+	if len(e.RecurrenceDates) == 0 {
+		e.RecurrenceDates = nil
+	}
+	if len(e.RecurrenceExDates) == 0 {
+		e.RecurrenceExDates = nil
+	}
+}
+
+// cloneEvent は保存しているイベントを呼び出し元が書き換えても影響しないように複製します。
+// JSONの列と同じく、シリアライズして戻すことでスライスとポインタを複製します。
+func cloneEvent(e *Event) (*Event, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("イベントの複製に失敗: %w", err)
+	}
+	var clone Event
+	if err := json.Unmarshal(b, &clone); err != nil {
+		return nil, fmt.Errorf("イベントの複製に失敗: %w", err)
+	}
+	return &clone, nil
+}
+
+// readEvent はイベントを複製し、シリーズであれば上書きを読み込みます。m.mu を持って呼び出してください。
+func (m *Memory) readEvent(row *memoryEvent) (*Event, error) {
+	event, err := cloneEvent(&row.event)
+	if err != nil {
+		return nil, err
+	}
+	event.Overrides = nil
+	if event.IsSeries() {
+		dates := make([]string, 0, len(m.overrides[event.ID]))
+		for date := range m.overrides[event.ID] {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		for _, date := range dates {
+			event.Overrides = append(event.Overrides, m.overrides[event.ID][date])
+		}
+	}
+	return event, nil
+}
+
+// CreateEventTx はトランザクション内でイベントを登録し、採番したIDと認証コードを返します。
+// イベントは Commit するまで他から見えません。
+func (m *Memory) CreateEventTx(ctx context.Context, tx Tx, params CreateEventParams) (int, string, error) {
+	mtx, err := m.memoryTxOf(tx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	approvalCode, approvalCodeHash := authcode.Generate()
+	row := &memoryEvent{
+		approvalCodeHash:      approvalCodeHash,
+		approvalCodeExpiresAt: params.ApprovalCodeExpiresAt.UTC(),
+	}
+	row.event.ID = m.nextEventID()
+	setEventParams(&row.event, params)
+
+	mtx.ops = append(mtx.ops, func() {
+		m.events[row.event.ID] = row
+	})
+	return row.event.ID, approvalCode.String(), nil
+}
+
+// UpsertSeedEventTx はシードのイベントを seedKey で識別して承認済みとして登録し、IDを返します。
+// 同じ seedKey のイベントがあれば内容を上書きし、画像はそのまま残します。
+func (m *Memory) UpsertSeedEventTx(ctx context.Context, tx Tx, seedKey string, params CreateEventParams) (int, error) {
+	mtx, err := m.memoryTxOf(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := mtx.seedKeys[seedKey]
+	if !ok {
+		m.mu.Lock()
+		for _, row := range m.events {
+			if row.seedKey == seedKey {
+				id = row.event.ID
+			}
+		}
+		m.mu.Unlock()
+		if id == 0 {
+			id = m.nextEventID()
+		}
+		mtx.seedKeys[seedKey] = id
+	}
+
+	mtx.ops = append(mtx.ops, func() {
+		row, ok := m.events[id]
+		if !ok {
+			row = &memoryEvent{seedKey: seedKey}
+			row.event.ID = id
+			m.events[id] = row
+		}
+		setEventParams(&row.event, params)
+		row.event.IsAuthenticated = true
+	})
+	return id, nil
+}
+
+// GetEvents は認証済みのイベント一覧を取得します。params.Status で未承認のイベントも取得できます。
+func (m *Memory) GetEvents(ctx context.Context, params GetEventsParams) ([]*Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []*Event
+	for _, row := range m.events {
+		e := &row.event
+		switch params.Status {
+		case "", StatusApproved:
+			if !e.IsAuthenticated {
+				continue
+			}
+		case StatusPending:
+			if e.IsAuthenticated {
+				continue
+			}
+		}
+		if params.From != "" && params.To != "" && !e.IsSeries() &&
+			!(e.EndDate >= params.From && e.StartDate <= params.To) {
+			continue
+		}
+		if b := params.Bounds; b != nil {
+			if e.Latitude == nil || e.Longitude == nil ||
+				*e.Latitude < b.MinLat || *e.Latitude > b.MaxLat ||
+				*e.Longitude < b.MinLng || *e.Longitude > b.MaxLng {
+				continue
+			}
+		}
+
+		event, err := m.readEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.StartDate != b.StartDate {
+			return a.StartDate < b.StartDate
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.ID < b.ID
+	})
+	return events, nil
+}
+
+// VerifyAuthCode は id のイベントに対して authCode が有効かを確認します。
+func (m *Memory) VerifyAuthCode(ctx context.Context, id int, authCode uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.verifyAuthCode(id, authCode), nil
+}
+
+// verifyAuthCode は m.mu を持って呼び出してください。
+func (m *Memory) verifyAuthCode(id int, authCode uuid.UUID) bool {
+	row, ok := m.events[id]
+	if !ok {
+		return false
+	}
+	now := time.Now()
+	authOK := row.authCodeExpiresAt.After(now) && authcode.Equal(row.authCodeHash, authCode)
+	approvalOK := row.approvalCodeExpiresAt.After(now) && authcode.Equal(row.approvalCodeHash, authCode)
+	return authOK || approvalOK
+}
+
+// AuthenticateEvent は id と承認用のコードが一致するイベントを認証済みに更新します。
+func (m *Memory) AuthenticateEvent(ctx context.Context, id int, authCode uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok || !row.approvalCodeExpiresAt.After(time.Now()) || !authcode.Equal(row.approvalCodeHash, authCode) || row.event.IsAuthenticated {
+		return errAuthCodeMismatch
+	}
+	m.events[id].event.IsAuthenticated = true
+	return nil
+}
+
+// RotateAuthCode は email がイベントの連絡先と一致する場合に主催者の認証コードを発行し直し、以前の認証コードを無効化します。
+// 新しいコードは承認には使えず、承認用のコードは変更しません。イベントが存在しない、またはメールアドレスが一致しない場合は空文字列を返します。
+func (m *Memory) RotateAuthCode(ctx context.Context, id int, email string, expiresAt time.Time) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return "", nil
+	}
+	if subtle.ConstantTimeCompare(
+		[]byte(strings.ToLower(strings.TrimSpace(row.event.Email))),
+		[]byte(strings.ToLower(strings.TrimSpace(email))),
+	) != 1 {
+		return "", nil
+	}
+
+	authCode, authCodeHash := authcode.Generate()
+	row.authCodeHash = authCodeHash
+	row.authCodeExpiresAt = expiresAt.UTC()
+	return authCode.String(), nil
+}
+
+// GetEvent は指定されたIDとオプションのauthCodeに基づいてイベントを1件取得します。
+// 未認証のイベントは、有効期限内の正しいauthCodeが指定された場合のみ返します。
+func (m *Memory) GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return nil, nil
+	}
+	if !row.event.IsAuthenticated && !m.verifyAuthCode(id, authCode) {
+		return nil, nil
+	}
+	return m.readEvent(row)
+}
+
+// GetEventForReview は承認状態や認証コードに関係なくイベントを1件取得します。
+func (m *Memory) GetEventForReview(ctx context.Context, id int) (*Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return nil, nil
+	}
+	return m.readEvent(row)
+}
+
+// SetEventApproved は認証コードを確かめずにイベントを承認済みにします。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (m *Memory) SetEventApproved(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	row.event.IsAuthenticated = true
+	return nil
+}
+
+// ResetApprovalTx はトランザクション内で承認済みのイベントを未承認に戻し、運営者に通知する承認用のコードを発行し直して返します。
+// authCode が有効な承認用のコードなら承認を残し、未承認のイベントと同じく空文字列を返します。変更は Commit するまで反映しません。
+func (m *Memory) ResetApprovalTx(ctx context.Context, tx Tx, id int, authCode uuid.UUID, expiresAt time.Time) (string, error) {
+	mtx, err := m.memoryTxOf(tx)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	row, ok := m.events[id]
+	reset := ok && row.event.IsAuthenticated &&
+		!(row.approvalCodeExpiresAt.After(time.Now()) && authcode.Equal(row.approvalCodeHash, authCode))
+	m.mu.Unlock()
+	if !reset {
+		return "", nil
+	}
+
+	approvalCode, approvalCodeHash := authcode.Generate()
+	mtx.ops = append(mtx.ops, func() {
+		row.event.IsAuthenticated = false
+		row.approvalCodeHash = approvalCodeHash
+		row.approvalCodeExpiresAt = expiresAt.UTC()
+	})
+	return approvalCode.String(), nil
+}
+
+// SetEventLocation は座標がまだないイベントに、ジオコーダーで求めた座標を設定します。
+// イベントが存在しないか、すでに座標がある場合は sql.ErrNoRows を返します。
+func (m *Memory) SetEventLocation(ctx context.Context, id int, lat, lng float64, precision string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok || (row.event.Latitude != nil && row.event.Longitude != nil) {
+		return sql.ErrNoRows
+	}
+	row.event.Latitude, row.event.Longitude, row.event.LocationPrecision = &lat, &lng, &precision
+	return nil
+}
+
+// SetPosterImage はイベントのポスター画像を設定し、置き換えられる前の画像のキーを返します。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (m *Memory) SetPosterImage(ctx context.Context, id int, imageKey, thumbnailKey string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	var old []string
+	if row.event.PosterImageKey != nil {
+		old = append(old, nonEmptyKeys(*row.event.PosterImageKey)...)
+	}
+	if row.event.PosterThumbnailKey != nil {
+		old = append(old, nonEmptyKeys(*row.event.PosterThumbnailKey)...)
+	}
+	row.event.PosterImageKey = &imageKey
+	row.event.PosterThumbnailKey = &thumbnailKey
+	return old, nil
+}
+
+// SetSpeakerPhoto は index 番目のスピーカーの顔写真を設定し、置き換えられる前の写真のキーを返します。
+// イベントまたはスピーカーが存在しない場合は sql.ErrNoRows を返します。
+func (m *Memory) SetSpeakerPhoto(ctx context.Context, id, index int, photoKey, thumbnailKey string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok || index < 0 || index >= len(row.event.Speakers) {
+		return nil, sql.ErrNoRows
+	}
+
+	speakers := make([]Speaker, len(row.event.Speakers))
+	copy(speakers, row.event.Speakers)
+	old := nonEmptyKeys(speakers[index].PhotoKey, speakers[index].PhotoThumbnailKey)
+	speakers[index].PhotoKey = photoKey
+	speakers[index].PhotoThumbnailKey = thumbnailKey
+	row.event.Speakers = speakers
+	return old, nil
+}
+
+// UpdateEvent はイベントの内容を params で置き換え、不要になった画像のキーを返します。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (m *Memory) UpdateEvent(ctx context.Context, id int, params CreateEventParams) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	speakers, orphaned := carrySpeakerPhotos(row.event.Speakers, params.Speakers)
+	setEventParams(&row.event, params)
+	row.event.Speakers = speakers
+	return orphaned, nil
+}
+
+// DeleteEvent はイベントを削除し、イベントが持っていた画像のキーを返します。
+// 各回の上書きと参加登録も一緒に削除します。
+// イベントが存在しない場合は sql.ErrNoRows を返します。
+func (m *Memory) DeleteEvent(ctx context.Context, id int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row, ok := m.events[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(m.events, id)
+	delete(m.overrides, id)
+	delete(m.registrations, id)
+
+	var keys []string
+	if row.event.PosterImageKey != nil {
+		keys = append(keys, nonEmptyKeys(*row.event.PosterImageKey)...)
+	}
+	if row.event.PosterThumbnailKey != nil {
+		keys = append(keys, nonEmptyKeys(*row.event.PosterThumbnailKey)...)
+	}
+	for _, s := range row.event.Speakers {
+		keys = append(keys, nonEmptyKeys(s.PhotoKey, s.PhotoThumbnailKey)...)
+	}
+	return keys, nil
+}
+
+// UpsertOccurrenceOverride はシリーズ内の1回分の上書きを登録・更新します。
+func (m *Memory) UpsertOccurrenceOverride(ctx context.Context, o OccurrenceOverride) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.events[o.EventID]; !ok {
+		return fmt.Errorf("繰り返しの上書きの保存に失敗: イベント %d が存在しません", o.EventID)
+	}
+	if m.overrides[o.EventID] == nil {
+		m.overrides[o.EventID] = make(map[string]OccurrenceOverride)
+	}
+	m.overrides[o.EventID][o.OccurrenceDate] = o
+	return nil
+}
+
+// CreateRegistration はイベントへの参加登録を行い、参加用リンクのトークンを発行して返します。
+// 同じメールアドレスで登録済みの場合はトークンを追加し、以前のトークンも使えるままにします。
+func (m *Memory) CreateRegistration(ctx context.Context, params CreateRegistrationParams) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.events[params.EventID]; !ok {
+		return "", fmt.Errorf("参加登録の保存に失敗: イベント %d が存在しません", params.EventID)
+	}
+
+	token, tokenHash := authcode.Generate()
+	if m.registrations[params.EventID] == nil {
+		m.registrations[params.EventID] = make(map[string][]string)
+	}
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+	m.registrations[params.EventID][email] = append(m.registrations[params.EventID][email], tokenHash)
+	return token.String(), nil
+}
+
+// VerifyRegistration はトークンがイベントの参加登録に一致するかを確認します。
+func (m *Memory) VerifyRegistration(ctx context.Context, eventID int, token uuid.UUID) (bool, error) {
+	if token == uuid.Nil {
+		return false, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := authcode.Hash(token)
+	for _, hashes := range m.registrations[eventID] {
+		if slices.Contains(hashes, hash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateAdmin は運営者を作成し、運営者用のトークンを返します。
+func (m *Memory) CreateAdmin(ctx context.Context, params CreateAdminParams) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+	for _, a := range m.admins {
+		if a.admin.Email == email {
+			return "", fmt.Errorf("運営者の作成に失敗: %s は登録済みです", email)
+		}
+	}
+
+	token, tokenHash := authcode.Generate()
+	m.admins = append(m.admins, memoryAdmin{
+		admin:     Admin{ID: len(m.admins) + 1, Email: email, Name: params.Name},
+		tokenHash: tokenHash,
+	})
+	return token.String(), nil
+}
+
+// GetAdminByToken はトークンに一致する運営者を返します。一致しない場合は nil を返します。
+func (m *Memory) GetAdminByToken(ctx context.Context, token uuid.UUID) (*Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, a := range m.admins {
+		if authcode.Equal(a.tokenHash, token) {
+			admin := a.admin
+			return &admin, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *Memory) GetUsers(ctx context.Context) ([]*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	users := make([]*User, len(m.users))
+	for i, u := range m.users {
+		user := *u
+		users[i] = &user
+	}
+	return users, nil
+}
+
+func (m *Memory) CreateUser(ctx context.Context, params CreateUserParams) (uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	userID := uuid.New()
+	m.users = append(m.users, &User{ID: userID, Name: params.Name, Email: params.Email})
+	return userID, nil
+}
+
+func (m *Memory) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.ID == userID {
+			user := *u
+			return &user, nil
+		}
+	}
+	return nil, fmt.Errorf("select user: %w", sql.ErrNoRows)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/authcode"
+)
+
+// CreateRegistrationParams は参加登録に必要なパラメータです。
+type CreateRegistrationParams struct {
+	EventID int
+	Name    string
+	Email   string
+}
+
+// CreateRegistration はイベントへの参加登録を行い、参加用リンクのトークンを発行して返します。
+// 同じメールアドレスで登録済みの場合は、登録内容を変えずにトークンを追加します。
+// 申し込みでは本人確認をしないため、他人が申し込み直しても以前のトークンは無効になりません。
+func (r *Repository) CreateRegistration(ctx context.Context, params CreateRegistrationParams) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer tx.Rollback()
+
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+	now := time.Now().UTC()
+	query := `
+		INSERT INTO event_registrations (event_id, name, email, created_at)
+		VALUES (?, ?, ?, ?)
+	` + r.onConflictDoNothing([]string{"event_id", "email"})
+	if _, err := tx.ExecContext(ctx, query, params.EventID, params.Name, email, now); err != nil {
+		return "", fmt.Errorf("参加登録の保存に失敗: %w", err)
+	}
+
+	var registrationID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM event_registrations WHERE event_id = ? AND email = ?
+	`, params.EventID, email).Scan(&registrationID)
+	if err != nil {
+		return "", fmt.Errorf("参加登録の取得に失敗: %w", err)
+	}
+
+	token, tokenHash := authcode.Generate()
+	query = `
+		INSERT INTO event_registration_tokens (registration_id, token_hash, created_at)
+		VALUES (?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, registrationID, tokenHash, now); err != nil {
+		return "", fmt.Errorf("参加用トークンの保存に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	return token.String(), nil
+}
+
+// verifyRegistrationQuery はトークンのハッシュで参加登録を引きます。uq_event_registration_tokens_hash を使います。
+const verifyRegistrationQuery = `
+	SELECT COUNT(*)
+	FROM event_registration_tokens t
+	JOIN event_registrations r ON r.id = t.registration_id
+	WHERE t.token_hash = ? AND r.event_id = ?
+`
+
+// VerifyRegistration はトークンがイベントの参加登録に一致するかを確認します。
+func (r *Repository) VerifyRegistration(ctx context.Context, eventID int, token uuid.UUID) (bool, error) {
+	if token == uuid.Nil {
+		return false, nil
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, verifyRegistrationQuery, authcode.Hash(token), eventID).Scan(&count); err != nil {
+		return false, fmt.Errorf("参加登録の確認に失敗: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,226 @@
+// Package seed は開発・テスト用のイベントをフィクスチャから読み込んでDBに登録します。
+// 本番のDBにサンプルデータが入らないよう、APP_ENV が development か test の場合のみ実行できます。
+package seed
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/deliverymode"
+	"github.com/ras0q/go-backend-template/internal/pkg/eventtype"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/prefecture"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+//go:embed fixtures/*.yaml
+var fixtures embed.FS
+
+// ErrNotAllowed は development・test 以外の環境でシードを実行しようとしたことを表します。
+var ErrNotAllowed = errors.New("seeding is only allowed when APP_ENV is development or test")
+
+// Allowed は env (APP_ENV) でシードを実行してよいかを返します。
+func Allowed(env string) bool {
+	return env == "development" || env == "test"
+}
+
+// Event はフィクスチャに書くイベントです。キーはAPIの CreateEventRequest に揃えています。
+// Key はシードの識別子で、同じ Key のイベントは何度投入しても1件になります。
+type Event struct {
+	Key               string                `json:"key"`
+	Title             string                `json:"title"`
+	Organizer         string                `json:"organizer"`
+	StartDate         string                `json:"startDate"`
+	StartTime         string                `json:"startTime"`
+	EndDate           string                `json:"endDate"`
+	EndTime           string                `json:"endTime"`
+	Email             string                `json:"email"`
+	Prefecture        *string               `json:"prefecture"`
+	EventType         *string               `json:"eventType"`
+	DeliveryMode      string                `json:"deliveryMode"`
+	OfficialURL       *string               `json:"officialUrl"`
+	OnlineLectureURL  *string               `json:"onlineLectureUrl"`
+	Venue             *string               `json:"venue"`
+	Target            *string               `json:"target"`
+	Capacity          *string               `json:"capacity"`
+	Description       *string               `json:"description"`
+	Tags              []string              `json:"tags"`
+	Speakers          []repository.Speaker  `json:"speakers"`
+	Schedule          []repository.Schedule `json:"schedule"`
+	RecurrenceRule    *string               `json:"recurrenceRule"`
+	RecurrenceDates   []string              `json:"recurrenceDates"`
+	RecurrenceExDates []string              `json:"recurrenceExdates"`
+	VenueAddress      *string               `json:"venueAddress"`
+	VenuePostalCode   *string               `json:"venuePostalCode"`
+	Latitude          *float64              `json:"latitude"`
+	Longitude         *float64              `json:"longitude"`
+}
+
+// Repository はシードの登録に使う永続化の操作です。*repository.Repository と *repository.Memory が実装します。
+type Repository interface {
+	BeginTx(ctx context.Context) (repository.Tx, error)
+	UpsertSeedEventTx(ctx context.Context, tx repository.Tx, seedKey string, params repository.CreateEventParams) (int, error)
+}
+
+var (
+	_ Repository = (*repository.Repository)(nil)
+	_ Repository = (*repository.Memory)(nil)
+)
+
+// Load はフィクスチャを読み込みます。ext が .yaml / .yml ならYAML、.json ならJSONとして解釈します。
+// YAMLもJSONと同じキーで書けるよう、一度JSONに変換してから読み込みます。
+func Load(data []byte, ext string) ([]Event, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("フィクスチャの読み込みに失敗: %w", err)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("フィクスチャの変換に失敗: %w", err)
+		}
+		data = b
+	case ".json":
+	default:
+		return nil, fmt.Errorf("対応していないフィクスチャの形式です: %q", ext)
+	}
+
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("フィクスチャの読み込みに失敗: %w", err)
+	}
+
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		if e.Key == "" {
+			return nil, fmt.Errorf("%s: key がありません", e.Title)
+		}
+		if seen[e.Key] {
+			return nil, fmt.Errorf("key %q が重複しています", e.Key)
+		}
+		seen[e.Key] = true
+	}
+	return events, nil
+}
+
+// LoadFile はファイルからフィクスチャを読み込みます。
+func LoadFile(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("フィクスチャの読み込みに失敗: %w", err)
+	}
+	return Load(data, filepath.Ext(path))
+}
+
+// Defaults は同梱のサンプルイベントを返します。
+func Defaults() ([]Event, error) {
+	data, err := fixtures.ReadFile("fixtures/events.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("フィクスチャの読み込みに失敗: %w", err)
+	}
+	return Load(data, ".yaml")
+}
+
+// Params はシードのイベントを登録用のパラメータに変換します。
+// 都道府県・イベント種別はAPIと同じくコード・IDに揃えます。
+func (e Event) Params() (repository.CreateEventParams, error) {
+	mode, err := deliverymode.Parse(e.DeliveryMode)
+	if err != nil {
+		return repository.CreateEventParams{}, fmt.Errorf("%s: %w", e.Key, err)
+	}
+	isOnline, isOffline := mode.Flags()
+
+	params := repository.CreateEventParams{
+		Title:             e.Title,
+		Organizer:         e.Organizer,
+		StartDate:         e.StartDate,
+		StartTime:         e.StartTime,
+		EndDate:           e.EndDate,
+		EndTime:           e.EndTime,
+		Email:             e.Email,
+		DeliveryMode:      string(mode),
+		IsOnline:          isOnline,
+		IsOffline:         isOffline,
+		OfficialURL:       e.OfficialURL,
+		OnlineLectureURL:  e.OnlineLectureURL,
+		Venue:             e.Venue,
+		Target:            e.Target,
+		Capacity:          e.Capacity,
+		Description:       e.Description,
+		Tags:              e.Tags,
+		Speakers:          e.Speakers,
+		Schedule:          e.Schedule,
+		RecurrenceRule:    e.RecurrenceRule,
+		RecurrenceDates:   e.RecurrenceDates,
+		RecurrenceExDates: e.RecurrenceExDates,
+		VenueAddress:      e.VenueAddress,
+		VenuePostalCode:   e.VenuePostalCode,
+		Latitude:          e.Latitude,
+		Longitude:         e.Longitude,
+	}
+	if e.Prefecture != nil {
+		p, ok := prefecture.Lookup(*e.Prefecture)
+		if !ok {
+			return repository.CreateEventParams{}, fmt.Errorf("%s: 不明な都道府県です: %q", e.Key, *e.Prefecture)
+		}
+		params.Prefecture = &p.Code
+	}
+	if e.EventType != nil {
+		t, ok := eventtype.Lookup(*e.EventType)
+		if !ok {
+			return repository.CreateEventParams{}, fmt.Errorf("%s: 不明なイベント種別です: %q", e.Key, *e.EventType)
+		}
+		params.EventType = &t.ID
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		precision := string(geo.PrecisionExact)
+		params.LocationPrecision = &precision
+	}
+	return params, nil
+}
+
+// Run はシードのイベントを1つのトランザクションで承認済みとして登録し、イベントのIDを返します。
+// env (APP_ENV) が development・test 以外の場合は ErrNotAllowed を返し、何も登録しません。
+func Run(ctx context.Context, repo Repository, env string, events []Event) ([]int, error) {
+	if !Allowed(env) {
+		return nil, ErrNotAllowed
+	}
+
+	params := make([]repository.CreateEventParams, 0, len(events))
+	for _, e := range events {
+		p, err := e.Params()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(params))
+	for i, p := range params {
+		id, err := repo.UpsertSeedEventTx(ctx, tx, events[i].Key, p)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+	return ids, nil
+}
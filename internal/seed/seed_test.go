@@ -0,0 +1,99 @@
+package seed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/seed"
+)
+
+func TestDefaults(t *testing.T) {
+	events, err := seed.Defaults()
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	for _, e := range events {
+		params, err := e.Params()
+		require.NoError(t, err, e.Key)
+		require.NotEmpty(t, params.Title)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	yamlEvents, err := seed.Load([]byte(`
+- key: a
+  title: 集合論入門
+  deliveryMode: hybrid
+  prefecture: 東京都
+  eventType: 講演会
+  latitude: 35.0
+  longitude: 135.0
+  schedule:
+    - startTime: "10:00"
+      title: 開会
+`), ".yaml")
+	require.NoError(t, err)
+	jsonEvents, err := seed.Load([]byte(`[{"key": "a", "title": "集合論入門", "deliveryMode": "hybrid",
+		"prefecture": "東京都", "eventType": "講演会", "latitude": 35.0, "longitude": 135.0,
+		"schedule": [{"startTime": "10:00", "title": "開会"}]}]`), ".json")
+	require.NoError(t, err)
+	require.Equal(t, jsonEvents, yamlEvents)
+
+	params, err := yamlEvents[0].Params()
+	require.NoError(t, err)
+	require.True(t, params.IsOnline)
+	require.True(t, params.IsOffline)
+	require.Equal(t, "13", *params.Prefecture)
+	require.Equal(t, "lecture", *params.EventType)
+	require.Equal(t, "exact", *params.LocationPrecision)
+	require.Equal(t, "10:00", params.Schedule[0].StartTime)
+
+	_, err = seed.Load([]byte(`[{"title": "key なし"}]`), ".json")
+	require.Error(t, err)
+	_, err = seed.Load([]byte(`[{"key": "a"}, {"key": "a"}]`), ".json")
+	require.Error(t, err)
+	_, err = seed.Load([]byte(`[]`), ".toml")
+	require.Error(t, err)
+}
+
+func TestRun_Idempotent(t *testing.T) {
+	events, err := seed.Defaults()
+	require.NoError(t, err)
+
+	repo := repository.NewMemory()
+	ctx := context.Background()
+
+	ids, err := seed.Run(ctx, repo, "development", events)
+	require.NoError(t, err)
+	again, err := seed.Run(ctx, repo, "test", events)
+	require.NoError(t, err)
+	require.Equal(t, ids, again)
+
+	// シードのイベントは承認済みとして公開され、2回目の実行で増えない
+	listed, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+	require.NoError(t, err)
+	require.Len(t, listed, len(events))
+	for _, e := range listed {
+		require.True(t, e.IsAuthenticated)
+	}
+}
+
+func TestRun_NotAllowed(t *testing.T) {
+	events, err := seed.Defaults()
+	require.NoError(t, err)
+
+	for _, env := range []string{"production", "staging", ""} {
+		_, err := seed.Run(context.Background(), repository.NewMemory(), env, events)
+		require.ErrorIs(t, err, seed.ErrNotAllowed, env)
+	}
+}
+
+func TestRun_InvalidDeliveryMode(t *testing.T) {
+	events := []seed.Event{{Key: "a", Title: "不明", DeliveryMode: "teleport"}}
+
+	_, err := seed.Run(context.Background(), repository.NewMemory(), "test", events)
+	require.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// Contact はお問い合わせの内容です。
+type Contact struct {
+	Name    string
+	Email   string
+	Message string
+}
+
+// SubmitContact はお問い合わせをSlackに通知します。
+func (s *Service) SubmitContact(ctx context.Context, contact Contact) error {
+	msg := fmt.Sprintf(
+		"お問い合わせがありました\n"+
+			"名前: %s\n"+
+			"メールアドレス: %s\n"+
+			"メッセージ: %s",
+		contact.Name, contact.Email, contact.Message)
+
+	if err := s.notifier.Post(ctx, msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	return nil
+}
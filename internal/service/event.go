@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// AuthLink は認証コード付きの、フロントエンドの承認ページのURLです。
+func AuthLink(id int, authCode string) string {
+	return fmt.Sprintf("%s/events/update/%d?authcode=%s", config.CORE_FRONTEND_URL, id, authCode)
+}
+
+// SubmitEvent はイベントを未承認の状態で登録し、認証リンクをSlackに通知して、作成したイベントのIDを返します。
+// 通知まで含めて失敗ならROLLBACKするため、運営者が知らないイベントは残りません。
+func (s *Service) SubmitEvent(ctx context.Context, params repository.CreateEventParams) (int, error) {
+	params.ApprovalCodeExpiresAt = s.now().Add(config.AuthCodeTTL())
+
+	// 1) トランザクション開始
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// deferでROLLBACKを仕込む（後で成功時はCommitする）
+	defer tx.Rollback()
+
+	// 2) DB登録
+	eventID, authCode, err := s.repo.CreateEventTx(ctx, tx, params)
+	if err != nil {
+		return 0, err
+	}
+
+	// 3) Slack通知
+	msg := fmt.Sprintf(
+		"新しいイベントが作成されました。\nタイトル: %s\nオーガナイザー: %s\n認証リンク: %s",
+		params.Title, params.Organizer, AuthLink(eventID, authCode),
+	)
+	if err := s.notifier.Post(ctx, msg); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+
+	// 4) COMMIT
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	return eventID, nil
+}
+
+// reviewableEvent は承認・却下の対象となる、未承認のイベントを返します。
+func (s *Service) reviewableEvent(ctx context.Context, id int, authCode uuid.UUID) (*repository.Event, error) {
+	event, err := s.repo.GetEvent(ctx, id, authCode)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	if event.IsAuthenticated {
+		return nil, ErrAlreadyApproved
+	}
+	return event, nil
+}
+
+// ApproveEvent は authCode を確かめてイベントを承認します。
+// 承認に使えるのは申請時に運営者へ通知した承認用のコードだけで、RotateAuthCode で主催者に送ったコードでは
+// ErrInvalidAuthCode を返します。
+func (s *Service) ApproveEvent(ctx context.Context, id int, authCode uuid.UUID) error {
+	if _, err := s.reviewableEvent(ctx, id, authCode); err != nil {
+		return err
+	}
+
+	if err := s.repo.AuthenticateEvent(ctx, id, authCode); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAuthCode, err)
+	}
+	return nil
+}
+
+// RejectEvent はイベントが存在し、未承認であることを確認します。イベントは残したままにします。
+func (s *Service) RejectEvent(ctx context.Context, id int, authCode uuid.UUID) error {
+	_, err := s.reviewableEvent(ctx, id, authCode)
+	return err
+}
+
+// adminReviewableEvent は reviewableEvent の運営者向けで、認証コードを確かめません。
+func (s *Service) adminReviewableEvent(ctx context.Context, id int) (*repository.Event, error) {
+	event, err := s.repo.GetEventForReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	if event.IsAuthenticated {
+		return nil, ErrAlreadyApproved
+	}
+	return event, nil
+}
+
+// ApproveEventByAdmin は運営者の操作として、認証コードなしでイベントを承認します。
+func (s *Service) ApproveEventByAdmin(ctx context.Context, id int) error {
+	if _, err := s.adminReviewableEvent(ctx, id); err != nil {
+		return err
+	}
+
+	err := s.repo.SetEventApproved(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEventNotFound
+	}
+	return err
+}
+
+// RejectEventByAdmin は運営者の操作として、未承認のイベントを却下して削除します。
+// 戻り値はイベントが持っていた画像のキーで、呼び出し元でストレージから削除してください。
+func (s *Service) RejectEventByAdmin(ctx context.Context, id int) ([]string, error) {
+	if _, err := s.adminReviewableEvent(ctx, id); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.DeleteEvent(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEventNotFound
+	}
+	return keys, err
+}
+
+// AuthorizeEvent は authCode を確かめて、主催者として変更できるイベントを返します。
+func (s *Service) AuthorizeEvent(ctx context.Context, id int, authCode uuid.UUID) (*repository.Event, error) {
+	event, err := s.repo.GetEvent(ctx, id, authCode)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+
+	ok, err := s.repo.VerifyAuthCode(ctx, id, authCode)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidAuthCode
+	}
+	return event, nil
+}
+
+// UpdateEvent は authCode を確かめてイベントの内容を params で置き換えます。
+// 主催者が承認済みのイベントを編集した場合は未承認に戻し、運営者が新しい内容を承認し直すまで公開しません。
+// 戻り値は不要になった画像のキーで、呼び出し元でストレージから削除してください。
+func (s *Service) UpdateEvent(ctx context.Context, id int, authCode uuid.UUID, params repository.CreateEventParams) ([]string, error) {
+	event, err := s.AuthorizeEvent(ctx, id, authCode)
+	if err != nil {
+		return nil, err
+	}
+	if event.IsAuthenticated {
+		if err := s.requestReapproval(ctx, id, authCode, params); err != nil {
+			return nil, err
+		}
+	}
+
+	orphaned, err := s.repo.UpdateEvent(ctx, id, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEventNotFound
+	}
+	return orphaned, err
+}
+
+// requestReapproval は承認済みのイベントを未承認に戻し、新しい承認リンクをSlackに通知します。
+// 運営者が承認用のコードで編集した場合は承認を残します。通知に失敗した場合はROLLBACKし、イベントは承認済みのまま残ります。
+func (s *Service) requestReapproval(ctx context.Context, id int, authCode uuid.UUID, params repository.CreateEventParams) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	approvalCode, err := s.repo.ResetApprovalTx(ctx, tx, id, authCode, s.now().Add(config.AuthCodeTTL()))
+	if err != nil || approvalCode == "" {
+		return err
+	}
+
+	msg := fmt.Sprintf(
+		"承認済みのイベントが編集されました。再度承認するまで公開を停止しています。\nタイトル: %s\nオーガナイザー: %s\n認証リンク: %s",
+		params.Title, params.Organizer, AuthLink(id, approvalCode),
+	)
+	if err := s.notifier.Post(ctx, msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+	return nil
+}
+
+// DeleteEvent は authCode を確かめてイベントを削除します。
+// 戻り値はイベントが持っていた画像のキーで、呼び出し元でストレージから削除してください。
+func (s *Service) DeleteEvent(ctx context.Context, id int, authCode uuid.UUID) ([]string, error) {
+	if _, err := s.AuthorizeEvent(ctx, id, authCode); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.DeleteEvent(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEventNotFound
+	}
+	return keys, err
+}
+
+// RotateAuthCode は email がイベントの連絡先と一致する場合に認証コードを発行し直し、新しい認証リンクをメールで送ります。
+// 以前に主催者へ送った認証コードは無効になりますが、運営者に通知した承認用のコードは変わりません。
+// 新しいコードはイベントの編集用で、主催者が自分で承認することはできません。
+// メールアドレスの一致・不一致は呼び出し元に伝えません。
+func (s *Service) RotateAuthCode(ctx context.Context, id int, email string) error {
+	expiresAt := s.now().Add(config.AuthCodeTTL())
+	authCode, err := s.repo.RotateAuthCode(ctx, id, email, expiresAt)
+	if err != nil {
+		return err
+	}
+	if authCode == "" {
+		return nil
+	}
+
+	body := fmt.Sprintf(
+		"イベントの新しい認証リンクを発行しました。\n以前のリンクは使用できなくなります。\nこのリンクではイベントの確認・編集ができます(掲載の承認は運営者が行います)。\n\n認証リンク: %s\n有効期限: %s",
+		AuthLink(id, authCode), expiresAt.Format("2006-01-02 15:04"),
+	)
+	if err := s.mailer.Send(email, "【Math Day】イベント認証リンクの再発行", body); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+var errTest = errors.New("test error")
+
+// submitEvent は未承認のイベントを登録し、IDと認証コードを返します。
+func submitEvent(t *testing.T, repo *repository.Memory, params repository.CreateEventParams) (int, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	if params.ApprovalCodeExpiresAt.IsZero() {
+		params.ApprovalCodeExpiresAt = time.Now().Add(time.Hour)
+	}
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	id, code, err := repo.CreateEventTx(ctx, tx, params)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	return id, uuid.MustParse(code)
+}
+
+// authCodeIn は通知やメールの本文にある認証リンクから認証コードを取り出します。
+func authCodeIn(t *testing.T, text string, id int) uuid.UUID {
+	t.Helper()
+
+	link := service.AuthLink(id, "")
+	i := strings.Index(text, link)
+	require.GreaterOrEqual(t, i, 0, "no auth link in %q", text)
+	code, err := uuid.Parse(strings.Fields(text[i+len(link):])[0])
+	require.NoError(t, err)
+	return code
+}
+
+// failingInsertRepo はイベントのINSERTだけを失敗させ、トランザクションがROLLBACKされたかを記録します。
+type failingInsertRepo struct {
+	*repository.Memory
+	tx *recordingTx
+}
+
+func (r *failingInsertRepo) BeginTx(ctx context.Context) (repository.Tx, error) {
+	tx, err := r.Memory.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.tx = &recordingTx{Tx: tx}
+	return r.tx, nil
+}
+
+func (r *failingInsertRepo) CreateEventTx(ctx context.Context, tx repository.Tx, params repository.CreateEventParams) (int, string, error) {
+	return 0, "", errTest
+}
+
+type recordingTx struct {
+	repository.Tx
+	rolledBack bool
+}
+
+func (tx *recordingTx) Rollback() error {
+	tx.rolledBack = true
+	return tx.Tx.Rollback()
+}
+
+func TestService_SubmitEvent(t *testing.T) {
+	ctx := context.Background()
+	params := repository.CreateEventParams{
+		Title:     "集合論入門",
+		Organizer: "数学の会",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := repository.NewMemory()
+		var message string
+		notifier := service.NotifierFunc(func(ctx context.Context, text string) error {
+			message = text
+			return nil
+		})
+
+		id, err := service.New(repo, notifier, nil).SubmitEvent(ctx, params)
+		require.NoError(t, err)
+		require.Contains(t, message, "タイトル: 集合論入門")
+
+		// 通知した認証リンクのコードで承認前のイベントを見られる
+		event, err := repo.GetEventForReview(ctx, id)
+		require.NoError(t, err)
+		require.False(t, event.IsAuthenticated)
+		pending, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusPending})
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		ok, err := repo.VerifyAuthCode(ctx, id, authCodeIn(t, message, id))
+		require.NoError(t, err)
+		require.True(t, ok, "auth code must be valid and expire in the future")
+	})
+
+	t.Run("notification failure rolls back", func(t *testing.T) {
+		repo := repository.NewMemory()
+		notifier := service.NotifierFunc(func(ctx context.Context, text string) error { return errTest })
+
+		_, err := service.New(repo, notifier, nil).SubmitEvent(ctx, params)
+		require.ErrorIs(t, err, service.ErrNotification)
+
+		events, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("insert failure", func(t *testing.T) {
+		repo := &failingInsertRepo{Memory: repository.NewMemory()}
+		notifier := service.NotifierFunc(func(ctx context.Context, text string) error {
+			t.Fatal("must not notify when the insert fails")
+			return nil
+		})
+
+		_, err := service.New(repo, notifier, nil).SubmitEvent(ctx, params)
+		require.ErrorIs(t, err, errTest)
+		require.True(t, repo.tx.rolledBack)
+	})
+}
+
+func TestService_ApproveEvent(t *testing.T) {
+	repo := repository.NewMemory()
+	s := service.New(repo, nil, nil)
+	ctx := context.Background()
+
+	id, code := submitEvent(t, repo, repository.CreateEventParams{Title: "集合論入門"})
+	rejected, rejectedCode := submitEvent(t, repo, repository.CreateEventParams{Title: "却下"})
+
+	// 未承認のイベントは認証コードがなければ見つからない
+	require.ErrorIs(t, s.ApproveEvent(ctx, id, uuid.New()), service.ErrEventNotFound)
+	require.NoError(t, s.ApproveEvent(ctx, id, code))
+	require.ErrorIs(t, s.ApproveEvent(ctx, id, code), service.ErrAlreadyApproved)
+	require.ErrorIs(t, s.ApproveEvent(ctx, id+100, code), service.ErrEventNotFound)
+
+	// 却下は確認だけで、承認はしない
+	require.NoError(t, s.RejectEvent(ctx, rejected, rejectedCode))
+	require.ErrorIs(t, s.RejectEvent(ctx, id, code), service.ErrAlreadyApproved)
+	approved, err := repo.GetEvents(ctx, repository.GetEventsParams{})
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	require.Equal(t, id, approved[0].ID)
+}
+
+func TestService_ReviewEventByAdmin(t *testing.T) {
+	repo := repository.NewMemory()
+	s := service.New(repo, nil, nil)
+	ctx := context.Background()
+
+	approved, _ := submitEvent(t, repo, repository.CreateEventParams{Title: "承認"})
+	rejected, _ := submitEvent(t, repo, repository.CreateEventParams{Title: "却下"})
+	_, err := repo.SetPosterImage(ctx, rejected, "poster.jpg", "poster-thumb.jpg")
+	require.NoError(t, err)
+
+	require.NoError(t, s.ApproveEventByAdmin(ctx, approved))
+	require.ErrorIs(t, s.ApproveEventByAdmin(ctx, approved), service.ErrAlreadyApproved)
+	require.ErrorIs(t, s.ApproveEventByAdmin(ctx, rejected+100), service.ErrEventNotFound)
+
+	keys, err := s.RejectEventByAdmin(ctx, rejected)
+	require.NoError(t, err)
+	require.Equal(t, []string{"poster.jpg", "poster-thumb.jpg"}, keys)
+	_, err = s.RejectEventByAdmin(ctx, approved)
+	require.ErrorIs(t, err, service.ErrAlreadyApproved)
+
+	events, err := repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, approved, events[0].ID)
+}
+
+func TestService_UpdateAndDeleteEvent(t *testing.T) {
+	repo := repository.NewMemory()
+	var posted []string
+	notifier := service.NotifierFunc(func(ctx context.Context, text string) error {
+		posted = append(posted, text)
+		return nil
+	})
+	var mailed string
+	mailer := service.MailerFunc(func(to, subject, body string) error {
+		mailed = body
+		return nil
+	})
+	s := service.New(repo, notifier, mailer)
+	ctx := context.Background()
+
+	id, approvalCode := submitEvent(t, repo, repository.CreateEventParams{
+		Title:    "集合論入門",
+		Email:    "owner@example.com",
+		Speakers: []repository.Speaker{{Name: "山田"}},
+	})
+	require.NoError(t, repo.SetEventApproved(ctx, id))
+	_, err := repo.SetSpeakerPhoto(ctx, id, 0, "old.jpg", "old-thumb.jpg")
+	require.NoError(t, err)
+	require.NoError(t, s.RotateAuthCode(ctx, id, "owner@example.com"))
+	code := authCodeIn(t, mailed, id)
+
+	// 承認済みのイベントは誰でも見られるが、変更には認証コードが要る
+	_, err = s.UpdateEvent(ctx, id, uuid.Nil, repository.CreateEventParams{})
+	require.ErrorIs(t, err, service.ErrInvalidAuthCode)
+
+	// 運営者が承認用のコードで編集した場合は承認済みのまま
+	_, err = s.UpdateEvent(ctx, id, approvalCode, repository.CreateEventParams{Title: "集合論入門", Speakers: []repository.Speaker{{Name: "山田"}}})
+	require.NoError(t, err)
+	event, err := repo.GetEvent(ctx, id, uuid.Nil)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	require.Empty(t, posted)
+
+	orphaned, err := s.UpdateEvent(ctx, id, code, repository.CreateEventParams{Title: "集合論入門 (改訂)"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"old.jpg", "old-thumb.jpg"}, orphaned)
+
+	// 主催者が承認済みのイベントを編集すると、運営者が承認し直すまで公開されない
+	event, err = repo.GetEvent(ctx, id, uuid.Nil)
+	require.NoError(t, err)
+	require.Nil(t, event)
+	require.Len(t, posted, 1)
+	require.Contains(t, posted[0], "集合論入門 (改訂)")
+	newApprovalCode := authCodeIn(t, posted[0], id)
+	require.ErrorIs(t, s.ApproveEvent(ctx, id, code), service.ErrInvalidAuthCode)
+	require.ErrorIs(t, s.ApproveEvent(ctx, id, approvalCode), service.ErrEventNotFound)
+	require.NoError(t, s.ApproveEvent(ctx, id, newApprovalCode))
+	event, err = repo.GetEvent(ctx, id, uuid.Nil)
+	require.NoError(t, err)
+	require.Equal(t, "集合論入門 (改訂)", event.Title)
+
+	_, err = s.DeleteEvent(ctx, id, uuid.New())
+	require.ErrorIs(t, err, service.ErrInvalidAuthCode)
+	_, err = s.DeleteEvent(ctx, id, code)
+	require.NoError(t, err)
+	_, err = s.DeleteEvent(ctx, id, code)
+	require.ErrorIs(t, err, service.ErrEventNotFound)
+}
+
+func TestService_UpdateEvent_NotificationFailure(t *testing.T) {
+	repo := repository.NewMemory()
+	var mailed string
+	mailer := service.MailerFunc(func(to, subject, body string) error {
+		mailed = body
+		return nil
+	})
+	notifier := service.NotifierFunc(func(ctx context.Context, text string) error { return errTest })
+	s := service.New(repo, notifier, mailer)
+	ctx := context.Background()
+
+	id, _ := submitEvent(t, repo, repository.CreateEventParams{Title: "集合論入門", Email: "owner@example.com"})
+	require.NoError(t, repo.SetEventApproved(ctx, id))
+	require.NoError(t, s.RotateAuthCode(ctx, id, "owner@example.com"))
+	code := authCodeIn(t, mailed, id)
+
+	// 運営者に通知できなければ編集せず、承認済みのまま残す
+	_, err := s.UpdateEvent(ctx, id, code, repository.CreateEventParams{Title: "集合論入門 (改訂)"})
+	require.ErrorIs(t, err, service.ErrNotification)
+	event, err := repo.GetEvent(ctx, id, uuid.Nil)
+	require.NoError(t, err)
+	require.True(t, event.IsAuthenticated)
+	require.Equal(t, "集合論入門", event.Title)
+
+	// 未承認のイベントの編集は通知しない
+	id, code = submitEvent(t, repo, repository.CreateEventParams{Title: "位相空間論"})
+	_, err = s.UpdateEvent(ctx, id, code, repository.CreateEventParams{Title: "位相空間論 (改訂)"})
+	require.NoError(t, err)
+}
+
+func TestService_RotateAuthCode(t *testing.T) {
+	repo := repository.NewMemory()
+	ctx := context.Background()
+	id, code := submitEvent(t, repo, repository.CreateEventParams{
+		Title: "集合論入門",
+		Email: "owner@example.com",
+	})
+
+	var sent []string
+	var body string
+	mailer := service.MailerFunc(func(to, subject, b string) error {
+		sent = append(sent, to)
+		body = b
+		return nil
+	})
+	s := service.New(repo, nil, mailer)
+
+	require.NoError(t, s.RotateAuthCode(ctx, id, "owner@example.com"))
+	// 一致しないメールアドレスでもエラーにはしない
+	require.NoError(t, s.RotateAuthCode(ctx, id, "other@example.com"))
+	require.Equal(t, []string{"owner@example.com"}, sent)
+
+	// メールのリンクのコードで閲覧・編集できる
+	ok, err := repo.VerifyAuthCode(ctx, id, authCodeIn(t, body, id))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// 申請者が自分のイベントを承認できないよう、メールのコードでは承認できない
+	require.ErrorIs(t, s.ApproveEvent(ctx, id, authCodeIn(t, body, id)), service.ErrInvalidAuthCode)
+	// 運営者に通知した承認用のコードは、発行し直したあとも使える
+	require.NoError(t, s.ApproveEvent(ctx, id, code))
+
+	failing := service.MailerFunc(func(to, subject, body string) error { return errTest })
+	require.ErrorIs(t, service.New(repo, nil, failing).RotateAuthCode(ctx, id, "owner@example.com"), service.ErrNotification)
+}
+
+func TestService_SubmitContact(t *testing.T) {
+	var message string
+	notifier := service.NotifierFunc(func(ctx context.Context, text string) error {
+		message = text
+		return nil
+	})
+	err := service.New(nil, notifier, nil).SubmitContact(context.Background(), service.Contact{
+		Name:    "山田",
+		Email:   "yamada@example.com",
+		Message: "参加方法を教えてください",
+	})
+	require.NoError(t, err)
+	require.Contains(t, message, "名前: 山田")
+	require.Contains(t, message, "メッセージ: 参加方法を教えてください")
+}
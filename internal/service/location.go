@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// BackfillVenueLocations は座標のないイベントの位置を、都道府県・会場の住所から geocoder で求めて設定し、設定した件数を返します。
+// 位置情報に対応する前に登録されたイベントを、近くのイベントの検索に含めるために使います。
+// 住所が古い形式で venue_address がない場合は、会場名(venue)の先頭の都道府県名を使います。
+// 位置を特定できないイベントは飛ばします。
+func (s *Service) BackfillVenueLocations(ctx context.Context, geocoder geo.Geocoder) (int, error) {
+	events, err := s.repo.GetEvents(ctx, repository.GetEventsParams{Status: repository.StatusAll})
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, event := range events {
+		if event.Latitude != nil && event.Longitude != nil {
+			continue
+		}
+
+		addr := geo.Address{}
+		if event.Prefecture != nil {
+			addr.Prefecture = *event.Prefecture
+		}
+		switch {
+		case event.VenueAddress != nil && *event.VenueAddress != "":
+			addr.Address = *event.VenueAddress
+		case event.Venue != nil:
+			addr.Address = *event.Venue
+		}
+		if event.VenuePostalCode != nil {
+			addr.PostalCode = *event.VenuePostalCode
+		}
+
+		p, precision, err := geocoder.Geocode(ctx, addr)
+		if errors.Is(err, geo.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return updated, fmt.Errorf("geocode event %d: %w", event.ID, err)
+		}
+
+		err = s.repo.SetEventLocation(ctx, event.ID, p.Lat, p.Lng, string(precision))
+		if errors.Is(err, sql.ErrNoRows) {
+			// 削除されたか、途中で座標が指定された
+			continue
+		}
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
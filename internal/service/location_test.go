@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
+)
+
+func TestService_BackfillVenueLocations(t *testing.T) {
+	repo := repository.NewMemory()
+	ctx := context.Background()
+	geocoder, err := geo.NewPrefectureGeocoder()
+	require.NoError(t, err)
+
+	// 位置情報に対応する前に登録され、座標を持たないイベント
+	for _, params := range []repository.CreateEventParams{
+		{Title: "都道府県あり", Prefecture: ptr("13")},
+		{Title: "会場名から", Venue: ptr("東京都千代田区 数学会館")},
+		{Title: "特定できない", Venue: ptr("オンライン")},
+		{Title: "座標あり", Prefecture: ptr("13"), Latitude: ptr(34.69), Longitude: ptr(135.50), LocationPrecision: ptr("exact")},
+	} {
+		id, _ := submitEvent(t, repo, params)
+		require.NoError(t, repo.SetEventApproved(ctx, id))
+	}
+
+	tokyo := geo.BoundsAround(geo.Point{Lat: 35.68, Lng: 139.76}, 50)
+	listed, err := repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+	require.NoError(t, err)
+	require.Empty(t, listed)
+
+	n, err := service.New(repo, nil, nil).BackfillVenueLocations(ctx, geocoder)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	listed, err = repo.GetEvents(ctx, repository.GetEventsParams{Bounds: &tokyo})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"都道府県あり", "会場名から"}, titlesOf(listed))
+	for _, e := range listed {
+		require.Equal(t, string(geo.PrecisionPrefecture), *e.LocationPrecision)
+	}
+
+	// 2回目は何も変えない
+	n, err = service.New(repo, nil, nil).BackfillVenueLocations(ctx, geocoder)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func titlesOf(events []*repository.Event) []string {
+	res := make([]string, len(events))
+	for i, e := range events {
+		res[i] = e.Title
+	}
+	return res
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+// AttendeeLink は参加用トークン付きの、フロントエンドのイベント詳細ページのURLです。
+func AttendeeLink(id int, token string) string {
+	return fmt.Sprintf("%s/events/%d?attendee=%s", config.CORE_FRONTEND_URL, id, token)
+}
+
+// RegisterForEvent は承認済みのイベントへの参加登録を行い、参加用リンクを登録されたメールアドレスに送ります。
+// トークンはメールでしか渡さないため、オンライン講義URLを見られるのはそのアドレスを受け取れる人だけです。
+// 登録済みのアドレスで申し込み直した場合も、以前のリンクは使えるまま、新しいリンクを同じアドレスに送ります。
+// イベントが存在しないか未承認の場合は ErrEventNotFound を返します。
+func (s *Service) RegisterForEvent(ctx context.Context, params repository.CreateRegistrationParams) error {
+	event, err := s.repo.GetEvent(ctx, params.EventID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return ErrEventNotFound
+	}
+
+	token, err := s.repo.CreateRegistration(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"%s 様\n\n「%s」への参加登録を受け付けました。\n以下のリンクからイベントの詳細を確認できます。オンライン講義のURLは開始前にこのページに表示されます。\nリンクは他の人に共有しないでください。\n\n参加用リンク: %s",
+		params.Name, event.Title, AttendeeLink(params.EventID, token),
+	)
+	if err := s.mailer.Send(params.Email, "【Math Day】参加登録の受付", body); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotification, err)
+	}
+	return nil
+}
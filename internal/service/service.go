@@ -0,0 +1,89 @@
+// Package service はイベントの申請・承認やお問い合わせなどの業務の流れをまとめます。
+// HTTPに依存しないため、ハンドラのほかCLIや定期実行のジョブからも使えます。
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ras0q/go-backend-template/internal/repository"
+)
+
+var (
+	// ErrEventNotFound はイベントが存在しない(または認証コードなしでは見えない)ことを表します。
+	ErrEventNotFound = errors.New("event not found")
+	// ErrInvalidAuthCode は認証コードが正しくないか、有効期限が切れていることを表します。
+	ErrInvalidAuthCode = errors.New("invalid auth code")
+	// ErrAlreadyApproved は承認済みのイベントを承認・却下しようとしたことを表します。
+	ErrAlreadyApproved = errors.New("event already approved")
+	// ErrNotification はSlackやメールでの通知に失敗したことを表します。
+	ErrNotification = errors.New("failed to send notification")
+)
+
+// Repository はサービスが使う永続化の操作です。*repository.Repository が実装します。
+type Repository interface {
+	BeginTx(ctx context.Context) (repository.Tx, error)
+	CreateEventTx(ctx context.Context, tx repository.Tx, params repository.CreateEventParams) (int, string, error)
+	GetEvent(ctx context.Context, id int, authCode uuid.UUID) (*repository.Event, error)
+	VerifyAuthCode(ctx context.Context, id int, authCode uuid.UUID) (bool, error)
+	AuthenticateEvent(ctx context.Context, id int, authCode uuid.UUID) error
+	RotateAuthCode(ctx context.Context, id int, email string, expiresAt time.Time) (string, error)
+	UpdateEvent(ctx context.Context, id int, params repository.CreateEventParams) ([]string, error)
+	DeleteEvent(ctx context.Context, id int) ([]string, error)
+	GetEventForReview(ctx context.Context, id int) (*repository.Event, error)
+	SetEventApproved(ctx context.Context, id int) error
+	ResetApprovalTx(ctx context.Context, tx repository.Tx, id int, authCode uuid.UUID, expiresAt time.Time) (string, error)
+	CreateRegistration(ctx context.Context, params repository.CreateRegistrationParams) (string, error)
+	GetEvents(ctx context.Context, params repository.GetEventsParams) ([]*repository.Event, error)
+	SetEventLocation(ctx context.Context, id int, lat, lng float64, precision string) error
+}
+
+var (
+	_ Repository = (*repository.Repository)(nil)
+	_ Repository = (*repository.Memory)(nil)
+)
+
+// Notifier は運営者への通知(Slack)を送ります。
+type Notifier interface {
+	Post(ctx context.Context, text string) error
+}
+
+// NotifierFunc は関数を Notifier として使うためのアダプタです。
+type NotifierFunc func(ctx context.Context, text string) error
+
+func (f NotifierFunc) Post(ctx context.Context, text string) error {
+	return f(ctx, text)
+}
+
+// Mailer は主催者へのメールを送ります。
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// MailerFunc は関数を Mailer として使うためのアダプタです。
+type MailerFunc func(to, subject, body string) error
+
+func (f MailerFunc) Send(to, subject, body string) error {
+	return f(to, subject, body)
+}
+
+type Service struct {
+	repo     Repository
+	notifier Notifier
+	mailer   Mailer
+
+	// now はテストで時刻を固定するためのものです。
+	now func() time.Time
+}
+
+func New(repo Repository, notifier Notifier, mailer Mailer) *Service {
+	return &Service{
+		repo:     repo,
+		notifier: notifier,
+		mailer:   mailer,
+		now:      time.Now,
+	}
+}
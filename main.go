@@ -1,53 +1,109 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
 
-	"github.com/ras0q/go-backend-template/internal/handler"
-	"github.com/ras0q/go-backend-template/internal/migration"
 	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/database"
+	"github.com/ras0q/go-backend-template/internal/pkg/mail"
+	"github.com/ras0q/go-backend-template/internal/pkg/slack"
 	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/service"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 )
 
+// command はサブコマンドです。args にはサブコマンド名より後ろの引数が渡されます。
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"serve":        {usage: "APIサーバーを起動します (引数なしの場合と同じ)", run: runServe},
+	"migrate":      {usage: "マイグレーションを操作します (up / down / status / redo)", run: runMigrate},
+	"events":       {usage: "イベントを一覧・承認・却下し、座標を補います (list / approve / reject / geocode)", run: runEvents},
+	"export":       {usage: "イベントをCSVまたはJSONで出力します", run: runExport},
+	"seed":         {usage: "JSONファイルのイベントを承認済みとして登録します", run: runSeed},
+	"create-admin": {usage: "運営者を作成し、トークンを表示します", run: runCreateAdmin},
+}
+
 func main() {
-	e := echo.New()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	allowOrigins := strings.Split(os.Getenv("ALLOW_ORIGINS"), ",")
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	// config.CORE_FRONTEND_URL
-	allowOrigins = append(allowOrigins, config.CORE_FRONTEND_URL)
+// run はサブコマンドを実行します。コンテナは引数なしで起動するため、引数がなければ serve として扱います。
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return runServe(ctx, nil)
+	}
 
-	// middlewares
-	e.Use(middleware.Recover())
-	e.Use(middleware.Logger())
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: allowOrigins,
-	}))
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+	return cmd.run(ctx, args[1:])
+}
 
-	// connect to database
-	db, err := sqlx.Connect("mysql", config.MySQL().FormatDSN())
-	if err != nil {
-		e.Logger.Fatal(err)
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
-	defer db.Close()
+	sort.Strings(names)
 
-	// migrate tables
-	if err := migration.MigrateTables(db.DB); err != nil {
-		e.Logger.Fatal(err)
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [options]\n\nCommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].usage)
 	}
+}
+
+// newFlagSet はサブコマンドのオプションを解析する FlagSet を作成します。
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(strings.TrimSpace(os.Args[0]+" "+name), flag.ContinueOnError)
+}
 
-	// setup repository
-	repo := repository.New(db)
+// subcommand は migrate up のような2段目のサブコマンドを取り出します。
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("subcommand is required (%s)", strings.Join(names, " / "))
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q (%s)", args[0], strings.Join(names, " / "))
+}
 
-	// setup routes
-	h := handler.New(repo)
-	v1API := e.Group("/api/v1")
-	h.SetupRoutes(v1API)
+// openDB は設定に従ってDBに接続します。
+func openDB() (*sqlx.DB, error) {
+	return database.Open(config.Database())
+}
 
-	e.Logger.Fatal(e.Start(config.AppAddr()))
+// newService はAPIサーバーとCLIで共通の設定でサービスを作成します。
+func newService(repo *repository.Repository) *service.Service {
+	return service.New(repo,
+		slack.NewWebhook(config.SlackWebhookURL()),
+		mail.NewSMTP(config.SMTP()),
+	)
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ras0q/go-backend-template/internal/migration"
+)
+
+// runMigrate は migrate up / down / status / redo を実行します。
+func runMigrate(ctx context.Context, args []string) error {
+	name, args, err := subcommand(args, "up", "down", "status", "redo")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("migrate " + name)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch name {
+	case "up":
+		return migration.Up(db)
+	case "down":
+		return migration.Down(db)
+	case "redo":
+		return migration.Redo(db)
+	default:
+		return migration.Status(db)
+	}
+}
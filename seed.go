@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/seed"
+)
+
+// runSeed はフィクスチャのイベントを承認済みとして登録します。
+// --file がなければ同梱のサンプルイベントを使います。APP_ENV が development・test の場合のみ実行できます。
+func runSeed(ctx context.Context, args []string) error {
+	fs := newFlagSet("seed")
+	file := fs.String("file", "", "YAML or JSON fixture file (default: bundled sample events)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !seed.Allowed(config.AppEnv()) {
+		return seed.ErrNotAllowed
+	}
+
+	events, err := seed.Defaults()
+	if *file != "" {
+		events, err = seed.LoadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ids, err := seed.Run(ctx, repository.New(db), config.AppEnv(), events)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("seeded %d events\n", len(ids))
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ras0q/go-backend-template/internal/handler"
+	"github.com/ras0q/go-backend-template/internal/migration"
+	"github.com/ras0q/go-backend-template/internal/pkg/config"
+	"github.com/ras0q/go-backend-template/internal/pkg/geo"
+	"github.com/ras0q/go-backend-template/internal/pkg/ogp"
+	"github.com/ras0q/go-backend-template/internal/pkg/slack"
+	"github.com/ras0q/go-backend-template/internal/pkg/storage"
+	"github.com/ras0q/go-backend-template/internal/repository"
+	"github.com/ras0q/go-backend-template/internal/seed"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// runServe はAPIサーバーを起動します。
+// スキーマのバージョンがアプリと異なる場合は、MIGRATION_MODE=auto でなければ起動しません。
+func runServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	e := echo.New()
+
+	allowOrigins := strings.Split(os.Getenv("ALLOW_ORIGINS"), ",")
+
+	// config.CORE_FRONTEND_URL
+	allowOrigins = append(allowOrigins, config.CORE_FRONTEND_URL)
+
+	// middlewares
+	e.Use(middleware.Recover())
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		// 数秒おきに届く死活監視はログに残さない
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/healthz" || c.Path() == "/readyz"
+		},
+	}))
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: allowOrigins,
+	}))
+
+	// connect to database
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// check or migrate tables
+	switch mode := config.MigrationMode(); mode {
+	case "auto":
+		if err := migration.Up(db); err != nil {
+			return err
+		}
+	case "check":
+		if err := migration.Check(db); err != nil {
+			return fmt.Errorf("%w (run `migrate up` or set MIGRATION_MODE=auto)", err)
+		}
+	default:
+		return fmt.Errorf("unknown MIGRATION_MODE %q (check / auto)", mode)
+	}
+
+	// setup repository
+	repo := repository.New(db)
+
+	// 開発環境ではサンプルのイベントを入れておく。何度起動しても重複しない
+	if config.AppEnv() == "development" {
+		events, err := seed.Defaults()
+		if err != nil {
+			return err
+		}
+		if _, err := seed.Run(ctx, repo, config.AppEnv(), events); err != nil {
+			return err
+		}
+	}
+
+	// setup geocoder
+	geocoder, err := geo.NewPrefectureGeocoder()
+	if err != nil {
+		return err
+	}
+
+	// setup image storage
+	st, err := newStorage(config.Storage())
+	if err != nil {
+		return err
+	}
+
+	// setup og image renderer
+	ogRenderer, err := newOGRenderer(config.OGPFontPath())
+	if err != nil {
+		return err
+	}
+
+	// setup service
+	svc := newService(repo)
+
+	// setup routes
+	// レスポンスのキャッシュは nil でプロセス内に持つ。複数台で共有する場合は
+	// Redis 互換のサーバーを使う cache.Cache の実装を渡す
+	h := handler.New(repo, svc, geocoder, st, ogRenderer, nil)
+	validator := handler.OpenAPIValidator(handler.OpenAPIValidatorConfig{
+		ValidateResponses: config.OpenAPIValidateResponses(),
+	})
+	v1API := e.Group("/api/v1", handler.DeprecatedAPI(handler.V1Deprecation(config.APIV1Sunset())), validator)
+	h.SetupRoutes(v1API)
+	h.SetupV2Routes(e.Group("/api/v2", validator))
+	h.SetupHealthRoutes(e.Group(""), readinessChecks(db)...)
+
+	srv := config.Server()
+	e.Server.ReadTimeout = srv.ReadTimeout
+	e.Server.WriteTimeout = srv.WriteTimeout
+	e.Server.IdleTimeout = srv.IdleTimeout
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Start(config.AppAddr())
+	}()
+
+	// main でシグナルを受け取ると ctx が終わる
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	return shutdown(e, h, srv)
+}
+
+// readinessChecks は /readyz で確かめる依存先です。
+// スキーマはアプリより古い場合だけ失敗とします。新しいアプリが先にマイグレーションを適用しても、
+// 入れ替え中の古いインスタンスが一斉に振り分け先から外れないようにするためです。
+func readinessChecks(db *sqlx.DB) []handler.HealthCheck {
+	checks := []handler.HealthCheck{
+		{
+			Name: "database",
+			Check: func(ctx context.Context) (string, error) {
+				return db.DriverName(), db.PingContext(ctx)
+			},
+		},
+		{
+			Name: "migration",
+			Check: func(ctx context.Context) (string, error) {
+				current, latest, err := migration.VersionContext(ctx, db)
+				if err != nil {
+					return "", err
+				}
+				detail := fmt.Sprintf("version %d (application expects %d)", current, latest)
+				if current < latest {
+					return detail, migration.ErrSchemaBehind
+				}
+				return detail, nil
+			},
+		},
+	}
+
+	if config.ReadinessCheckNotifier() {
+		webhook := slack.NewWebhook(config.SlackWebhookURL())
+		checks = append(checks, handler.HealthCheck{
+			Name:     "notifier",
+			Optional: true,
+			Check: func(ctx context.Context) (string, error) {
+				return "slack", webhook.Ping(ctx)
+			},
+		})
+	}
+	return checks
+}
+
+// shutdown は /readyz を503にしてから、処理中のリクエストが終わるのを待ってサーバーを止めます。
+// srv.ShutdownDelay の間は新しいリクエストも受け付け、振り分け先から外れるまでに届いたリクエストを落とさないようにします。
+// srv.ShutdownTimeout を過ぎても終わらないリクエストは、接続を切ってエラーを返します。
+func shutdown(e *echo.Echo, h *handler.Handler, srv config.ServerConfig) error {
+	h.BeginShutdown()
+	e.Logger.Printf("shutting down (delay %s, timeout %s)", srv.ShutdownDelay, srv.ShutdownTimeout)
+	time.Sleep(srv.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), srv.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown server: %w", err)
+	}
+	return nil
+}
+
+// newStorage は設定に応じてアップロードファイルの保存先を作成します。
+func newStorage(c config.StorageConfig) (storage.Storage, error) {
+	switch c.Driver {
+	case "local":
+		return storage.NewLocal(c.LocalDir, config.CORE_BACKEND_URL+"/api/v2/images")
+	case "s3":
+		if c.S3Endpoint == "" || c.S3Bucket == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required for the s3 storage driver")
+		}
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        c.S3Endpoint,
+			Region:          c.S3Region,
+			Bucket:          c.S3Bucket,
+			AccessKeyID:     c.S3AccessKeyID,
+			SecretAccessKey: c.S3SecretAccessKey,
+			PublicURL:       c.S3PublicURL,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", c.Driver)
+	}
+}
+
+// newOGRenderer はOGP画像の Renderer を作成します。fontPath が空の場合は同梱のフォントを使います。
+func newOGRenderer(fontPath string) (*ogp.Renderer, error) {
+	var fontData []byte
+	if fontPath != "" {
+		b, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, fmt.Errorf("read OGP_FONT_PATH: %w", err)
+		}
+		fontData = b
+	}
+	return ogp.NewRenderer(fontData)
+}